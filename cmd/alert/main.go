@@ -16,13 +16,51 @@ func main() {
 	db := setup.ConnectToDatabase(cfg.DbDriver)
 	defer db.Close()
 
-	broker := setup.ConnectToMessageBroker(cfg.MessageBroker)
+	broker := setup.ConnectToMessageBroker(cfg.CommonConfig)
 	defer broker.Close()
 
+	sitesRepo := db.SitesRepo()
+	sitesService := service.NewSitesService(sitesRepo, cfg.CommonConfig)
+
 	resultsRepo := db.ResultsRepo()
 	resultsService := service.NewResultsService(resultsRepo, cfg.CommonConfig)
 
-	alert, err := alert.New(broker, resultsService, cfg)
+	certificatesRepo := db.CertificatesRepo()
+	certificatesService := service.NewCertificatesService(certificatesRepo, cfg.CommonConfig)
+
+	incidentsRepo := db.IncidentsRepo()
+	incidentsService := service.NewIncidentsService(incidentsRepo, cfg.CommonConfig)
+
+	alertRulesRepo := db.AlertRulesRepo()
+	alertRulesService := service.NewAlertRulesService(alertRulesRepo, cfg.CommonConfig)
+
+	maintenanceRepo := db.MaintenanceRepo()
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, cfg.CommonConfig)
+
+	escalationPoliciesRepo := db.EscalationPoliciesRepo()
+	escalationPoliciesService := service.NewEscalationPoliciesService(
+		escalationPoliciesRepo,
+		cfg.CommonConfig,
+	)
+
+	pendingNotificationsRepo := db.PendingNotificationsRepo()
+	pendingNotificationsService := service.NewPendingNotificationsService(
+		pendingNotificationsRepo,
+		cfg.CommonConfig,
+	)
+
+	alert, err := alert.New(
+		broker,
+		sitesService,
+		resultsService,
+		certificatesService,
+		incidentsService,
+		alertRulesService,
+		maintenanceService,
+		escalationPoliciesService,
+		pendingNotificationsService,
+		cfg,
+	)
 	if err != nil {
 		slog.Error("failed to create alert service", sl.Error(err))
 		os.Exit(1)
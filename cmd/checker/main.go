@@ -14,7 +14,7 @@ func main() {
 	db := setup.ConnectToDatabase(cfg.DbDriver)
 	defer db.Close()
 
-	broker := setup.ConnectToMessageBroker(cfg.MessageBroker)
+	broker := setup.ConnectToMessageBroker(cfg.CommonConfig)
 	defer broker.Close()
 
 	resultsRepo := db.ResultsRepo()
@@ -23,7 +23,10 @@ func main() {
 	sitesRepo := db.SitesRepo()
 	sitesService := service.NewSitesService(sitesRepo, cfg.CommonConfig)
 
-	checker := checker.New(broker, resultsService, sitesService, cfg)
+	certificatesRepo := db.CertificatesRepo()
+	certificatesService := service.NewCertificatesService(certificatesRepo, cfg.CommonConfig)
+
+	checker := checker.New(broker, resultsService, sitesService, certificatesService, cfg)
 	slog.Info("starting checker service")
 	checker.Start()
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"shm/internal/config"
+	"shm/internal/lib/setup"
+	"shm/internal/lib/sl"
+	"shm/internal/notifier"
+	"shm/internal/notifier/chatops"
+	"shm/internal/notifier/email"
+	"shm/internal/notifier/paging"
+	"shm/internal/notifier/push"
+	"shm/internal/notifier/telegram"
+	"shm/internal/notifier/webhook"
+	"shm/internal/service"
+)
+
+func main() {
+	cfg := config.NewNotifierConfig()
+
+	db := setup.ConnectToDatabase(cfg.DbDriver)
+	defer db.Close()
+
+	broker := setup.ConnectToMessageBroker(cfg.CommonConfig)
+	defer broker.Close()
+
+	if cfg.TelegramToken != "" {
+		bot, err := telegram.NewSender(cfg.TelegramToken)
+		if err != nil {
+			slog.Error("failed to create telegram sender", sl.Error(err))
+			os.Exit(1)
+		}
+		telegram.Register(bot)
+	} else {
+		slog.Warn("telegram token is not found, telegram channels are disabled")
+	}
+
+	if cfg.SMTP.Host != "" {
+		sender, err := email.NewSender(cfg.SMTP)
+		if err != nil {
+			slog.Error("failed to create email sender", sl.Error(err))
+			os.Exit(1)
+		}
+		sender.Register()
+	} else {
+		slog.Warn("smtp host is not found, email channels are disabled")
+	}
+
+	chatops.Register()
+	paging.Register()
+	push.Register()
+	webhook.Register()
+
+	channelsRepo := db.ChannelsRepo()
+	channelsService := service.NewChannelsService(channelsRepo, cfg.CommonConfig)
+
+	chatsRepo := db.ChatsRepo()
+	chatsService := service.NewChatsService(chatsRepo, cfg.CommonConfig)
+
+	emailsRepo := db.EmailsRepo()
+	emailsService := service.NewEmailsService(emailsRepo, cfg.CommonConfig)
+
+	notificationDeliveriesRepo := db.NotificationDeliveriesRepo()
+	notificationDeliveriesService := service.NewNotificationDeliveriesService(
+		notificationDeliveriesRepo,
+		cfg.CommonConfig,
+	)
+
+	dispatcher := notifier.NewDispatcher(
+		broker,
+		channelsService,
+		chatsService,
+		emailsService,
+		notificationDeliveriesService,
+		cfg,
+	)
+
+	slog.Info("starting notifier")
+	dispatcher.Start()
+}
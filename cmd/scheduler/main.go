@@ -2,8 +2,10 @@ package main
 
 import (
 	"log/slog"
+	"os"
 	"shm/internal/config"
 	"shm/internal/lib/setup"
+	"shm/internal/lib/sl"
 	"shm/internal/scheduler"
 	"shm/internal/service"
 )
@@ -14,12 +16,34 @@ func main() {
 	db := setup.ConnectToDatabase(cfg.DbDriver)
 	defer db.Close()
 
-	broker := setup.ConnectToMessageBroker(cfg.MessageBroker)
+	broker := setup.ConnectToMessageBroker(cfg.CommonConfig)
 	defer broker.Close()
 
 	sitesRepo := db.SitesRepo()
 	sitesService := service.NewSitesService(sitesRepo, cfg.CommonConfig)
 
+	leasesRepo := db.LeasesRepo()
+	leasesService := service.NewLeasesService(leasesRepo, cfg.CommonConfig)
+
+	maintenanceRepo := db.MaintenanceRepo()
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, cfg.CommonConfig)
+
+	incidentsRepo := db.IncidentsRepo()
+	incidentsService := service.NewIncidentsService(incidentsRepo, cfg.CommonConfig)
+
+	scheduler, err := scheduler.New(
+		broker,
+		sitesService,
+		maintenanceService,
+		incidentsService,
+		leasesService,
+		cfg,
+	)
+	if err != nil {
+		slog.Error("failed to create scheduler", sl.Error(err))
+		os.Exit(1)
+	}
+
 	slog.Info("starting scheduler service")
-	scheduler.New(broker, sitesService, cfg).Start()
+	scheduler.Start()
 }
@@ -16,10 +16,53 @@ func main() {
 	db := setup.ConnectToDatabase(cfg.DbDriver)
 	defer db.Close()
 
+	broker := setup.ConnectToMessageBroker(cfg.CommonConfig)
+	defer broker.Close()
+
 	sitesRepo := db.SitesRepo()
 	sites := service.NewSitesService(sitesRepo, cfg.CommonConfig)
 
-	server := server.New(sites, cfg)
+	resultsRepo := db.ResultsRepo()
+	results := service.NewResultsService(resultsRepo, cfg.CommonConfig)
+
+	certificatesRepo := db.CertificatesRepo()
+	certificates := service.NewCertificatesService(certificatesRepo, cfg.CommonConfig)
+
+	incidentsRepo := db.IncidentsRepo()
+	incidents := service.NewIncidentsService(incidentsRepo, cfg.CommonConfig)
+
+	alertRulesRepo := db.AlertRulesRepo()
+	rules := service.NewAlertRulesService(alertRulesRepo, cfg.CommonConfig)
+
+	maintenanceRepo := db.MaintenanceRepo()
+	maintenance := service.NewMaintenanceService(maintenanceRepo, cfg.CommonConfig)
+
+	escalationPoliciesRepo := db.EscalationPoliciesRepo()
+	escalations := service.NewEscalationPoliciesService(escalationPoliciesRepo, cfg.CommonConfig)
+
+	channelsRepo := db.ChannelsRepo()
+	channels := service.NewChannelsService(channelsRepo, cfg.CommonConfig)
+
+	emailsRepo := db.EmailsRepo()
+	emails := service.NewEmailsService(emailsRepo, cfg.CommonConfig)
+
+	notificationDeliveriesRepo := db.NotificationDeliveriesRepo()
+	outbox := service.NewNotificationDeliveriesService(notificationDeliveriesRepo, cfg.CommonConfig)
+
+	server := server.New(
+		broker,
+		sites,
+		results,
+		certificates,
+		incidents,
+		rules,
+		maintenance,
+		escalations,
+		channels,
+		emails,
+		outbox,
+		cfg,
+	)
 	slog.Info("starting http server", slog.String("address", cfg.Address))
 	if err := server.Start(); err != http.ErrServerClosed {
 		slog.Error("error from http server", sl.Error(err))
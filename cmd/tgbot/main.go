@@ -20,16 +20,33 @@ func main() {
 	db := setup.ConnectToDatabase(cfg.DbDriver)
 	defer db.Close()
 
-	broker := setup.ConnectToMessageBroker(cfg.MessageBroker)
-	defer broker.Close()
-
 	chatsRepo := db.ChatsRepo()
 	chatsService := service.NewChatsService(chatsRepo, cfg.CommonConfig)
 
 	sitesRepo := db.SitesRepo()
 	sitesService := service.NewSitesService(sitesRepo, cfg.CommonConfig)
 
-	tgbot, err := telegram.New(broker, chatsService, sitesService, cfg)
+	alertRulesRepo := db.AlertRulesRepo()
+	alertRulesService := service.NewAlertRulesService(alertRulesRepo, cfg.CommonConfig)
+
+	maintenanceRepo := db.MaintenanceRepo()
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, cfg.CommonConfig)
+
+	incidentsRepo := db.IncidentsRepo()
+	incidentsService := service.NewIncidentsService(incidentsRepo, cfg.CommonConfig)
+
+	escalationPoliciesRepo := db.EscalationPoliciesRepo()
+	escalationPoliciesService := service.NewEscalationPoliciesService(escalationPoliciesRepo, cfg.CommonConfig)
+
+	tgbot, err := telegram.New(
+		chatsService,
+		sitesService,
+		alertRulesService,
+		maintenanceService,
+		incidentsService,
+		escalationPoliciesService,
+		cfg,
+	)
 	if err != nil {
 		slog.Error("failed to create tg bot", sl.Error(err))
 		os.Exit(1)
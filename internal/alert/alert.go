@@ -2,6 +2,7 @@ package alert
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -12,28 +13,76 @@ import (
 	"shm/internal/lib/sl"
 	"shm/internal/model"
 	"shm/internal/service"
+	"sync"
 	"syscall"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type AlertService struct {
-	broker         broker.MessageBroker
-	resultsService *service.ResultsService
-	config         config.AlertServiceConfig
+	broker                    broker.MessageBroker
+	sitesService              *service.SitesService
+	resultsService            *service.ResultsService
+	certificatesService       *service.CertificatesService
+	incidentsService          *service.IncidentsService
+	alertRulesService         *service.AlertRulesService
+	maintenanceService        *service.MaintenanceService
+	escalationPoliciesService *service.EscalationPoliciesService
+	pendingService            *service.PendingNotificationsService
+	config                    config.AlertServiceConfig
+	// relayMu keeps pending notifications from being published twice at
+	// once by routines of the service
+	relayMu sync.Mutex
 }
 
 func New(
 	broker broker.MessageBroker,
+	sites *service.SitesService,
 	results *service.ResultsService,
+	certificates *service.CertificatesService,
+	incidents *service.IncidentsService,
+	alertRules *service.AlertRulesService,
+	maintenance *service.MaintenanceService,
+	escalationPolicies *service.EscalationPoliciesService,
+	pending *service.PendingNotificationsService,
 	config config.AlertServiceConfig,
 ) (*AlertService, error) {
 	if config.NumberOrFailedChecks < 1 {
 		return nil, fmt.Errorf("number of failed checks must be at least 1")
 	}
+	if config.LatencyWindow < 1 {
+		return nil, fmt.Errorf("latency window must be at least 1 check")
+	}
+	if config.FlapWindow < 2 {
+		return nil, fmt.Errorf("flap window must be at least 2 checks")
+	}
+	if config.FlapStopPercent < 0 || config.FlapStartPercent > 100 ||
+		config.FlapStopPercent >= config.FlapStartPercent {
+		return nil, fmt.Errorf("flap stop percent must be less than flap start percent")
+	}
+	if config.EscalationTickSec <= 0 {
+		return nil, fmt.Errorf("escalation tick must be positive")
+	}
+	if config.RelayTickSec <= 0 {
+		return nil, fmt.Errorf("relay tick must be positive")
+	}
+	for _, days := range config.CertExpiryWarningDays {
+		if days < 0 {
+			return nil, fmt.Errorf("days before certificate expiry must not be negative")
+		}
+	}
 	return &AlertService{
-		broker:         broker,
-		resultsService: results,
-		config:         config,
+		broker:                    broker,
+		sitesService:              sites,
+		resultsService:            results,
+		certificatesService:       certificates,
+		incidentsService:          incidents,
+		alertRulesService:         alertRules,
+		maintenanceService:        maintenance,
+		escalationPoliciesService: escalationPolicies,
+		pendingService:            pending,
+		config:                    config,
 	}, nil
 }
 
@@ -42,106 +91,259 @@ func (a *AlertService) Start() {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	resultsQueue, err := a.broker.ConsumeResults(ctx)
+	resultsQueue, err := a.broker.ConsumeResults(ctx, 1)
 	if err != nil {
 		slog.Error("failed to register a consumer for check results", sl.Error(err))
 		return
 	}
 
-	if err := a.routine(ctx, resultsQueue); err != nil && !errors.Is(err, context.Canceled) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return a.routine(ctx, resultsQueue)
+	})
+
+	g.Go(func() error {
+		return a.escalationRoutine(ctx)
+	})
+
+	g.Go(func() error {
+		return a.relayRoutine(ctx)
+	})
+
+	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
 		slog.Error("error from alert service", sl.Error(err))
 	}
 }
 
-func (a *AlertService) routine(ctx context.Context, resultsQueue <-chan model.CheckResult) error {
+func (a *AlertService) routine(
+	ctx context.Context,
+	resultsQueue <-chan broker.Message[model.CheckResult],
+) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case result, ok := <-resultsQueue:
+		case msg, ok := <-resultsQueue:
 			if !ok {
 				return fmt.Errorf("queue with results was closed")
 			}
-			if err := a.sendNotificationIfNeeded(ctx, result.Site); err != nil {
-				return fmt.Errorf("failed to handle check result: %w", err)
+			if err := a.handleResult(ctx, msg.Body); err != nil {
+				// the result is consumed again by this or another alert service
+				msg.Retry()
+				return err
 			}
-			slog.Info("successful handling of check result", sl.CheckResult(result))
+			if err := msg.Ack(); err != nil {
+				slog.Warn("failed to ack check result, it will be consumed again", sl.Error(err))
+			}
+			a.relayNotifications(ctx)
 		}
 	}
 }
 
-func (a *AlertService) sendNotificationIfNeeded(ctx context.Context, site model.Site) error {
-	lastResults, err := a.resultsService.GetNLastResultsForSite(
-		ctx, site, a.config.NumberOrFailedChecks+1,
-	)
+func (a *AlertService) handleResult(ctx context.Context, result model.CheckResult) error {
+	inMaintenance, err := a.maintenanceService.InMaintenance(ctx, result.Site, result.Time)
 	if err != nil {
-		return fmt.Errorf("failed to get last results for site: %w", err)
+		return fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	if inMaintenance {
+		slog.Info("site is in maintenance, check result was skipped", sl.CheckResult(result))
+		return nil
+	}
+	if err := a.sendNotificationIfNeeded(ctx, result); err != nil {
+		return fmt.Errorf("failed to handle check result: %w", err)
+	}
+	if err := a.sendCertificateWarningIfNeeded(ctx, result); err != nil {
+		return fmt.Errorf("failed to handle certificate of check result: %w", err)
 	}
+	if err := a.sendAnswersChangeIfNeeded(ctx, result); err != nil {
+		return fmt.Errorf("failed to handle dns answers of check result: %w", err)
+	}
+	slog.Info("successful handling of check result", sl.CheckResult(result))
+	return nil
+}
 
-	if len(lastResults) < a.config.NumberOrFailedChecks {
-		slog.Info(
-			"number of last results is not enough",
-			sl.Site(site),
-			slog.Int("last_results", len(lastResults)),
-		)
+func (a *AlertService) sendNotificationIfNeeded(ctx context.Context, result model.CheckResult) error {
+	site, err := a.sitesService.GetSiteById(ctx, result.Site.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get site: %w", err)
+	}
+	if site == nil {
+		slog.Info("site of check result was deleted", sl.Site(result.Site))
 		return nil
 	}
 
-	var message string
-	if len(lastResults) == a.config.NumberOrFailedChecks && a.allChecksFailed(lastResults) {
-		slog.Info("all checks failed", sl.Site(site))
-		message = fmt.Sprintf(
-			"Bad news. The website %s is temporarily unavailable.",
-			site.Url,
-		)
+	if site.State == model.SiteStatePaused {
+		return nil
 	}
 
-	if len(lastResults) == a.config.NumberOrFailedChecks+1 {
-		if lastResults[0].IsSuccessful() && a.allChecksFailed(lastResults[1:]) {
-			slog.Info("website is back up", sl.Site(site))
+	flapping, err := a.detectFlapping(ctx, *site)
+	if err != nil {
+		return fmt.Errorf("failed to detect flapping: %w", err)
+	}
+	if flapping {
+		return nil
+	}
 
-			successfulResult, err := a.resultsService.GetSecondToLastSuccessfulResultForSite(ctx, site)
-			if err != nil {
-				return fmt.Errorf("failed to get second to last successful result for site: %w", err)
-			}
+	switch site.State {
+	case model.SiteStateDown:
+		return a.handleDownSite(ctx, *site, result)
+	case model.SiteStateDegraded:
+		return a.handleDegradedSite(ctx, *site, result)
+	default:
+		return a.handleUpSite(ctx, *site, result)
+	}
+}
 
-			if successfulResult != nil {
-				slog.Info("second to last successful result was found", sl.Site(site))
-				message = fmt.Sprintf(
-					"Good news! The website %s is back up after %d minutes.",
-					site.Url,
-					int(time.Since(successfulResult.Time).Minutes()),
-				)
-			} else {
-				slog.Info("second to last successful result was not found", sl.Site(site))
-				message = fmt.Sprintf("Good news! The website %s is back up.", site.Url)
-			}
-		} else if a.allChecksFailed(lastResults[:a.config.NumberOrFailedChecks]) &&
-			lastResults[len(lastResults)-1].IsSuccessful() {
-			message = fmt.Sprintf(
-				"Bad news. The website %s is temporarily unavailable.",
-				site.Url,
-			)
-		}
+// handleUpSite opens a down incident once alert rules of a site that is up
+// (or whose state is not known yet) match its last checks, or a degraded
+// incident once its checks exceed latency thresholds.
+func (a *AlertService) handleUpSite(
+	ctx context.Context,
+	site model.Site,
+	result model.CheckResult,
+) error {
+	opened, err := a.openDownIncidentIfNeeded(ctx, site, result, nil)
+	if err != nil || opened || !result.IsSuccessful() {
+		return err
+	}
 
+	degraded, err := a.openDegradedIncidentIfNeeded(ctx, site, result)
+	if err != nil {
+		return err
+	}
+	if !degraded && site.State == model.SiteStateUnknown {
+		return a.incidentsService.SetSiteState(ctx, site.Id, model.SiteStateUp, nil)
 	}
+	return nil
+}
 
-	if message != "" {
-		notification := model.Notification{
-			Url:     site.Url,
-			Message: message,
-		}
-		slog.Info("sending notification", sl.Notification(notification))
-		return a.broker.PublishNotification(ctx, notification)
+// openDownIncidentIfNeeded opens a down incident if alert rules of the site
+// match its last checks. The degraded incident of the site, if any, ends
+// when the down incident starts. It reports whether the incident was opened.
+func (a *AlertService) openDownIncidentIfNeeded(
+	ctx context.Context,
+	site model.Site,
+	result model.CheckResult,
+	degraded *model.Incident,
+) (bool, error) {
+	match, err := a.matchRules(ctx, site)
+	if err != nil {
+		return false, err
+	}
+	if match == nil {
+		slog.Info("alert rules of site do not match", sl.Site(site))
+		return false, nil
+	}
+
+	firstFailed := match.firstFailed()
+
+	cause := result.FailureReason
+	if cause == "" {
+		cause = match.rule.Condition.String()
+	}
+	incident := model.Incident{
+		SiteId:              site.Id,
+		Kind:                model.IncidentKindDown,
+		StartedAt:           firstFailed.Time,
+		Cause:               cause,
+		FirstFailedResultId: firstFailed.Id,
+		LastFailedResultId:  result.Id,
+	}
+	notification := model.Notification{
+		Url:        site.Url,
+		Message:    unavailableMessage(site, cause),
+		Event:      model.NotificationEventDown,
+		Site:       &site,
+		Incident:   &incident,
+		Transition: &model.StateTransition{From: site.State, To: model.SiteStateDown},
+		Result:     &result,
 	}
+	if degraded != nil {
+		notification.ReplacedIncidentId = degraded.Id
+	}
+	// the notification is added to pending notifications with the incident
+	// and published once the incident is opened
+	incident.Id, err = a.incidentsService.OpenIncident(ctx, incident, &notification)
+	if err != nil {
+		return false, fmt.Errorf("failed to open incident: %w", err)
+	}
+	slog.Info(
+		"incident was opened",
+		sl.Site(site),
+		slog.Int64("incident_id", incident.Id),
+		slog.String("rule", match.rule.Condition.String()),
+	)
+
+	// steps without delay are run right away instead of on the next tick
+	return true, a.escalate(ctx, site, incident, time.Now())
+}
+
+// handleDownSite resolves the open incident of a site that is down on the
+// first successful check which does not match alert rules, or records the
+// failed check in it otherwise.
+func (a *AlertService) handleDownSite(
+	ctx context.Context,
+	site model.Site,
+	result model.CheckResult,
+) error {
+	incident, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get open incident: %w", err)
+	}
+	if incident == nil {
+		slog.Warn("site is down without open incident", sl.Site(site))
+		return a.incidentsService.SetSiteState(ctx, site.Id, model.SiteStateUnknown, nil)
+	}
+
+	if !result.IsSuccessful() {
+		return a.incidentsService.AddFailureToIncident(ctx, incident.Id, result.Id)
+	}
+
+	match, err := a.matchRules(ctx, site)
+	if err != nil || match != nil {
+		return err
+	}
+
+	resolved := *incident
+	resolved.EndedAt = sql.NullTime{Time: result.Time, Valid: true}
+	notification := model.Notification{
+		Url: site.Url,
+		Message: fmt.Sprintf(
+			"Good news! The website %s is back up after %d minutes.",
+			site.Url,
+			int(resolved.Duration(result.Time).Minutes()),
+		),
+		Event:      model.NotificationEventUp,
+		IncidentId: resolved.Id,
+		Site:       &site,
+		Incident:   &resolved,
+		Transition: &model.StateTransition{From: site.State, To: model.SiteStateUp},
+		Result:     &result,
+	}
+	err = a.incidentsService.ResolveIncident(ctx, *incident, result.Time, &notification)
+	if err != nil {
+		return fmt.Errorf("failed to resolve incident: %w", err)
+	}
+	slog.Info("incident was resolved", sl.Site(site), slog.Int64("incident_id", incident.Id))
 	return nil
 }
 
-func (a *AlertService) allChecksFailed(results []model.CheckResult) bool {
-	for _, res := range results {
-		if res.IsSuccessful() {
-			return false
-		}
+func (a *AlertService) publishNotification(
+	ctx context.Context,
+	notification model.Notification,
+) error {
+	slog.Info("sending notification", sl.Notification(notification))
+	return a.broker.PublishNotification(ctx, notification)
+}
+
+func unavailableMessage(site model.Site, cause string) string {
+	if cause == "" {
+		return fmt.Sprintf("Bad news. The website %s is temporarily unavailable.", site.Url)
 	}
-	return true
+	return fmt.Sprintf(
+		"Bad news. The website %s is temporarily unavailable: %s.",
+		site.Url,
+		cause,
+	)
 }
@@ -0,0 +1,552 @@
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"shm/internal/service"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAlertService returns an alert service with a new SQLite database and
+// the site added to it.
+func newAlertService(t *testing.T, cfg config.AlertServiceConfig, site model.Site) (*AlertService, model.Site) {
+	t.Helper()
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	cfg.CommonConfig = config.CommonConfig{DbQueryTimeoutSec: 5 * time.Second}
+	cfg.EscalationTickSec = time.Minute
+	cfg.RelayTickSec = time.Minute
+	a, err := New(
+		nil,
+		service.NewSitesService(database.SitesRepo(), cfg.CommonConfig),
+		service.NewResultsService(database.ResultsRepo(), cfg.CommonConfig),
+		service.NewCertificatesService(database.CertificatesRepo(), cfg.CommonConfig),
+		service.NewIncidentsService(database.IncidentsRepo(), cfg.CommonConfig),
+		service.NewAlertRulesService(database.AlertRulesRepo(), cfg.CommonConfig),
+		service.NewMaintenanceService(database.MaintenanceRepo(), cfg.CommonConfig),
+		service.NewEscalationPoliciesService(database.EscalationPoliciesRepo(), cfg.CommonConfig),
+		service.NewPendingNotificationsService(database.PendingNotificationsRepo(), cfg.CommonConfig),
+		cfg,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := site.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.sitesService.AddSite(ctx, site); err != nil {
+		t.Fatal(err)
+	}
+	added, err := a.sitesService.GetSiteByUrl(ctx, site.Url)
+	if err != nil || added == nil {
+		t.Fatalf("GetSiteByUrl() = %v, %v", added, err)
+	}
+	return a, *added
+}
+
+// check stores a result of the site checked at the time and handles it.
+func check(t *testing.T, a *AlertService, site model.Site, at time.Time, successful bool, latency int64) {
+	t.Helper()
+	result := model.CheckResult{
+		Site:       site,
+		Time:       at,
+		Successful: successful,
+		Latency:    sql.NullInt64{Int64: latency, Valid: true},
+	}
+	if !successful {
+		result.FailureReason = "connection refused"
+	}
+
+	var err error
+	result.Id, err = a.resultsService.AddResult(context.Background(), result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.handleResult(context.Background(), result); err != nil {
+		t.Fatalf("handleResult() error = %v", err)
+	}
+}
+
+// pendingEvents returns events of the notifications added since the last call
+// and removes them.
+func pendingEvents(t *testing.T, a *AlertService) []model.NotificationEvent {
+	t.Helper()
+	ctx := context.Background()
+	notifications, err := a.pendingService.GetPendingNotifications(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []model.NotificationEvent
+	for _, notification := range notifications {
+		events = append(events, notification.Event)
+		if err := a.pendingService.DeletePendingNotification(ctx, notification.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return events
+}
+
+func siteState(t *testing.T, a *AlertService, site model.Site) model.SiteState {
+	t.Helper()
+	got, err := a.sitesService.GetSiteById(context.Background(), site.Id)
+	if err != nil || got == nil {
+		t.Fatalf("GetSiteById() = %v, %v", got, err)
+	}
+	return got.State
+}
+
+// checkStep is a check of the site with the state and the notifications it
+// leads to.
+type checkStep struct {
+	successful bool
+	latency    int64
+	wantState  model.SiteState
+	wantEvents []model.NotificationEvent
+}
+
+func runChecks(t *testing.T, a *AlertService, site model.Site, steps []checkStep) {
+	t.Helper()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, step := range steps {
+		check(t, a, site, at.Add(time.Duration(i)*time.Minute), step.successful, step.latency)
+
+		if state := siteState(t, a, site); state != step.wantState {
+			t.Errorf("check %d: state = %s, want %s", i+1, state, step.wantState)
+		}
+		if events := pendingEvents(t, a); !reflect.DeepEqual(events, step.wantEvents) {
+			t.Errorf("check %d: events = %v, want %v", i+1, events, step.wantEvents)
+		}
+	}
+}
+
+func TestStateTransitions(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 2,
+		LatencyWindow:        1,
+		FlapWindow:           100,
+		FlapStartPercent:     50,
+		FlapStopPercent:      25,
+	}, model.Site{Url: "https://example.com", Latency: model.LatencySpec{MaxMs: 500}})
+
+	runChecks(t, a, site, []checkStep{
+		{successful: true, latency: 100, wantState: model.SiteStateUp},
+		{
+			successful: true, latency: 800, wantState: model.SiteStateDegraded,
+			wantEvents: []model.NotificationEvent{model.NotificationEventDegraded},
+		},
+		{successful: true, latency: 900, wantState: model.SiteStateDegraded},
+		// a single failure does not match the default rule
+		{successful: false, wantState: model.SiteStateDegraded},
+		{
+			successful: false, wantState: model.SiteStateDown,
+			wantEvents: []model.NotificationEvent{model.NotificationEventDown},
+		},
+		{successful: false, wantState: model.SiteStateDown},
+		{
+			successful: true, latency: 100, wantState: model.SiteStateUp,
+			wantEvents: []model.NotificationEvent{model.NotificationEventUp},
+		},
+		{
+			successful: true, latency: 700, wantState: model.SiteStateDegraded,
+			wantEvents: []model.NotificationEvent{model.NotificationEventDegraded},
+		},
+		{
+			successful: true, latency: 100, wantState: model.SiteStateUp,
+			wantEvents: []model.NotificationEvent{model.NotificationEventRecovered},
+		},
+	})
+}
+
+func TestDownIncidentReplacesDegradedOne(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 1,
+		LatencyWindow:        1,
+		FlapWindow:           100,
+		FlapStartPercent:     50,
+		FlapStopPercent:      25,
+	}, model.Site{Url: "https://example.com", Latency: model.LatencySpec{MaxMs: 500}})
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	check(t, a, site, at, true, 800)
+	degraded, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil || degraded == nil {
+		t.Fatalf("GetOpenIncidentBySiteId() = %v, %v", degraded, err)
+	}
+	pendingEvents(t, a)
+
+	check(t, a, site, at.Add(time.Minute), false, 0)
+	notifications, err := a.pendingService.GetPendingNotifications(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 || notifications[0].ReplacedIncidentId != degraded.Id {
+		t.Fatalf("notifications = %+v, want down replacing incident %d", notifications, degraded.Id)
+	}
+
+	down, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil || down == nil {
+		t.Fatalf("GetOpenIncidentBySiteId() = %v, %v", down, err)
+	}
+	if down.Id == degraded.Id || down.Kind != model.IncidentKindDown {
+		t.Errorf("open incident = %+v, want a new down incident", down)
+	}
+}
+
+func TestFlappingSuppressesNotifications(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 1,
+		LatencyWindow:        1,
+		FlapWindow:           4,
+		FlapStartPercent:     60,
+		FlapStopPercent:      30,
+	}, model.Site{Url: "https://example.com"})
+
+	runChecks(t, a, site, []checkStep{
+		{successful: true, wantState: model.SiteStateUp},
+		{
+			successful: false, wantState: model.SiteStateDown,
+			wantEvents: []model.NotificationEvent{model.NotificationEventDown},
+		},
+		{
+			successful: true, wantState: model.SiteStateUp,
+			wantEvents: []model.NotificationEvent{model.NotificationEventUp},
+		},
+		// state changed in 3 of 3 checks
+		{
+			successful: false, wantState: model.SiteStateFlapping,
+			wantEvents: []model.NotificationEvent{model.NotificationEventFlapping},
+		},
+		{successful: true, wantState: model.SiteStateFlapping},
+		{successful: false, wantState: model.SiteStateFlapping},
+		{successful: true, wantState: model.SiteStateFlapping},
+		// state changed in 2 of 3 checks
+		{successful: true, wantState: model.SiteStateFlapping},
+		// state changed in 1 of 3 checks, which is above the stop threshold
+		{successful: true, wantState: model.SiteStateFlapping},
+		{
+			successful: true, wantState: model.SiteStateUnknown,
+			wantEvents: []model.NotificationEvent{model.NotificationEventStabilized},
+		},
+		{successful: true, wantState: model.SiteStateUp},
+	})
+}
+
+func TestMaintenanceSuppressesAlerts(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 1,
+		LatencyWindow:        1,
+		FlapWindow:           100,
+		FlapStartPercent:     50,
+		FlapStopPercent:      25,
+	}, model.Site{Url: "https://example.com"})
+
+	// checks of runChecks start at 12:00, the window lasts until 12:02
+	window := model.MaintenanceWindow{SiteId: site.Id, Cron: "0 12 * * *", DurationMin: 2}
+	if err := window.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.maintenanceService.AddWindow(context.Background(), window); err != nil {
+		t.Fatal(err)
+	}
+
+	runChecks(t, a, site, []checkStep{
+		{successful: false, wantState: model.SiteStateUnknown},
+		{successful: false, wantState: model.SiteStateUnknown},
+		{
+			successful: false, wantState: model.SiteStateDown,
+			wantEvents: []model.NotificationEvent{model.NotificationEventDown},
+		},
+	})
+}
+
+func TestEscalation(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 1,
+		LatencyWindow:        1,
+		FlapWindow:           100,
+		FlapStartPercent:     50,
+		FlapStopPercent:      25,
+	}, model.Site{Url: "https://example.com"})
+	ctx := context.Background()
+
+	// the second step was saved before steps notified channels and is
+	// skipped instead of failing on every tick
+	_, err := a.escalationPoliciesService.AddPolicy(ctx, model.EscalationPolicy{
+		SiteId: site.Id,
+		Steps: model.EscalationSteps{
+			{DelayMin: 0, ChatId: 5},
+			{DelayMin: 0},
+			{DelayMin: 10, ChannelId: 7},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the incident is opened now, as escalation runs steps whose delay has
+	// passed by the clock
+	check(t, a, site, time.Now(), false, 0)
+	want := []model.NotificationEvent{model.NotificationEventDown, model.NotificationEventEscalation}
+	if events := pendingEvents(t, a); !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+
+	incident, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil || incident == nil {
+		t.Fatalf("GetOpenIncidentBySiteId() = %v, %v", incident, err)
+	}
+	if incident.EscalationStep != 2 {
+		t.Errorf("escalation step = %d, want 2", incident.EscalationStep)
+	}
+
+	tests := []struct {
+		name           string
+		after          time.Duration
+		wantChannelIds []int64
+	}{
+		{name: "before delay", after: 9 * time.Minute},
+		{name: "after delay", after: 10 * time.Minute, wantChannelIds: []int64{7}},
+		{name: "step is run once", after: 11 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incident, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+			if err != nil || incident == nil {
+				t.Fatalf("GetOpenIncidentBySiteId() = %v, %v", incident, err)
+			}
+			if err := a.escalate(ctx, site, *incident, incident.StartedAt.Add(tt.after)); err != nil {
+				t.Fatalf("escalate() error = %v", err)
+			}
+
+			notifications, err := a.pendingService.GetPendingNotifications(ctx, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var channelIds []int64
+			for _, notification := range notifications {
+				channelIds = append(channelIds, notification.ChannelIds...)
+			}
+			if !reflect.DeepEqual(channelIds, tt.wantChannelIds) {
+				t.Errorf("escalated to channels %v, want %v", channelIds, tt.wantChannelIds)
+			}
+			pendingEvents(t, a)
+		})
+	}
+}
+
+func TestResumedSiteIsResolved(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 1,
+		LatencyWindow:        1,
+		FlapWindow:           100,
+		FlapStartPercent:     50,
+		FlapStopPercent:      25,
+	}, model.Site{Url: "https://example.com"})
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	check(t, a, site, at, false, 0)
+	if events := pendingEvents(t, a); !reflect.DeepEqual(events, []model.NotificationEvent{model.NotificationEventDown}) {
+		t.Fatalf("events = %v, want down", events)
+	}
+
+	// the site is paused and resumed with the state of its open incident
+	if err := a.incidentsService.SetSiteState(ctx, site.Id, model.SiteStatePaused, nil); err != nil {
+		t.Fatal(err)
+	}
+	check(t, a, site, at.Add(time.Minute), false, 0)
+	if err := a.incidentsService.SetSiteState(ctx, site.Id, model.SiteStateDown, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	check(t, a, site, at.Add(2*time.Minute), true, 100)
+	if events := pendingEvents(t, a); !reflect.DeepEqual(events, []model.NotificationEvent{model.NotificationEventUp}) {
+		t.Errorf("events = %v, want up", events)
+	}
+	if state := siteState(t, a, site); state != model.SiteStateUp {
+		t.Errorf("state = %s, want up", state)
+	}
+	incident, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil || incident != nil {
+		t.Errorf("GetOpenIncidentBySiteId() = %v, %v, want no open incident", incident, err)
+	}
+}
+
+func TestEscalateIncidents(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name            string
+		state           model.SiteState
+		inMaintenance   bool
+		wantEscalations int
+	}{
+		{name: "down site", state: model.SiteStateDown, wantEscalations: 1},
+		{name: "paused site", state: model.SiteStatePaused},
+		{name: "site in maintenance", state: model.SiteStateDown, inMaintenance: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, site := newAlertService(t, config.AlertServiceConfig{
+				NumberOrFailedChecks: 1,
+				LatencyWindow:        1,
+				FlapWindow:           100,
+				FlapStartPercent:     50,
+				FlapStopPercent:      25,
+			}, model.Site{Url: "https://example.com"})
+			ctx := context.Background()
+
+			policy := model.EscalationPolicy{SiteId: site.Id, Steps: model.EscalationSteps{{DelayMin: 10, ChatId: 5}}}
+			if _, err := a.escalationPoliciesService.AddPolicy(ctx, policy); err != nil {
+				t.Fatal(err)
+			}
+			incident := model.Incident{SiteId: site.Id, Kind: model.IncidentKindDown, StartedAt: now.Add(-time.Hour)}
+			if _, err := a.incidentsService.OpenIncident(ctx, incident, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.incidentsService.SetSiteState(ctx, site.Id, tt.state, nil); err != nil {
+				t.Fatal(err)
+			}
+			if tt.inMaintenance {
+				window := model.MaintenanceWindow{SiteId: site.Id, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}
+				if err := window.Normalize(); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := a.maintenanceService.AddWindow(ctx, window); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := a.escalateIncidents(ctx, now); err != nil {
+				t.Fatalf("escalateIncidents() error = %v", err)
+			}
+			if events := pendingEvents(t, a); len(events) != tt.wantEscalations {
+				t.Errorf("events = %v, want %d escalations", events, tt.wantEscalations)
+			}
+		})
+	}
+}
+
+func TestCertificateWarning(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks:  1,
+		LatencyWindow:         1,
+		FlapWindow:            100,
+		FlapStartPercent:      50,
+		FlapStopPercent:       25,
+		CertExpiryWarningDays: []int{30, 7},
+	}, model.Site{Url: "https://example.com"})
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		expiresIn  time.Duration
+		wantEvents []model.NotificationEvent
+	}{
+		{name: "far from expiry", expiresIn: 60 * 24 * time.Hour},
+		{
+			name:       "first threshold",
+			expiresIn:  20 * 24 * time.Hour,
+			wantEvents: []model.NotificationEvent{model.NotificationEventCertExpiry},
+		},
+		// a result consumed again does not warn twice
+		{name: "same threshold", expiresIn: 20 * 24 * time.Hour},
+		{
+			name:       "second threshold",
+			expiresIn:  5 * 24 * time.Hour,
+			wantEvents: []model.NotificationEvent{model.NotificationEventCertExpiry},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := model.Certificate{
+				SiteId:    site.Id,
+				Subject:   "example.com",
+				Issuer:    "Test CA",
+				NotBefore: now.Add(-24 * time.Hour),
+				ExpiresAt: now.Add(tt.expiresIn).Truncate(time.Hour),
+				CheckedAt: now,
+			}
+			if err := a.certificatesService.SaveCertificate(ctx, cert); err != nil {
+				t.Fatal(err)
+			}
+
+			result := model.CheckResult{Site: site, Time: now, Successful: true, Certificate: &cert}
+			if err := a.sendCertificateWarningIfNeeded(ctx, result); err != nil {
+				t.Fatalf("sendCertificateWarningIfNeeded() error = %v", err)
+			}
+			if events := pendingEvents(t, a); !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestAnswersChange(t *testing.T) {
+	a, site := newAlertService(t, config.AlertServiceConfig{
+		NumberOrFailedChecks: 1,
+		LatencyWindow:        1,
+		FlapWindow:           100,
+		FlapStartPercent:     50,
+		FlapStopPercent:      25,
+	}, model.Site{Url: "dns://example.com"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		answers    model.Strings
+		wantChange bool
+	}{
+		{name: "first answers are recorded", answers: model.Strings{"192.0.2.1"}},
+		{name: "same answers", answers: model.Strings{"192.0.2.1"}},
+		{name: "no answers", answers: nil},
+		{name: "changed answers", answers: model.Strings{"192.0.2.2"}, wantChange: true},
+		// a result consumed again does not notify twice
+		{name: "change is notified once", answers: model.Strings{"192.0.2.2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := model.CheckResult{Site: site, Time: time.Now(), Successful: true, Answers: tt.answers}
+			if err := a.sendAnswersChangeIfNeeded(ctx, result); err != nil {
+				t.Fatalf("sendAnswersChangeIfNeeded() error = %v", err)
+			}
+
+			notifications, err := a.pendingService.GetPendingNotifications(ctx, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := len(notifications) == 1; changed != tt.wantChange {
+				t.Fatalf("notifications = %v, want change %v", notifications, tt.wantChange)
+			}
+			if tt.wantChange {
+				notification := notifications[0]
+				if notification.Event != model.NotificationEventDNSChanged || notification.Site == nil {
+					t.Errorf("notification = %+v, want dns change with site", notification)
+				}
+				if !strings.Contains(notification.Message, "from [192.0.2.1] to [192.0.2.2]") {
+					t.Errorf("message = %q", notification.Message)
+				}
+			}
+			pendingEvents(t, a)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"time"
+)
+
+// sendCertificateWarningIfNeeded warns subscribers once per configured number
+// of days left before expiry of the site certificate. The warning is added to
+// pending notifications together with recording it.
+func (a *AlertService) sendCertificateWarningIfNeeded(
+	ctx context.Context,
+	result model.CheckResult,
+) error {
+	if result.Certificate == nil || len(a.config.CertExpiryWarningDays) == 0 {
+		return nil
+	}
+
+	daysLeft := result.Certificate.DaysLeft(time.Now())
+	threshold, ok := a.warningThreshold(daysLeft)
+	if !ok {
+		return nil
+	}
+
+	cert, err := a.certificatesService.GetCertificateBySiteId(ctx, result.Site.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get certificate of site: %w", err)
+	}
+	if cert == nil || !cert.ExpiresAt.Equal(result.Certificate.ExpiresAt) {
+		slog.Info("certificate of check result is outdated", sl.Site(result.Site))
+		return nil
+	}
+	if cert.WarnedDays.Valid && cert.WarnedDays.Int64 <= int64(threshold) {
+		return nil
+	}
+
+	notification := model.Notification{
+		Url:   result.Site.Url,
+		Event: model.NotificationEventCertExpiry,
+		Site:  &result.Site,
+		Message: fmt.Sprintf(
+			"Warning! The TLS certificate of the website %s expires in %d days (%s).",
+			result.Site.Url,
+			daysLeft,
+			cert.ExpiresAt.UTC().Format(time.DateOnly),
+		),
+	}
+	warned, err := a.certificatesService.SetWarnedDays(ctx, result.Site.Id, threshold, notification)
+	if err != nil {
+		return fmt.Errorf("failed to record certificate expiry warning: %w", err)
+	}
+	if warned {
+		slog.Info("sending certificate expiry warning", sl.Notification(notification))
+	}
+	return nil
+}
+
+// warningThreshold returns the smallest configured number of days which is
+// not less than daysLeft.
+func (a *AlertService) warningThreshold(daysLeft int) (int, bool) {
+	threshold, found := 0, false
+	for _, days := range a.config.CertExpiryWarningDays {
+		if daysLeft <= days && (!found || days < threshold) {
+			threshold, found = days, true
+		}
+	}
+	return threshold, found
+}
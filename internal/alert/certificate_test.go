@@ -0,0 +1,36 @@
+package alert
+
+import (
+	"shm/internal/config"
+	"testing"
+)
+
+func TestWarningThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		days      []int
+		daysLeft  int
+		want      int
+		wantFound bool
+	}{
+		{name: "far from expiry", days: []int{30, 14, 7, 1}, daysLeft: 45},
+		{name: "first threshold", days: []int{30, 14, 7, 1}, daysLeft: 30, want: 30, wantFound: true},
+		{name: "between thresholds", days: []int{30, 14, 7, 1}, daysLeft: 20, want: 30, wantFound: true},
+		{name: "smallest matching threshold", days: []int{30, 14, 7, 1}, daysLeft: 7, want: 7, wantFound: true},
+		{name: "last day", days: []int{30, 14, 7, 1}, daysLeft: 0, want: 1, wantFound: true},
+		{name: "expired", days: []int{30, 14, 7, 1}, daysLeft: -3, want: 1, wantFound: true},
+		{name: "unsorted thresholds", days: []int{1, 30, 7, 14}, daysLeft: 10, want: 14, wantFound: true},
+		{name: "duplicated thresholds", days: []int{7, 7}, daysLeft: 5, want: 7, wantFound: true},
+		{name: "no thresholds", days: nil, daysLeft: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &AlertService{config: config.AlertServiceConfig{CertExpiryWarningDays: tt.days}}
+			got, found := a.warningThreshold(tt.daysLeft)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("warningThreshold(%d) = %d, %v, want %d, %v", tt.daysLeft, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"slices"
+	"strings"
+)
+
+// sendAnswersChangeIfNeeded notifies subscribers when answers of a DNS check
+// differ from the answers they were last notified about. The notification is
+// added to pending notifications together with the new answers. The first
+// answers of a site are recorded without notification.
+func (a *AlertService) sendAnswersChangeIfNeeded(ctx context.Context, result model.CheckResult) error {
+	if result.Site.Type != model.CheckTypeDNS || len(result.Answers) == 0 {
+		return nil
+	}
+
+	previous, err := a.sitesService.GetDNSAnswers(ctx, result.Site.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get dns answers of site: %w", err)
+	}
+	if slices.Equal(previous, result.Answers) {
+		return nil
+	}
+
+	var notification *model.Notification
+	if len(previous) > 0 {
+		notification = &model.Notification{
+			Url:   result.Site.Url,
+			Event: model.NotificationEventDNSChanged,
+			Message: fmt.Sprintf(
+				"Attention! DNS answers for %s changed from [%s] to [%s].",
+				result.Site.Url,
+				strings.Join(previous, ", "),
+				strings.Join(result.Answers, ", "),
+			),
+			Site: &result.Site,
+		}
+	}
+
+	updated, err := a.sitesService.UpdateDNSAnswers(ctx, result.Site.Id, previous, result.Answers, notification)
+	if err != nil {
+		return fmt.Errorf("failed to update dns answers of site: %w", err)
+	}
+	if updated && notification != nil {
+		slog.Info(
+			"dns answers changed",
+			sl.Site(result.Site),
+			slog.Any("previous", previous),
+			slog.Any("current", result.Answers),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"time"
+)
+
+// escalationRoutine periodically runs due escalation steps of open down
+// incidents which nobody acknowledged.
+func (a *AlertService) escalationRoutine(ctx context.Context) error {
+	t := time.NewTicker(a.config.EscalationTickSec)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		if err := a.escalateIncidents(ctx, time.Now()); err != nil {
+			return err
+		}
+		a.relayNotifications(ctx)
+	}
+}
+
+// escalateIncidents runs due escalation steps of all escalating incidents.
+// Sites in maintenance are not escalated, their steps run once the
+// maintenance ends.
+func (a *AlertService) escalateIncidents(ctx context.Context, now time.Time) error {
+	incidents, err := a.incidentsService.GetEscalatingIncidents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get escalating incidents: %w", err)
+	}
+
+	for _, incident := range incidents {
+		site, err := a.sitesService.GetSiteById(ctx, incident.SiteId)
+		if err != nil {
+			return fmt.Errorf("failed to get site: %w", err)
+		}
+		if site == nil {
+			continue
+		}
+
+		inMaintenance, err := a.maintenanceService.InMaintenance(ctx, *site, now)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance windows: %w", err)
+		}
+		if inMaintenance {
+			continue
+		}
+
+		if err := a.escalate(ctx, *site, incident, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escalate runs steps of the escalation policy of the site whose delay since
+// the start of the incident has passed. Every step is completed in the
+// incident together with adding its notification to pending notifications,
+// so it is not run twice by this routine and by the alert service which
+// opened the incident.
+func (a *AlertService) escalate(
+	ctx context.Context,
+	site model.Site,
+	incident model.Incident,
+	now time.Time,
+) error {
+	policy, err := a.escalationPoliciesService.GetEffectivePolicy(ctx, site)
+	if err != nil {
+		return fmt.Errorf("failed to get escalation policy: %w", err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	for step := incident.EscalationStep; step < len(policy.Steps); step++ {
+		delay := time.Duration(policy.Steps[step].DelayMin) * time.Minute
+		if now.Before(incident.StartedAt.Add(delay)) {
+			return nil
+		}
+
+		var notification *model.Notification
+		if err := policy.Steps[step].Validate(); err != nil {
+			// steps saved before webhooks were replaced by channels notify
+			// nobody, they are completed without notification instead of
+			// failing on every tick
+			slog.Error(
+				"escalation step is invalid and is skipped",
+				sl.Site(site),
+				slog.Int64("incident_id", incident.Id),
+				slog.Int("step", step),
+				sl.Error(err),
+			)
+		} else {
+			notification = escalationNotification(site, incident, policy.Steps[step], now)
+		}
+
+		advanced, err := a.incidentsService.AdvanceEscalationStep(ctx, incident.Id, step, notification)
+		if err != nil {
+			// the step is retried on the next tick
+			slog.Error(
+				"failed to run escalation step",
+				sl.Site(site),
+				slog.Int64("incident_id", incident.Id),
+				slog.Int("step", step),
+				sl.Error(err),
+			)
+			return nil
+		}
+		if !advanced {
+			// the step was already run by another alert service
+			return nil
+		}
+	}
+	return nil
+}
+
+// escalationNotification returns the notification sent by the valid step.
+func escalationNotification(
+	site model.Site,
+	incident model.Incident,
+	step model.EscalationStep,
+	now time.Time,
+) *model.Notification {
+	notification := model.Notification{
+		Url: site.Url,
+		Message: fmt.Sprintf(
+			"Escalation! The website %s has been down for %d minutes and nobody acknowledged it.",
+			site.Url,
+			int(incident.Duration(now).Minutes()),
+		),
+		Event:      model.NotificationEventEscalation,
+		IncidentId: incident.Id,
+		Site:       &site,
+		Incident:   &incident,
+	}
+	if step.ChannelId != 0 {
+		notification.ChannelIds = []int64{step.ChannelId}
+	} else {
+		notification.ChatIds = []int64{step.ChatId}
+	}
+	return &notification
+}
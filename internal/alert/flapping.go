@@ -0,0 +1,107 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+)
+
+// detectFlapping updates the flapping state of the site using the share of
+// state changes among its last checks. A site starts flapping when the share
+// reaches the start threshold and stabilizes when it drops to the stop
+// threshold, so that it does not toggle between the two on every check. It
+// reports whether up and down notifications of the site must be suppressed.
+func (a *AlertService) detectFlapping(ctx context.Context, site model.Site) (bool, error) {
+	lastResults, err := a.resultsService.GetNLastResultsForSite(ctx, site, a.config.FlapWindow)
+	if err != nil {
+		return false, fmt.Errorf("failed to get last results for site: %w", err)
+	}
+
+	if len(lastResults) < a.config.FlapWindow {
+		return site.State == model.SiteStateFlapping, nil
+	}
+
+	percent := stateChangePercent(lastResults)
+	if site.State != model.SiteStateFlapping {
+		if percent < a.config.FlapStartPercent {
+			return false, nil
+		}
+		return true, a.startFlapping(ctx, site, percent)
+	}
+
+	if percent > a.config.FlapStopPercent {
+		return true, nil
+	}
+	// the result which stabilized the site is only used to leave the
+	// flapping state, the next one decides whether the site is up or down
+	return true, a.stopFlapping(ctx, site, percent)
+}
+
+func (a *AlertService) startFlapping(ctx context.Context, site model.Site, percent int) error {
+	slog.Info("site started flapping", sl.Site(site), slog.Int("percent", percent))
+	notification := model.Notification{
+		Url: site.Url,
+		Message: fmt.Sprintf(
+			"Attention! The website %s is flapping: its state changed in %d%% of the last %d checks. "+
+				"Notifications about it going up and down are paused until it stabilizes.",
+			site.Url,
+			percent,
+			a.config.FlapWindow,
+		),
+		Event:      model.NotificationEventFlapping,
+		Site:       &site,
+		Transition: &model.StateTransition{From: site.State, To: model.SiteStateFlapping},
+	}
+	err := a.incidentsService.SetSiteState(ctx, site.Id, model.SiteStateFlapping, &notification)
+	if err != nil {
+		return fmt.Errorf("failed to set state of site: %w", err)
+	}
+	return nil
+}
+
+// stopFlapping returns the site to the state machine. A site with an open
+// incident keeps its state, otherwise its state is decided by the next check.
+func (a *AlertService) stopFlapping(ctx context.Context, site model.Site, percent int) error {
+	slog.Info("site stabilized", sl.Site(site), slog.Int("percent", percent))
+
+	incident, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get open incident: %w", err)
+	}
+
+	state := model.SiteStateUnknown
+	if incident != nil {
+		state = incident.State()
+	}
+	notification := model.Notification{
+		Url:        site.Url,
+		Message:    fmt.Sprintf("The website %s has stabilized.", site.Url),
+		Event:      model.NotificationEventStabilized,
+		Site:       &site,
+		Incident:   incident,
+		Transition: &model.StateTransition{From: site.State, To: state},
+	}
+	err = a.incidentsService.SetSiteState(ctx, site.Id, state, &notification)
+	if err != nil {
+		return fmt.Errorf("failed to set state of site: %w", err)
+	}
+	return nil
+}
+
+// stateChangePercent returns the share of consecutive results with different
+// outcomes.
+func stateChangePercent(results []model.CheckResult) int {
+	if len(results) < 2 {
+		return 0
+	}
+
+	changes := 0
+	for i := 1; i < len(results); i++ {
+		if results[i].IsSuccessful() != results[i-1].IsSuccessful() {
+			changes++
+		}
+	}
+	return changes * 100 / (len(results) - 1)
+}
@@ -0,0 +1,120 @@
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+)
+
+// openDegradedIncidentIfNeeded opens a degraded incident if the successful
+// check exceeds latency thresholds of the site. It reports whether the
+// incident was opened.
+func (a *AlertService) openDegradedIncidentIfNeeded(
+	ctx context.Context,
+	site model.Site,
+	result model.CheckResult,
+) (bool, error) {
+	reason, err := a.degradation(ctx, site)
+	if err != nil || reason == "" {
+		return false, err
+	}
+
+	incident := model.Incident{
+		SiteId:              site.Id,
+		Kind:                model.IncidentKindDegraded,
+		StartedAt:           result.Time,
+		Cause:               reason,
+		FirstFailedResultId: result.Id,
+		LastFailedResultId:  result.Id,
+	}
+	notification := model.Notification{
+		Url: site.Url,
+		Message: fmt.Sprintf(
+			"Attention! The website %s is responding slowly: %s.",
+			site.Url,
+			reason,
+		),
+		Event:      model.NotificationEventDegraded,
+		Site:       &site,
+		Incident:   &incident,
+		Transition: &model.StateTransition{From: site.State, To: model.SiteStateDegraded},
+		Result:     &result,
+	}
+	incident.Id, err = a.incidentsService.OpenIncident(ctx, incident, &notification)
+	if err != nil {
+		return false, fmt.Errorf("failed to open degraded incident: %w", err)
+	}
+	slog.Info("site is degraded", sl.Site(site), slog.Int64("incident_id", incident.Id))
+	return true, nil
+}
+
+// handleDegradedSite resolves the degraded incident of a site once its checks
+// are within latency thresholds again, and replaces it with a down incident
+// once alert rules match.
+func (a *AlertService) handleDegradedSite(
+	ctx context.Context,
+	site model.Site,
+	result model.CheckResult,
+) error {
+	incident, err := a.incidentsService.GetOpenIncidentBySiteId(ctx, site.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get open incident: %w", err)
+	}
+	if incident == nil {
+		slog.Warn("site is degraded without open incident", sl.Site(site))
+		return a.incidentsService.SetSiteState(ctx, site.Id, model.SiteStateUnknown, nil)
+	}
+
+	opened, err := a.openDownIncidentIfNeeded(ctx, site, result, incident)
+	if err != nil || opened || !result.IsSuccessful() {
+		return err
+	}
+
+	reason, err := a.degradation(ctx, site)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return a.incidentsService.AddFailureToIncident(ctx, incident.Id, result.Id)
+	}
+
+	resolved := *incident
+	resolved.EndedAt = sql.NullTime{Time: result.Time, Valid: true}
+	notification := model.Notification{
+		Url: site.Url,
+		Message: fmt.Sprintf(
+			"Good news! The website %s is responding normally again after %d minutes.",
+			site.Url,
+			int(resolved.Duration(result.Time).Minutes()),
+		),
+		Event:      model.NotificationEventRecovered,
+		IncidentId: resolved.Id,
+		Site:       &site,
+		Incident:   &resolved,
+		Transition: &model.StateTransition{From: site.State, To: model.SiteStateUp},
+		Result:     &result,
+	}
+	err = a.incidentsService.ResolveIncident(ctx, *incident, result.Time, &notification)
+	if err != nil {
+		return fmt.Errorf("failed to resolve degraded incident: %w", err)
+	}
+	slog.Info("site is no longer degraded", sl.Site(site), slog.Int64("incident_id", incident.Id))
+	return nil
+}
+
+// degradation returns the reason why the site is degraded according to its
+// last checks, or empty string if it is not.
+func (a *AlertService) degradation(ctx context.Context, site model.Site) (string, error) {
+	if !site.Latency.Enabled() {
+		return "", nil
+	}
+
+	lastResults, err := a.resultsService.GetNLastResultsForSite(ctx, site, a.config.LatencyWindow)
+	if err != nil {
+		return "", fmt.Errorf("failed to get last results for site: %w", err)
+	}
+	return site.Latency.Degradation(lastResults), nil
+}
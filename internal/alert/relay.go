@@ -0,0 +1,69 @@
+package alert
+
+import (
+	"context"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"time"
+)
+
+// relayBatchSize is the number of pending notifications read at once.
+const relayBatchSize = 100
+
+// relayRoutine periodically publishes notifications which stayed pending
+// because the broker failed or the service stopped after the state change was
+// committed.
+func (a *AlertService) relayRoutine(ctx context.Context) error {
+	t := time.NewTicker(a.config.RelayTickSec)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		a.relayNotifications(ctx)
+	}
+}
+
+// relayNotifications publishes pending notifications in order and removes
+// each of them once the broker confirms it. It stops at the first failure,
+// so that notifications are not reordered, and the rest are published by
+// relayRoutine. A notification published but not removed is published
+// again, and the notifier delivers it once by its id.
+func (a *AlertService) relayNotifications(ctx context.Context) {
+	a.relayMu.Lock()
+	defer a.relayMu.Unlock()
+
+	for {
+		notifications, err := a.pendingService.GetPendingNotifications(ctx, relayBatchSize)
+		if err != nil {
+			slog.Error("failed to get pending notifications", sl.Error(err))
+			return
+		}
+		if len(notifications) == 0 {
+			return
+		}
+
+		for _, notification := range notifications {
+			if err := a.publishNotification(ctx, notification); err != nil {
+				slog.Warn(
+					"failed to publish pending notification, it will be published later",
+					sl.Notification(notification),
+					sl.Error(err),
+				)
+				return
+			}
+			if err := a.pendingService.DeletePendingNotification(ctx, notification.Id); err != nil {
+				slog.Error(
+					"failed to remove published notification, it will be published again",
+					sl.Notification(notification),
+					sl.Error(err),
+				)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"shm/internal/model"
+	"time"
+)
+
+// ruleMatch is an alert rule which matched the last results of a site.
+type ruleMatch struct {
+	rule    model.AlertRule
+	results []model.CheckResult
+}
+
+// firstFailed returns the first result of the trailing run of failed results,
+// which is where the incident starts. If the last result is successful, e.g.
+// for latency rules, the incident starts at it.
+func (m *ruleMatch) firstFailed() model.CheckResult {
+	first := m.results[0]
+	for _, result := range m.results {
+		if result.IsSuccessful() {
+			break
+		}
+		first = result
+	}
+	return first
+}
+
+// matchRules evaluates alert rules of the site on its last results and
+// returns the first matched rule, or nil if none matched. Sites without rules
+// use the configured number of consecutive failed checks.
+func (a *AlertService) matchRules(ctx context.Context, site model.Site) (*ruleMatch, error) {
+	rules, err := a.alertRulesService.GetEffectiveRules(ctx, site)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules for site: %w", err)
+	}
+	if len(rules) == 0 {
+		rules = []model.AlertRule{a.defaultRule(site)}
+	}
+
+	results, err := a.resultsForRules(ctx, site, rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	for _, rule := range rules {
+		if rule.Condition.Matches(results) {
+			return &ruleMatch{rule: rule, results: results}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *AlertService) defaultRule(site model.Site) model.AlertRule {
+	return model.AlertRule{
+		SiteId: site.Id,
+		Condition: model.Condition{
+			Type:  model.ConditionConsecutiveFailures,
+			Count: a.config.NumberOrFailedChecks,
+		},
+	}
+}
+
+// resultsForRules loads enough last results of the site to evaluate all the
+// rules. Both the last n results and results of the last period start with
+// the newest result, so the longer of them contains the other one.
+func (a *AlertService) resultsForRules(
+	ctx context.Context,
+	site model.Site,
+	rules []model.AlertRule,
+) ([]model.CheckResult, error) {
+	count, window := 1, time.Duration(0)
+	for _, rule := range rules {
+		ruleCount, ruleWindow := rule.Condition.Requirements()
+		count = max(count, ruleCount)
+		window = max(window, ruleWindow)
+	}
+
+	results, err := a.resultsService.GetNLastResultsForSite(ctx, site, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last results for site: %w", err)
+	}
+	if window == 0 || len(results) == 0 {
+		return results, nil
+	}
+
+	windowResults, err := a.resultsService.GetResultsForSiteSince(
+		ctx, site, results[0].Time.Add(-window),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results of period for site: %w", err)
+	}
+	if len(windowResults) > len(results) {
+		return windowResults, nil
+	}
+	return results, nil
+}
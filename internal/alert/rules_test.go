@@ -0,0 +1,53 @@
+package alert
+
+import (
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+func TestRuleMatchFirstFailed(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	result := func(minutesAgo int, successful bool) model.CheckResult {
+		return model.CheckResult{
+			Time:       now.Add(-time.Duration(minutesAgo) * time.Minute),
+			Successful: successful,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		results []model.CheckResult
+		want    time.Time
+	}{
+		{
+			name:    "single failure",
+			results: []model.CheckResult{result(0, false)},
+			want:    now,
+		},
+		{
+			name:    "trailing run of failures",
+			results: []model.CheckResult{result(0, false), result(1, false), result(2, true), result(3, false)},
+			want:    now.Add(-time.Minute),
+		},
+		{
+			name:    "all failed",
+			results: []model.CheckResult{result(0, false), result(1, false), result(2, false)},
+			want:    now.Add(-2 * time.Minute),
+		},
+		{
+			name:    "last result is successful",
+			results: []model.CheckResult{result(0, true), result(1, false)},
+			want:    now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &ruleMatch{results: tt.results}
+			if got := match.firstFailed().Time; !got.Equal(tt.want) {
+				t.Errorf("firstFailed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package broker
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// amqpConnection is the part of an AMQP connection used by RabbitMQ, so
+// that losing the connection can be simulated in tests.
+type amqpConnection interface {
+	Channel() (amqpChannel, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	IsClosed() bool
+	Close() error
+}
+
+// amqpChannel is the part of an AMQP channel used by RabbitMQ.
+type amqpChannel interface {
+	Confirm(noWait bool) error
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	ConsumeWithContext(
+		ctx context.Context,
+		queue, consumer string,
+		autoAck, exclusive, noLocal, noWait bool,
+		args amqp.Table,
+	) (<-chan amqp.Delivery, error)
+	PublishWithDeferredConfirmWithContext(
+		ctx context.Context,
+		exchange, key string,
+		mandatory, immediate bool,
+		msg amqp.Publishing,
+	) (confirmation, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	IsClosed() bool
+	Close() error
+}
+
+// confirmation waits until the broker confirms a published message.
+type confirmation interface {
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+// dialAMQP connects to RabbitMQ at url.
+func dialAMQP(url string) (amqpConnection, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return rabbitConnection{conn}, nil
+}
+
+// rabbitConnection adapts *amqp.Connection to amqpConnection.
+type rabbitConnection struct {
+	*amqp.Connection
+}
+
+func (c rabbitConnection) Channel() (amqpChannel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return rabbitChannel{ch}, nil
+}
+
+// rabbitChannel adapts *amqp.Channel to amqpChannel.
+type rabbitChannel struct {
+	*amqp.Channel
+}
+
+func (c rabbitChannel) PublishWithDeferredConfirmWithContext(
+	ctx context.Context,
+	exchange, key string,
+	mandatory, immediate bool,
+	msg amqp.Publishing,
+) (confirmation, error) {
+	confirmation, err := c.Channel.PublishWithDeferredConfirmWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	if err != nil {
+		return nil, err
+	}
+	return confirmation, nil
+}
@@ -2,17 +2,81 @@ package broker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"shm/internal/model"
 )
 
+var (
+	// ErrNacked is returned when the broker refused a published message.
+	ErrNacked = errors.New("message was nacked by broker")
+	// ErrPublishTimeout is returned when the broker did not confirm a
+	// published message in time.
+	ErrPublishTimeout = errors.New("message was not confirmed in time")
+)
+
+// PublishError is returned when a published message was not confirmed by
+// the broker, so it may be lost and should be published again.
+type PublishError struct {
+	Queue string
+	Err   error
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("failed to publish message to %s queue: %v", e.Queue, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
 type MessageBroker interface {
-	ConsumeSites(ctx context.Context) (<-chan model.Site, error)
-	ConsumeResults(ctx context.Context) (<-chan model.CheckResult, error)
-	ConsumeNotifications(ctx context.Context) (<-chan model.Notification, error)
+	// ConsumeSites, ConsumeResults and ConsumeNotifications deliver at most
+	// prefetch messages which are not settled yet.
+	ConsumeSites(ctx context.Context, prefetch int) (<-chan Message[model.Site], error)
+	ConsumeResults(ctx context.Context, prefetch int) (<-chan Message[model.CheckResult], error)
+	ConsumeNotifications(
+		ctx context.Context,
+		prefetch int,
+	) (<-chan Message[model.Notification], error)
 
+	// PublishSite, PublishResult and PublishNotification return after the
+	// broker confirmed the message, or PublishError if it did not.
 	PublishSite(ctx context.Context, site model.Site) error
 	PublishResult(ctx context.Context, result model.CheckResult) error
 	PublishNotification(ctx context.Context, notification model.Notification) error
 
 	Close()
 }
+
+// acknowledger settles a consumed message in the broker.
+type acknowledger interface {
+	ack() error
+	nack(requeue bool) error
+}
+
+// Message is a consumed object. The handler settles every message once: Ack
+// when it was handled, Retry when it should be consumed again, or Reject
+// when it can never be handled. Messages which are not settled before the
+// consumer stops are consumed again.
+type Message[T any] struct {
+	Body         T
+	acknowledger acknowledger
+}
+
+// Ack fails if the connection was lost after the message was consumed, the
+// message is consumed again in this case.
+func (m Message[T]) Ack() error {
+	return m.acknowledger.ack()
+}
+
+// Retry returns the message to its queue, so it is redelivered at once. A
+// message which is retried too many times is moved to the dead-letter queue.
+func (m Message[T]) Retry() error {
+	return m.acknowledger.nack(true)
+}
+
+// Reject moves the message to the dead-letter queue.
+func (m Message[T]) Reject() error {
+	return m.acknowledger.nack(false)
+}
@@ -0,0 +1,86 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// settlement records how a delivery was settled in RabbitMQ.
+type settlement struct {
+	acked    bool
+	nacked   bool
+	requeued bool
+}
+
+func (s *settlement) Ack(tag uint64, multiple bool) error {
+	s.acked = true
+	return nil
+}
+
+func (s *settlement) Nack(tag uint64, multiple, requeue bool) error {
+	s.nacked = true
+	s.requeued = requeue
+	return nil
+}
+
+func (s *settlement) Reject(tag uint64, requeue bool) error {
+	return s.Nack(tag, false, requeue)
+}
+
+func TestMessageSettlement(t *testing.T) {
+	tests := []struct {
+		name   string
+		settle func(Message[string]) error
+		want   settlement
+	}{
+		{name: "ack", settle: Message[string].Ack, want: settlement{acked: true}},
+		{name: "retry", settle: Message[string].Retry, want: settlement{nacked: true, requeued: true}},
+		// rejected messages are not requeued, so they are dead-lettered
+		{name: "reject", settle: Message[string].Reject, want: settlement{nacked: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &settlement{}
+			msg := Message[string]{
+				Body:         "body",
+				acknowledger: delivery{amqp.Delivery{Acknowledger: got}},
+			}
+			if err := tt.settle(msg); err != nil {
+				t.Fatalf("settle error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("settled %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "nacked", err: ErrNacked, wantErr: ErrNacked},
+		{name: "timeout", err: fmt.Errorf("wait: %w", context.DeadlineExceeded), wantErr: ErrPublishTimeout},
+		{name: "closed", err: ErrClosed, wantErr: ErrClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := publishError("sites", tt.err)
+			var publishErr *PublishError
+			if !errors.As(err, &publishErr) || publishErr.Queue != "sites" {
+				t.Fatalf("publishError() = %v, want PublishError of sites queue", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("publishError() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,116 +3,372 @@ package broker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"shm/internal/lib/sl"
 	"shm/internal/model"
 	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 var _ MessageBroker = &RabbitMQ{}
 
+// ErrClosed is returned by publishes and consumers after Close.
+var ErrClosed = errors.New("broker is closed")
+
+// Queues are quorum queues. They are named apart from the classic queues of
+// earlier versions, since a queue can not be declared again with another
+// type, and those can be deleted once they are drained.
+const (
+	sitesQueue         = "shm.sites"
+	resultsQueue       = "shm.results"
+	notificationsQueue = "shm.notifications"
+
+	// deadLetterExchange routes rejected messages of every queue to the
+	// queue with ".dead" suffix.
+	deadLetterExchange = "shm.dead-letter"
+	// deliveryLimit is the number of times a message is returned to its
+	// queue before it is dead-lettered, so that a message which fails its
+	// handler every time does not loop forever.
+	deliveryLimit = 10
+
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// RabbitMQ reconnects when the connection is lost. Consumers are registered
+// again on the new connection without closing their channels, and
+// publishes wait until the broker is connected.
 type RabbitMQ struct {
-	wg             sync.WaitGroup
-	conn           *amqp.Connection
-	ch             *amqp.Channel
-	sitesQ         amqp.Queue
-	resultQ        amqp.Queue
-	notificationsQ amqp.Queue
-	closed         chan struct{}
+	dial func() (amqpConnection, error)
+	// timeout limits waiting for the connection and confirmation of
+	// published messages
+	timeout time.Duration
+	wg      sync.WaitGroup
+	closed  chan struct{}
+
+	mu   sync.Mutex
+	conn amqpConnection
+	// ch is the channel for publishing
+	ch amqpChannel
+	// connected is closed once the broker is connected, it is replaced
+	// when the connection is lost
+	connected chan struct{}
+}
+
+func NewRabbitMQ(url string, timeout time.Duration) (*RabbitMQ, error) {
+	return newRabbitMQ(func() (amqpConnection, error) { return dialAMQP(url) }, timeout)
 }
 
-func NewRabbitMQ(url string) (*RabbitMQ, error) {
-	conn, err := amqp.Dial(url)
+func newRabbitMQ(dial func() (amqpConnection, error), timeout time.Duration) (*RabbitMQ, error) {
+	r := &RabbitMQ{
+		dial:      dial,
+		timeout:   timeout,
+		closed:    make(chan struct{}),
+		connected: make(chan struct{}),
+	}
+
+	conn, ch, err := r.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		return nil, err
+	}
+
+	r.wg.Add(1)
+	go r.reconnectRoutine(conn, ch)
+
+	return r, nil
+}
+
+// connect dials RabbitMQ, declares the topology and makes the new
+// connection current.
+func (r *RabbitMQ) connect() (amqpConnection, amqpChannel, error) {
+	conn, err := r.dial()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
 	ch, err := conn.Channel()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create channel: %w", err)
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to create channel: %w", err)
 	}
 
-	err = ch.Qos(
-		1,     // prefetch count
-		0,     // prefetch size
-		false, // global
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to put channel into confirm mode: %w", err)
+	}
+
+	if err := declareTopology(ch); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conn = conn
+	r.ch = ch
+	close(r.connected)
+	return conn, ch, nil
+}
+
+// disconnected forgets the lost connection, so that publishes and consumers
+// wait for the next one.
+func (r *RabbitMQ) disconnected(conn amqpConnection) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn != conn {
+		return
+	}
+	r.conn = nil
+	r.ch = nil
+	r.connected = make(chan struct{})
+}
+
+// connection returns the current connection and publishing channel, waiting
+// until the broker is connected.
+func (r *RabbitMQ) connection(ctx context.Context) (amqpConnection, amqpChannel, error) {
+	for {
+		r.mu.Lock()
+		conn, ch, connected := r.conn, r.ch, r.connected
+		r.mu.Unlock()
+
+		if conn != nil && !conn.IsClosed() {
+			return conn, ch, nil
+		}
+		if conn != nil {
+			r.disconnected(conn)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-r.closed:
+			return nil, nil, ErrClosed
+		case <-connected:
+		}
+	}
+}
+
+// reconnectRoutine waits until the connection or its publishing channel is
+// closed and connects again, waiting exponentially longer between attempts.
+func (r *RabbitMQ) reconnectRoutine(conn amqpConnection, ch amqpChannel) {
+	defer r.wg.Done()
+
+	for {
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		var reason *amqp.Error
+		select {
+		case <-r.closed:
+			// the connection may be opened after Close took the previous one
+			conn.Close()
+			return
+		case reason = <-connClosed:
+		case reason = <-chClosed:
+			// a channel is closed by an error, the connection is
+			// opened again to recover it together with consumers
+			conn.Close()
+		}
+		select {
+		case <-r.closed:
+			return
+		default:
+		}
+		slog.Error("connection to RabbitMQ was lost", slog.Any("reason", reason))
+		r.disconnected(conn)
+
+		delay := minReconnectDelay
+		for {
+			select {
+			case <-r.closed:
+				return
+			case <-time.After(delay):
+			}
+
+			var err error
+			if conn, ch, err = r.connect(); err == nil {
+				slog.Info("reconnected to RabbitMQ")
+				break
+			}
+			slog.Error("failed to reconnect to RabbitMQ", slog.Duration("delay", delay), sl.Error(err))
+			delay = min(2*delay, maxReconnectDelay)
+		}
+	}
+}
+
+// declareTopology declares the dead-letter exchange and queues with their
+// dead-letter queues.
+func declareTopology(ch amqpChannel) error {
+	err := ch.ExchangeDeclare(
+		deadLetterExchange, // name
+		"direct",           // kind
+		true,               // durable
+		false,              // delete when unused
+		false,              // internal
+		false,              // no-wait
+		nil,                // arguments
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set QoS: %w", err)
+		return fmt.Errorf("failed to declare a dead-letter exchange: %w", err)
 	}
 
-	sitesQ, err := declareQueue(ch, "sites")
-	if err != nil {
-		return nil, fmt.Errorf("failed to declare a sites queue: %w", err)
+	for _, queue := range []string{sitesQueue, resultsQueue, notificationsQueue} {
+		if err := declareQueue(ch, queue); err != nil {
+			return fmt.Errorf("failed to declare a %s queue: %w", queue, err)
+		}
 	}
+	return nil
+}
 
-	resultsQ, err := declareQueue(ch, "results")
+func declareQueue(ch amqpChannel, name string) error {
+	deadLetterQueue := name + ".dead"
+	_, err := ch.QueueDeclare(
+		deadLetterQueue, // name
+		true,            // durable
+		false,           // delete when unused
+		false,           // exclusive
+		false,           // no-wait
+		nil,             // arguments
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare a results queue: %w", err)
+		return err
 	}
 
-	notificationsQ, err := declareQueue(ch, "notifications")
+	err = ch.QueueBind(
+		deadLetterQueue,    // name
+		deadLetterQueue,    // key
+		deadLetterExchange, // exchange
+		false,              // no-wait
+		nil,                // arguments
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare a notifications queue: %w", err)
+		return err
 	}
 
-	return &RabbitMQ{
-		conn:           conn,
-		ch:             ch,
-		sitesQ:         sitesQ,
-		resultQ:        resultsQ,
-		notificationsQ: notificationsQ,
-		closed:         make(chan struct{}),
-	}, nil
-}
-
-func declareQueue(ch *amqp.Channel, name string) (amqp.Queue, error) {
-	return ch.QueueDeclare(
+	_, err = ch.QueueDeclare(
 		name,  // name
-		false, // durable
+		true,  // durable
 		false, // delete when unused
 		false, // exclusive
 		false, // no-wait
-		nil,   // arguments
+		amqp.Table{
+			amqp.QueueTypeArg:           amqp.QueueTypeQuorum,
+			"x-delivery-limit":          deliveryLimit,
+			"x-dead-letter-exchange":    deadLetterExchange,
+			"x-dead-letter-routing-key": deadLetterQueue,
+		},
 	)
+	return err
+}
+
+func (r *RabbitMQ) ConsumeSites(
+	ctx context.Context,
+	prefetch int,
+) (<-chan Message[model.Site], error) {
+	return consumeRoutine[model.Site](r, ctx, sitesQueue, prefetch)
 }
 
-func (r *RabbitMQ) ConsumeSites(ctx context.Context) (<-chan model.Site, error) {
-	return consumeRoutine[model.Site](r, ctx, r.sitesQ.Name)
+func (r *RabbitMQ) ConsumeResults(
+	ctx context.Context,
+	prefetch int,
+) (<-chan Message[model.CheckResult], error) {
+	return consumeRoutine[model.CheckResult](r, ctx, resultsQueue, prefetch)
+}
+
+func (r *RabbitMQ) ConsumeNotifications(
+	ctx context.Context,
+	prefetch int,
+) (<-chan Message[model.Notification], error) {
+	return consumeRoutine[model.Notification](r, ctx, notificationsQueue, prefetch)
 }
 
-func (r *RabbitMQ) ConsumeResults(ctx context.Context) (<-chan model.CheckResult, error) {
-	return consumeRoutine[model.CheckResult](r, ctx, r.resultQ.Name)
+// delivery settles a message consumed from RabbitMQ.
+type delivery struct {
+	amqp.Delivery
 }
 
-func (r *RabbitMQ) ConsumeNotifications(ctx context.Context) (<-chan model.Notification, error) {
-	return consumeRoutine[model.Notification](r, ctx, r.notificationsQ.Name)
+func (d delivery) ack() error {
+	return d.Ack(false)
 }
 
-func consumeRoutine[T any](r *RabbitMQ, ctx context.Context, queue string) (<-chan T, error) {
-	msgs, err := r.consumeMessages(ctx, queue)
+func (d delivery) nack(requeue bool) error {
+	return d.Nack(false, requeue)
+}
+
+// consumeRoutine parses consumed messages. Messages which can not be parsed
+// are moved to the dead-letter queue. The consumer is registered again when
+// its channel is closed, so the returned channel is closed only when ctx is
+// done or the broker is closed.
+func consumeRoutine[T any](
+	r *RabbitMQ,
+	ctx context.Context,
+	queue string,
+	prefetch int,
+) (<-chan Message[T], error) {
+	ch, msgs, err := r.consumeMessages(ctx, queue, prefetch)
 	if err != nil {
 		return nil, err
 	}
 
-	objects := make(chan T)
+	objects := make(chan Message[T])
 	r.wg.Add(1)
 	go func() {
 		defer r.wg.Done()
 		defer close(objects)
-		for msg := range msgs {
-			var object T
-			if err := json.Unmarshal(msg.Body, &object); err != nil {
-				slog.Error("failed to parse message body", sl.Error(err))
-				continue
+		for {
+			for msg := range msgs {
+				var object T
+				if err := json.Unmarshal(msg.Body, &object); err != nil {
+					slog.Error(
+						"failed to parse message body, message was dead-lettered",
+						slog.String("queue", queue),
+						sl.Error(err),
+					)
+					if err := msg.Nack(false, false); err != nil {
+						slog.Error("failed to reject message", slog.String("queue", queue), sl.Error(err))
+					}
+					continue
+				}
+				select {
+				case <-r.closed:
+					return
+				case objects <- Message[T]{Body: object, acknowledger: delivery{msg}}:
+				}
 			}
+			ch.Close()
+
 			select {
+			case <-ctx.Done():
+				return
 			case <-r.closed:
 				return
-			case objects <- object:
+			case <-time.After(minReconnectDelay):
+			}
+
+			for {
+				ch, msgs, err = r.consumeMessages(ctx, queue, prefetch)
+				if err == nil {
+					slog.Info("consumer was registered again", slog.String("queue", queue))
+					break
+				}
+				if ctx.Err() != nil || errors.Is(err, ErrClosed) {
+					return
+				}
+				slog.Error("failed to register consumer again", slog.String("queue", queue), sl.Error(err))
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-r.closed:
+					return
+				case <-time.After(minReconnectDelay):
+				}
 			}
 		}
 	}()
@@ -120,65 +376,138 @@ func consumeRoutine[T any](r *RabbitMQ, ctx context.Context, queue string) (<-ch
 	return objects, nil
 }
 
+// consumeMessages registers a consumer with manual acknowledgements on its
+// own channel, so that prefetch applies only to it.
 func (r *RabbitMQ) consumeMessages(
 	ctx context.Context,
 	queue string,
-) (<-chan amqp.Delivery, error) {
-	return r.ch.ConsumeWithContext(
+	prefetch int,
+) (amqpChannel, <-chan amqp.Delivery, error) {
+	conn, _, err := r.connection(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	err = ch.Qos(
+		prefetch, // prefetch count
+		0,        // prefetch size
+		false,    // global
+	)
+	if err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	msgs, err := ch.ConsumeWithContext(
 		ctx,
 		queue, // queue
 		"",    // consumer
-		true,  // auto-ack
+		false, // auto-ack
 		false, // exclusive
 		false, // no-local
 		false, // no-wait
 		nil,   // args
 	)
+	if err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+
+	return ch, msgs, nil
 }
 
-func (r *RabbitMQ) PublishSite(_ context.Context, site model.Site) error {
+func (r *RabbitMQ) PublishSite(ctx context.Context, site model.Site) error {
 	body, err := json.Marshal(site)
 	if err != nil {
 		return fmt.Errorf("failed to marshal site: %w", err)
 	}
-
-	msg := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        body,
-	}
-	return r.ch.Publish("", r.sitesQ.Name, false, false, msg)
+	return r.publish(ctx, sitesQueue, body)
 }
 
-func (r *RabbitMQ) PublishResult(_ context.Context, result model.CheckResult) error {
+func (r *RabbitMQ) PublishResult(ctx context.Context, result model.CheckResult) error {
 	body, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal result: %w", err)
 	}
-
-	msg := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        body,
-	}
-	return r.ch.Publish("", r.resultQ.Name, false, false, msg)
+	return r.publish(ctx, resultsQueue, body)
 }
 
-func (r *RabbitMQ) PublishNotification(_ context.Context, notification model.Notification) error {
+func (r *RabbitMQ) PublishNotification(ctx context.Context, notification model.Notification) error {
 	body, err := json.Marshal(notification)
 	if err != nil {
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
+	return r.publish(ctx, notificationsQueue, body)
+}
+
+// publish sends a persistent message, so that it survives restart of
+// RabbitMQ, and waits until the broker confirms it. While the broker is
+// disconnected publish waits for the connection. Waiting is limited by ctx
+// and the broker timeout.
+func (r *RabbitMQ) publish(ctx context.Context, queue string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
 
 	msg := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        body,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	}
+
+	for {
+		conn, ch, err := r.connection(ctx)
+		if err != nil {
+			return publishError(queue, err)
+		}
+
+		confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, msg)
+		if errors.Is(err, amqp.ErrClosed) {
+			// the connection was lost after it was taken
+			r.disconnected(conn)
+			continue
+		}
+		if err != nil {
+			return publishError(queue, err)
+		}
+
+		acked, err := confirmation.WaitContext(ctx)
+		if err != nil {
+			return publishError(queue, err)
+		}
+		if acked {
+			return nil
+		}
+		if ch.IsClosed() {
+			// unconfirmed messages are nacked when the channel is closed,
+			// the message is published again on the next connection
+			r.disconnected(conn)
+			continue
+		}
+		return publishError(queue, ErrNacked)
 	}
-	return r.ch.Publish("", r.notificationsQ.Name, false, false, msg)
 }
 
-func (r *RabbitMQ) Close() {
-	defer r.conn.Close()
-	defer r.ch.Close()
+func publishError(queue string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrPublishTimeout, err)
+	}
+	return &PublishError{Queue: queue, Err: err}
+}
 
+func (r *RabbitMQ) Close() {
 	close(r.closed)
+
+	r.mu.Lock()
+	conn := r.conn
+	r.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+
 	r.wg.Wait()
 }
@@ -0,0 +1,399 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"shm/internal/model"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeConnection is an AMQP connection which is lost when the test closes
+// it. Published messages are confirmed at once if ack is set, otherwise
+// they wait for confirmation until the connection is lost.
+type fakeConnection struct {
+	ack bool
+
+	mu       sync.Mutex
+	closed   bool
+	notify   []chan *amqp.Error
+	channels []*fakeChannel
+}
+
+func (c *fakeConnection) Channel() (amqpChannel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, amqp.ErrClosed
+	}
+	ch := &fakeChannel{conn: c, consumers: map[string]chan amqp.Delivery{}}
+	c.channels = append(c.channels, ch)
+	return ch, nil
+}
+
+func (c *fakeConnection) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		close(receiver)
+	} else {
+		c.notify = append(c.notify, receiver)
+	}
+	return receiver
+}
+
+func (c *fakeConnection) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *fakeConnection) Close() error {
+	c.lose(nil)
+	return nil
+}
+
+// lose closes the connection with its channels and consumers, like
+// RabbitMQ does when the connection is lost.
+func (c *fakeConnection) lose(reason *amqp.Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	for _, ch := range c.channels {
+		ch.Close()
+	}
+	for _, receiver := range c.notify {
+		if reason != nil {
+			receiver <- reason
+		}
+		close(receiver)
+	}
+}
+
+// consumer returns the deliveries of the consumer of queue, or nil if there
+// is none.
+func (c *fakeConnection) consumer(queue string) chan amqp.Delivery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ch := range c.channels {
+		ch.mu.Lock()
+		deliveries := ch.consumers[queue]
+		ch.mu.Unlock()
+		if deliveries != nil {
+			return deliveries
+		}
+	}
+	return nil
+}
+
+// published returns the number of messages published to queue.
+func (c *fakeConnection) published(queue string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, ch := range c.channels {
+		ch.mu.Lock()
+		for _, q := range ch.published {
+			if q == queue {
+				n++
+			}
+		}
+		ch.mu.Unlock()
+	}
+	return n
+}
+
+type fakeChannel struct {
+	conn *fakeConnection
+
+	mu        sync.Mutex
+	closed    bool
+	notify    []chan *amqp.Error
+	consumers map[string]chan amqp.Delivery
+	published []string
+	pending   []*fakeConfirmation
+}
+
+func (ch *fakeChannel) Confirm(noWait bool) error {
+	return nil
+}
+
+func (ch *fakeChannel) ExchangeDeclare(
+	name, kind string,
+	durable, autoDelete, internal, noWait bool,
+	args amqp.Table,
+) error {
+	return nil
+}
+
+func (ch *fakeChannel) QueueDeclare(
+	name string,
+	durable, autoDelete, exclusive, noWait bool,
+	args amqp.Table,
+) (amqp.Queue, error) {
+	return amqp.Queue{Name: name}, nil
+}
+
+func (ch *fakeChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	return nil
+}
+
+func (ch *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	return nil
+}
+
+func (ch *fakeChannel) ConsumeWithContext(
+	ctx context.Context,
+	queue, consumer string,
+	autoAck, exclusive, noLocal, noWait bool,
+	args amqp.Table,
+) (<-chan amqp.Delivery, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.closed {
+		return nil, amqp.ErrClosed
+	}
+	deliveries := make(chan amqp.Delivery)
+	ch.consumers[queue] = deliveries
+	return deliveries, nil
+}
+
+func (ch *fakeChannel) PublishWithDeferredConfirmWithContext(
+	ctx context.Context,
+	exchange, key string,
+	mandatory, immediate bool,
+	msg amqp.Publishing,
+) (confirmation, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.closed {
+		return nil, amqp.ErrClosed
+	}
+	ch.published = append(ch.published, key)
+	c := &fakeConfirmation{done: make(chan struct{})}
+	if ch.conn.ack {
+		c.acked = true
+		close(c.done)
+	} else {
+		ch.pending = append(ch.pending, c)
+	}
+	return c, nil
+}
+
+func (ch *fakeChannel) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.closed {
+		close(receiver)
+	} else {
+		ch.notify = append(ch.notify, receiver)
+	}
+	return receiver
+}
+
+func (ch *fakeChannel) IsClosed() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.closed
+}
+
+// Close nacks unconfirmed messages and stops consumers.
+func (ch *fakeChannel) Close() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.closed {
+		return nil
+	}
+	ch.closed = true
+	for _, c := range ch.pending {
+		close(c.done)
+	}
+	for _, deliveries := range ch.consumers {
+		close(deliveries)
+	}
+	for _, receiver := range ch.notify {
+		close(receiver)
+	}
+	return nil
+}
+
+type fakeConfirmation struct {
+	done  chan struct{}
+	acked bool
+}
+
+func (c *fakeConfirmation) WaitContext(ctx context.Context) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-c.done:
+		return c.acked, nil
+	}
+}
+
+// newFakeRabbitMQ returns a broker which connects to conns in order, and
+// fails to connect when there are no more.
+func newFakeRabbitMQ(t *testing.T, timeout time.Duration, conns ...*fakeConnection) *RabbitMQ {
+	t.Helper()
+	dials := make(chan *fakeConnection, len(conns))
+	for _, conn := range conns {
+		dials <- conn
+	}
+	dial := func() (amqpConnection, error) {
+		select {
+		case conn := <-dials:
+			return conn, nil
+		default:
+			return nil, errors.New("connection refused")
+		}
+	}
+
+	r, err := newRabbitMQ(dial, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+// waitFor waits until cond is true, long enough for the broker to connect
+// again.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * minReconnectDelay)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConsumerIsRegisteredAgain(t *testing.T) {
+	first, second := &fakeConnection{}, &fakeConnection{}
+	r := newFakeRabbitMQ(t, time.Second, first, second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sites, err := r.ConsumeSites(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliver := func(conn *fakeConnection, id int64) {
+		t.Helper()
+		var deliveries chan amqp.Delivery
+		waitFor(t, "consumer", func() bool {
+			deliveries = conn.consumer(sitesQueue)
+			return deliveries != nil
+		})
+		body, _ := json.Marshal(model.Site{Id: id})
+		deliveries <- amqp.Delivery{Body: body, Acknowledger: &settlement{}}
+
+		select {
+		case msg, ok := <-sites:
+			if !ok {
+				t.Fatal("sites channel was closed")
+			}
+			if msg.Body.Id != id {
+				t.Errorf("consumed site %d, want %d", msg.Body.Id, id)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("site was not consumed")
+		}
+	}
+
+	deliver(first, 1)
+	first.lose(&amqp.Error{Code: amqp.ConnectionForced, Reason: "shutdown"})
+	deliver(second, 2)
+}
+
+func TestPublishWhenConnectionIsLost(t *testing.T) {
+	tests := []struct {
+		name string
+		// next is the connection after the lost one, if any
+		next    *fakeConnection
+		timeout time.Duration
+		wantErr error
+	}{
+		{
+			name:    "published again on next connection",
+			next:    &fakeConnection{ack: true},
+			timeout: 5 * time.Second,
+		},
+		{
+			name:    "not connected again",
+			timeout: 200 * time.Millisecond,
+			wantErr: ErrPublishTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &fakeConnection{}
+			conns := []*fakeConnection{first}
+			if tt.next != nil {
+				conns = append(conns, tt.next)
+			}
+			r := newFakeRabbitMQ(t, tt.timeout, conns...)
+
+			published := make(chan error, 1)
+			go func() {
+				published <- r.PublishSite(context.Background(), model.Site{Id: 1})
+			}()
+			waitFor(t, "publish", func() bool { return first.published(sitesQueue) == 1 })
+			first.lose(&amqp.Error{Code: amqp.ConnectionForced, Reason: "shutdown"})
+
+			var err error
+			select {
+			case err = <-published:
+			case <-time.After(tt.timeout + time.Second):
+				t.Fatal("publish did not return")
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("PublishSite() error = %v", err)
+				}
+				if n := tt.next.published(sitesQueue); n != 1 {
+					t.Errorf("published %d messages on next connection, want 1", n)
+				}
+				return
+			}
+			var publishErr *PublishError
+			if !errors.As(err, &publishErr) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("PublishSite() error = %v, want PublishError with %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublishAfterClose(t *testing.T) {
+	dial := func() (amqpConnection, error) { return &fakeConnection{ack: true}, nil }
+	r, err := newRabbitMQ(dial, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	err = r.PublishResult(context.Background(), model.CheckResult{})
+	var publishErr *PublishError
+	if !errors.As(err, &publishErr) || !errors.Is(err, ErrClosed) {
+		t.Errorf("PublishResult() error = %v, want PublishError with %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,80 @@
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"shm/internal/lib/jsonpath"
+	"shm/internal/model"
+)
+
+// checkAssertions returns the reason of the first failed assertion or empty
+// string if all assertions passed.
+func checkAssertions(body []byte, assertions model.Assertions) string {
+	var doc any
+	var docErr error
+	docParsed := false
+
+	for _, assertion := range assertions {
+		switch assertion.Type {
+		case model.AssertionContains:
+			if !bytes.Contains(body, []byte(assertion.Value)) {
+				return fmt.Sprintf("keyword '%s' missing", assertion.Value)
+			}
+		case model.AssertionNotContains:
+			if bytes.Contains(body, []byte(assertion.Value)) {
+				return fmt.Sprintf("keyword '%s' found", assertion.Value)
+			}
+		case model.AssertionRegex:
+			re, err := regexp.Compile(assertion.Value)
+			if err != nil {
+				return fmt.Sprintf("invalid regex '%s'", assertion.Value)
+			}
+			if !re.Match(body) {
+				return fmt.Sprintf("body does not match regex '%s'", assertion.Value)
+			}
+		case model.AssertionJSONPath:
+			if !docParsed {
+				docErr = json.Unmarshal(body, &doc)
+				docParsed = true
+			}
+			if docErr != nil {
+				return "body is not valid JSON"
+			}
+			if reason := checkJSONPath(doc, assertion); reason != "" {
+				return reason
+			}
+		default:
+			return fmt.Sprintf("unknown assertion type '%s'", assertion.Type)
+		}
+	}
+
+	return ""
+}
+
+func checkJSONPath(doc any, assertion model.Assertion) string {
+	value, err := jsonpath.Lookup(doc, assertion.Path)
+	if err != nil {
+		return fmt.Sprintf("json path '%s' not found", assertion.Path)
+	}
+
+	// Strings are compared as is, other values by their JSON representation,
+	// so "true", "42" and "null" can be expected as well.
+	actual, ok := value.(string)
+	if !ok {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("json path '%s' has unsupported value", assertion.Path)
+		}
+		actual = string(b)
+	}
+
+	if actual != assertion.Value {
+		return fmt.Sprintf(
+			"json path '%s' is '%s', expected '%s'",
+			assertion.Path, actual, assertion.Value,
+		)
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"shm/internal/model"
+	"testing"
+)
+
+func TestCheckAssertions(t *testing.T) {
+	body := []byte(`{"status":"ok","healthy":true,"count":42,"version":"1.2.3","db":{"latency":null},"nodes":[{"id":"a"},{"id":"b"}]}`)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		assertions model.Assertions
+		want       string
+	}{
+		{name: "no assertions", body: body},
+		{
+			name:       "contains",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionContains, Value: `"healthy":true`}},
+		},
+		{
+			name:       "contains missing",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionContains, Value: "degraded"}},
+			want:       "keyword 'degraded' missing",
+		},
+		{
+			name:       "not contains",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionNotContains, Value: "error"}},
+		},
+		{
+			name:       "not contains found",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionNotContains, Value: "ok"}},
+			want:       "keyword 'ok' found",
+		},
+		{
+			name:       "regex",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionRegex, Value: `"version":"\d+\.\d+\.\d+"`}},
+		},
+		{
+			name:       "regex mismatch",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionRegex, Value: `"version":"2\.`}},
+			want:       `body does not match regex '"version":"2\.'`,
+		},
+		{
+			name:       "invalid regex",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionRegex, Value: "("}},
+			want:       "invalid regex '('",
+		},
+		{
+			name:       "json path string",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.status", Value: "ok"}},
+		},
+		{
+			name:       "json path bool",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.healthy", Value: "true"}},
+		},
+		{
+			name:       "json path number",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.count", Value: "42"}},
+		},
+		{
+			name:       "json path null",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.db.latency", Value: "null"}},
+		},
+		{
+			name:       "json path array element",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.nodes[1].id", Value: "b"}},
+		},
+		{
+			name:       "json path object",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.nodes[0]", Value: `{"id":"a"}`}},
+		},
+		{
+			name:       "json path string is not quoted",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.status", Value: `"ok"`}},
+			want:       `json path '$.status' is 'ok', expected '"ok"'`,
+		},
+		{
+			name:       "json path mismatch",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.count", Value: "41"}},
+			want:       "json path '$.count' is '42', expected '41'",
+		},
+		{
+			name:       "json path not found",
+			body:       body,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.nodes[2].id", Value: "c"}},
+			want:       "json path '$.nodes[2].id' not found",
+		},
+		{
+			name:       "body is not json",
+			body:       []byte("<html>ok</html>"),
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$.status", Value: "ok"}},
+			want:       "body is not valid JSON",
+		},
+		{
+			name:       "empty body",
+			body:       nil,
+			assertions: model.Assertions{{Type: model.AssertionJSONPath, Path: "$", Value: "null"}},
+			want:       "body is not valid JSON",
+		},
+		{
+			name: "first failure is reported",
+			body: body,
+			assertions: model.Assertions{
+				{Type: model.AssertionContains, Value: "ok"},
+				{Type: model.AssertionJSONPath, Path: "$.status", Value: "down"},
+				{Type: model.AssertionContains, Value: "missing"},
+			},
+			want: "json path '$.status' is 'ok', expected 'down'",
+		},
+		{
+			name: "several json paths",
+			body: body,
+			assertions: model.Assertions{
+				{Type: model.AssertionJSONPath, Path: "$.status", Value: "ok"},
+				{Type: model.AssertionJSONPath, Path: "$.nodes[0].id", Value: "a"},
+			},
+		},
+		{
+			name:       "unknown type",
+			body:       body,
+			assertions: model.Assertions{{Type: "xpath", Value: "/status"}},
+			want:       "unknown assertion type 'xpath'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkAssertions(tt.body, tt.assertions); got != tt.want {
+				t.Errorf("checkAssertions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"os/signal"
 	"shm/internal/broker"
@@ -14,6 +16,7 @@ import (
 	"shm/internal/lib/sl"
 	"shm/internal/model"
 	"shm/internal/service"
+	"strings"
 	"syscall"
 	"time"
 
@@ -21,23 +24,30 @@ import (
 )
 
 type Checker struct {
-	broker         broker.MessageBroker
-	resultsService *service.ResultsService
-	sitesService   *service.SitesService
-	config         config.CheckerConfig
+	broker              broker.MessageBroker
+	client              *http.Client
+	noRedirectsClient   *http.Client
+	resultsService      *service.ResultsService
+	sitesService        *service.SitesService
+	certificatesService *service.CertificatesService
+	config              config.CheckerConfig
 }
 
 func New(
 	broker broker.MessageBroker,
 	resultsService *service.ResultsService,
 	sitesService *service.SitesService,
+	certificatesService *service.CertificatesService,
 	config config.CheckerConfig,
 ) *Checker {
 	return &Checker{
-		broker:         broker,
-		resultsService: resultsService,
-		sitesService:   sitesService,
-		config:         config,
+		broker:              broker,
+		client:              newHTTPClient(true),
+		noRedirectsClient:   newHTTPClient(false),
+		resultsService:      resultsService,
+		sitesService:        sitesService,
+		certificatesService: certificatesService,
+		config:              config,
 	}
 }
 
@@ -46,7 +56,7 @@ func (c *Checker) Start() {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	sitesQueue, err := c.broker.ConsumeSites(ctx)
+	sitesQueue, err := c.broker.ConsumeSites(ctx, c.config.Workers)
 	if err != nil {
 		slog.Error("failed to register a consumer for sites", sl.Error(err))
 		return
@@ -65,18 +75,26 @@ func (c *Checker) Start() {
 	}
 }
 
-func (c *Checker) workerRoutine(ctx context.Context, sitesQueue <-chan model.Site) error {
+func (c *Checker) workerRoutine(
+	ctx context.Context,
+	sitesQueue <-chan broker.Message[model.Site],
+) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case site, ok := <-sitesQueue:
+		case msg, ok := <-sitesQueue:
 			if !ok {
 				return fmt.Errorf("queue with sites was closed")
 			}
-			if err := c.monitorSite(ctx, site); err != nil {
+			if err := c.monitorSite(ctx, msg.Body); err != nil {
+				// the site is checked again by this or another checker
+				msg.Retry()
 				return fmt.Errorf("failed to monitor site: %w", err)
 			}
+			if err := msg.Ack(); err != nil {
+				slog.Warn("failed to ack site, it will be consumed again", sl.Error(err))
+			}
 		}
 	}
 }
@@ -89,48 +107,155 @@ func (c *Checker) monitorSite(ctx context.Context, site model.Site) error {
 		slog.Info("successful checking of site", sl.CheckResult(result))
 	}
 
-	if err = c.resultsService.AddResult(ctx, result); err != nil {
+	// a site published again after a failure keeps its run, so the result
+	// replaces the one saved by the failed attempt
+	result.ScheduledAt = site.NextRunAt
+	if result.Id, err = c.resultsService.AddResult(ctx, result); err != nil {
 		return fmt.Errorf("failed to send check result to database: %w", err)
 	}
 
-	if err = c.broker.PublishResult(ctx, result); err != nil {
+	if result.Certificate != nil {
+		if err = c.certificatesService.SaveCertificate(ctx, *result.Certificate); err != nil {
+			return fmt.Errorf("failed to send certificate to database: %w", err)
+		}
+	}
+
+	if err = c.publishResult(ctx, result); err != nil {
 		return fmt.Errorf("failed to send check result to broker: %w", err)
 	}
 
 	return nil
 }
 
+// publishResult publishes the result again while the broker does not
+// confirm it, since the result is already saved and the site is not checked
+// again.
+func (c *Checker) publishResult(ctx context.Context, result model.CheckResult) error {
+	delay := time.Second
+	for {
+		err := c.broker.PublishResult(ctx, result)
+		var publishErr *broker.PublishError
+		if !errors.As(err, &publishErr) || ctx.Err() != nil {
+			return err
+		}
+		slog.Warn(
+			"check result was not confirmed by broker",
+			sl.CheckResult(result),
+			slog.Duration("delay", delay),
+			sl.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, 30*time.Second)
+	}
+}
+
 func (c *Checker) checkSite(
 	ctx context.Context,
 	site model.Site,
-) (result model.CheckResult, err error) {
+) (model.CheckResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.config.SiteResponseTimeoutSec)
 	defer cancel()
 
+	switch site.Type {
+	case model.CheckTypeTCP:
+		return c.checkTCP(ctx, site)
+	case model.CheckTypeDNS:
+		return c.checkDNS(ctx, site)
+	case model.CheckTypeHeartbeat:
+		return c.checkHeartbeat(ctx, site)
+	default:
+		return c.checkHTTP(ctx, site)
+	}
+}
+
+func (c *Checker) checkHTTP(
+	ctx context.Context,
+	site model.Site,
+) (result model.CheckResult, err error) {
 	start := time.Now()
-	req, _ := http.NewRequestWithContext(ctx, "GET", site.Url, nil)
-	resp, err := http.DefaultClient.Do(req)
+	result = model.CheckResult{
+		Site: site,
+		Time: start,
+	}
+
+	trace := newTracer(start)
+	ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+
+	req, err := newRequest(ctx, site)
+	if err != nil {
+		result.FailureReason = err.Error()
+		return result, err
+	}
+
+	client := c.client
+	if site.HTTP.NoRedirects {
+		client = c.noRedirectsClient
+	}
+	resp, err := client.Do(req)
 	latency := time.Since(start).Milliseconds()
 	if err != nil {
-		return model.CheckResult{
-			Site:    site,
-			Time:    start,
-			Latency: sql.NullInt64{},
-			Code:    sql.NullInt64{},
-		}, err
+		result.Timings = trace.finish()
+		result.FailureReason = fmt.Sprintf("request failed: %v", err)
+		return result, err
 	}
 	defer resp.Body.Close()
 
-	return model.CheckResult{
-		Site: site,
-		Time: start,
-		Latency: sql.NullInt64{
-			Int64: latency,
-			Valid: true,
-		},
-		Code: sql.NullInt64{
-			Int64: int64(resp.StatusCode),
-			Valid: true,
-		},
-	}, nil
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.config.MaxBodyBytes))
+	result.Timings = trace.finish()
+	if err != nil {
+		result.FailureReason = fmt.Sprintf("failed to read body: %v", err)
+		return result, err
+	}
+
+	result.Latency = sql.NullInt64{
+		Int64: latency,
+		Valid: true,
+	}
+	result.Code = sql.NullInt64{
+		Int64: int64(resp.StatusCode),
+		Valid: true,
+	}
+
+	result.Certificate = inspectCertificate(site.Id, resp, start)
+	if reason := certificateFailure(result.Certificate); reason != "" {
+		result.FailureReason = reason
+		return result, nil
+	}
+
+	if !site.HTTP.AcceptedCodes.Contains(result.Code.Int64) {
+		result.FailureReason = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+		return result, nil
+	}
+
+	result.FailureReason = checkAssertions(body, site.HTTP.Assertions)
+	result.AssertionFailed = result.FailureReason != ""
+	result.Successful = result.FailureReason == ""
+	return result, nil
+}
+
+func newRequest(ctx context.Context, site model.Site) (*http.Request, error) {
+	var body io.Reader
+	if site.HTTP.Body != "" {
+		body = strings.NewReader(site.HTTP.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, site.HTTP.Method, site.Url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, value := range site.HTTP.Headers {
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
 }
@@ -0,0 +1,76 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shm/internal/config"
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTPRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := &Checker{
+		client:            newHTTPClient(true),
+		noRedirectsClient: newHTTPClient(false),
+		config:            config.CheckerConfig{MaxBodyBytes: 1024},
+	}
+
+	tests := []struct {
+		name        string
+		spec        model.HTTPSpec
+		wantCode    int64
+		wantSuccess bool
+	}{
+		{
+			name:        "redirect is followed by default",
+			spec:        model.HTTPSpec{},
+			wantCode:    http.StatusOK,
+			wantSuccess: true,
+		},
+		{
+			name:        "redirect is checked itself",
+			spec:        model.HTTPSpec{NoRedirects: true},
+			wantCode:    http.StatusMovedPermanently,
+			wantSuccess: false,
+		},
+		{
+			name:        "redirect is accepted",
+			spec:        model.HTTPSpec{NoRedirects: true, AcceptedCodes: "200-399"},
+			wantCode:    http.StatusMovedPermanently,
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := model.Site{Url: server.URL + "/old", HTTP: tt.spec}
+			if err := site.Normalize(); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			result, err := c.checkHTTP(ctx, site)
+			if err != nil {
+				t.Fatalf("checkHTTP() error = %v", err)
+			}
+			if result.Code.Int64 != tt.wantCode {
+				t.Errorf("code = %d, want %d", result.Code.Int64, tt.wantCode)
+			}
+			if result.Successful != tt.wantSuccess {
+				t.Errorf("successful = %v, want %v (%s)", result.Successful, tt.wantSuccess, result.FailureReason)
+			}
+		})
+	}
+}
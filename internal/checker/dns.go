@@ -0,0 +1,149 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"shm/internal/model"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (c *Checker) checkDNS(
+	ctx context.Context,
+	site model.Site,
+) (result model.CheckResult, err error) {
+	start := time.Now()
+	result = model.CheckResult{
+		Site: site,
+		Time: start,
+	}
+
+	name, err := site.DNSName()
+	if err != nil {
+		result.FailureReason = err.Error()
+		return result, err
+	}
+
+	answers, err := lookup(ctx, newResolver(site.DNS.Resolver), site.DNS.RecordType, name)
+	result.Latency = since(start)
+	result.Timings.DNSLookup = result.Latency
+	if err != nil {
+		result.FailureReason = fmt.Sprintf("lookup failed: %v", err)
+		return result, err
+	}
+
+	result.Answers = answers
+	result.FailureReason = matchAnswers(answers, site.DNS)
+	result.Successful = result.FailureReason == ""
+	return result, nil
+}
+
+func newResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// lookup resolves name and returns sorted answers. Host names are returned in
+// lower case without trailing dot.
+func lookup(
+	ctx context.Context,
+	resolver *net.Resolver,
+	recordType string,
+	name string,
+) (model.Strings, error) {
+	var answers model.Strings
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, normalizeHost(cname))
+	case "MX":
+		records, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range records {
+			answers = append(answers, fmt.Sprintf("%d %s", mx.Pref, normalizeHost(mx.Host)))
+		}
+	case "TXT":
+		records, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, records...)
+	case "NS":
+		records, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range records {
+			answers = append(answers, normalizeHost(ns.Host))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", recordType)
+	}
+
+	slices.Sort(answers)
+	return slices.Compact(answers), nil
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// matchAnswers returns the reason why answers do not match the spec or empty
+// string if they do.
+func matchAnswers(answers model.Strings, spec model.DNSSpec) string {
+	if len(spec.Expected) == 0 {
+		return ""
+	}
+
+	var missing []string
+	for _, expected := range spec.Expected {
+		if !slices.Contains(answers, expected) {
+			missing = append(missing, expected)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("expected answers missing: %s", strings.Join(missing, ", "))
+	}
+
+	if spec.Match == model.DNSMatchEquals {
+		var unexpected []string
+		for _, answer := range answers {
+			if !slices.Contains(spec.Expected, answer) {
+				unexpected = append(unexpected, answer)
+			}
+		}
+		if len(unexpected) > 0 {
+			return fmt.Sprintf("unexpected answers: %s", strings.Join(unexpected, ", "))
+		}
+	}
+
+	return ""
+}
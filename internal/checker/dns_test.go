@@ -0,0 +1,393 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"shm/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		answers  model.Strings
+		expected model.Strings
+		match    model.DNSMatch
+		want     string
+	}{
+		{name: "nothing expected", answers: model.Strings{"192.0.2.1"}, match: model.DNSMatchEquals},
+		{name: "nothing expected and no answers", match: model.DNSMatchEquals},
+		{
+			name:     "equal",
+			answers:  model.Strings{"192.0.2.1", "192.0.2.2"},
+			expected: model.Strings{"192.0.2.2", "192.0.2.1"},
+			match:    model.DNSMatchEquals,
+		},
+		{
+			name:     "missing answer",
+			answers:  model.Strings{"192.0.2.1"},
+			expected: model.Strings{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+			match:    model.DNSMatchEquals,
+			want:     "expected answers missing: 192.0.2.2, 192.0.2.3",
+		},
+		{
+			name:     "unexpected answer",
+			answers:  model.Strings{"192.0.2.1", "192.0.2.9"},
+			expected: model.Strings{"192.0.2.1"},
+			match:    model.DNSMatchEquals,
+			want:     "unexpected answers: 192.0.2.9",
+		},
+		{
+			name:     "missing is reported before unexpected",
+			answers:  model.Strings{"192.0.2.9"},
+			expected: model.Strings{"192.0.2.1"},
+			match:    model.DNSMatchEquals,
+			want:     "expected answers missing: 192.0.2.1",
+		},
+		{
+			name:     "no answers",
+			expected: model.Strings{"192.0.2.1"},
+			match:    model.DNSMatchContains,
+			want:     "expected answers missing: 192.0.2.1",
+		},
+		{
+			name:     "contains subset",
+			answers:  model.Strings{"10 mx1.example.com", "20 mx2.example.com"},
+			expected: model.Strings{"10 mx1.example.com"},
+			match:    model.DNSMatchContains,
+		},
+		{
+			name:     "contains missing",
+			answers:  model.Strings{"20 mx2.example.com"},
+			expected: model.Strings{"10 mx1.example.com"},
+			match:    model.DNSMatchContains,
+			want:     "expected answers missing: 10 mx1.example.com",
+		},
+		{
+			name:     "comparison is exact",
+			answers:  model.Strings{"v=spf1 -all"},
+			expected: model.Strings{"V=SPF1 -all"},
+			match:    model.DNSMatchContains,
+			want:     "expected answers missing: V=SPF1 -all",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := model.DNSSpec{Expected: tt.expected, Match: tt.match}
+			if got := matchAnswers(tt.answers, spec); got != tt.want {
+				t.Errorf("matchAnswers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "example.com.", want: "example.com"},
+		{host: "Mail.Example.COM.", want: "mail.example.com"},
+		{host: "example.com", want: "example.com"},
+		{host: ".", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := normalizeHost(tt.host); got != tt.want {
+				t.Errorf("normalizeHost(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+const (
+	typeA     = 1
+	typeNS    = 2
+	typeCNAME = 5
+	typeMX    = 15
+	typeTXT   = 16
+	typeAAAA  = 28
+
+	rcodeNameError = 3
+)
+
+type stubRecord struct {
+	rtype uint16
+	rdata []byte
+}
+
+func encodeName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func ipRecord(ip string) stubRecord {
+	addr := net.ParseIP(ip)
+	if v4 := addr.To4(); v4 != nil {
+		return stubRecord{typeA, v4}
+	}
+	return stubRecord{typeAAAA, addr.To16()}
+}
+
+func cnameRecord(target string) stubRecord {
+	return stubRecord{typeCNAME, encodeName(target)}
+}
+
+func nsRecord(host string) stubRecord {
+	return stubRecord{typeNS, encodeName(host)}
+}
+
+func mxRecord(pref uint16, host string) stubRecord {
+	return stubRecord{typeMX, append(binary.BigEndian.AppendUint16(nil, pref), encodeName(host)...)}
+}
+
+func txtRecord(text string) stubRecord {
+	return stubRecord{typeTXT, append([]byte{byte(len(text))}, text...)}
+}
+
+// startStubResolver serves records by lower case names over UDP and returns
+// its address. Unknown names are answered with NXDOMAIN, and a CNAME record
+// answers queries of any type.
+func startStubResolver(t *testing.T, records map[string][]stubRecord) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := stubAnswer(buf[:n], records); resp != nil {
+				conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func stubAnswer(query []byte, records map[string][]stubRecord) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	var labels []string
+	end := 12
+	for end < len(query) && query[end] != 0 {
+		size := int(query[end])
+		if end+1+size > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[end+1:end+1+size]))
+		end += 1 + size
+	}
+	end += 5
+	if end > len(query) {
+		return nil
+	}
+	name := strings.ToLower(strings.Join(labels, "."))
+	qtype := binary.BigEndian.Uint16(query[end-4:])
+
+	var answers []stubRecord
+	for _, record := range records[name] {
+		if record.rtype == qtype || record.rtype == typeCNAME {
+			answers = append(answers, record)
+		}
+	}
+
+	// response with authoritative answer and recursion available
+	flags := uint16(0x8480) | binary.BigEndian.Uint16(query[2:])&0x0100
+	if _, found := records[name]; !found {
+		flags |= rcodeNameError
+	}
+
+	resp := append([]byte{}, query[:2]...)
+	resp = binary.BigEndian.AppendUint16(resp, flags)
+	resp = binary.BigEndian.AppendUint16(resp, 1)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(answers)))
+	resp = binary.BigEndian.AppendUint16(resp, 0)
+	resp = binary.BigEndian.AppendUint16(resp, 0)
+	resp = append(resp, query[12:end]...)
+	for _, answer := range answers {
+		// the name of the answer points to the name of the question
+		resp = append(resp, 0xc0, 12)
+		resp = binary.BigEndian.AppendUint16(resp, answer.rtype)
+		resp = binary.BigEndian.AppendUint16(resp, 1)
+		resp = binary.BigEndian.AppendUint32(resp, 60)
+		resp = binary.BigEndian.AppendUint16(resp, uint16(len(answer.rdata)))
+		resp = append(resp, answer.rdata...)
+	}
+	return resp
+}
+
+func TestCheckDNS(t *testing.T) {
+	resolver := startStubResolver(t, map[string][]stubRecord{
+		"example.test": {
+			ipRecord("192.0.2.2"),
+			ipRecord("192.0.2.1"),
+			ipRecord("2001:db8::1"),
+			mxRecord(20, "MX2.Example.Test."),
+			mxRecord(10, "mx1.example.test."),
+			txtRecord("v=spf1 -all"),
+			nsRecord("ns1.example.test."),
+		},
+		"www.example.test":   {cnameRecord("Example.Test.")},
+		"empty.example.test": {},
+	})
+
+	tests := []struct {
+		name        string
+		url         string
+		spec        model.DNSSpec
+		wantAnswers model.Strings
+		wantReason  string
+		wantErr     bool
+		wantNoHost  bool
+	}{
+		{
+			name:        "A",
+			url:         "dns://example.test",
+			spec:        model.DNSSpec{Expected: model.Strings{"192.0.2.1", "192.0.2.2"}},
+			wantAnswers: model.Strings{"192.0.2.1", "192.0.2.2"},
+		},
+		{
+			name:        "AAAA",
+			url:         "dns://example.test",
+			spec:        model.DNSSpec{RecordType: "AAAA", Expected: model.Strings{"2001:db8::1"}},
+			wantAnswers: model.Strings{"2001:db8::1"},
+		},
+		{
+			name:        "CNAME",
+			url:         "dns://www.example.test",
+			spec:        model.DNSSpec{RecordType: "CNAME", Expected: model.Strings{"example.test"}},
+			wantAnswers: model.Strings{"example.test"},
+		},
+		{
+			name: "MX",
+			url:  "dns://example.test",
+			spec: model.DNSSpec{
+				RecordType: "MX",
+				Expected:   model.Strings{"10 mx1.example.test"},
+				Match:      model.DNSMatchContains,
+			},
+			wantAnswers: model.Strings{"10 mx1.example.test", "20 mx2.example.test"},
+		},
+		{
+			name:        "TXT",
+			url:         "dns://example.test",
+			spec:        model.DNSSpec{RecordType: "TXT", Expected: model.Strings{"v=spf1 -all"}},
+			wantAnswers: model.Strings{"v=spf1 -all"},
+		},
+		{
+			name:        "NS",
+			url:         "dns://example.test",
+			spec:        model.DNSSpec{RecordType: "NS"},
+			wantAnswers: model.Strings{"ns1.example.test"},
+		},
+		{
+			name:        "unexpected answer",
+			url:         "dns://example.test",
+			spec:        model.DNSSpec{Expected: model.Strings{"192.0.2.1"}},
+			wantAnswers: model.Strings{"192.0.2.1", "192.0.2.2"},
+			wantReason:  "unexpected answers: 192.0.2.2",
+		},
+		{
+			name:       "NXDOMAIN",
+			url:        "dns://missing.example.test",
+			spec:       model.DNSSpec{},
+			wantReason: "lookup failed",
+			wantErr:    true,
+			wantNoHost: true,
+		},
+		{
+			name:       "no records of type",
+			url:        "dns://empty.example.test",
+			spec:       model.DNSSpec{RecordType: "MX"},
+			wantReason: "lookup failed",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := model.Site{Url: tt.url, DNS: tt.spec}
+			site.DNS.Resolver = resolver
+			if err := site.Normalize(); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			result, err := (&Checker{}).checkDNS(ctx, site)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkDNS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var dnsErr *net.DNSError
+			if tt.wantNoHost && (!errors.As(err, &dnsErr) || !dnsErr.IsNotFound) {
+				t.Errorf("checkDNS() error = %v, want not found", err)
+			}
+			if !reflect.DeepEqual(result.Answers, tt.wantAnswers) {
+				t.Errorf("answers = %q, want %q", result.Answers, tt.wantAnswers)
+			}
+			if !strings.HasPrefix(result.FailureReason, tt.wantReason) || (tt.wantReason == "") != (result.FailureReason == "") {
+				t.Errorf("failure reason = %q, want %q", result.FailureReason, tt.wantReason)
+			}
+			if result.Successful != (tt.wantReason == "") {
+				t.Errorf("successful = %v, want %v", result.Successful, tt.wantReason == "")
+			}
+			if !result.Timings.DNSLookup.Valid {
+				t.Error("dns lookup timing was not recorded")
+			}
+		})
+	}
+}
+
+func TestCheckDNSUnreachableResolver(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nothing listens on the address after the connection is closed
+	resolver := conn.LocalAddr().String()
+	conn.Close()
+
+	site := model.Site{Url: "dns://example.test", DNS: model.DNSSpec{Resolver: resolver}}
+	if err := site.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := (&Checker{}).checkDNS(ctx, site)
+	if err == nil {
+		t.Fatal("checkDNS() returned no error")
+	}
+	if result.Successful || !strings.HasPrefix(result.FailureReason, "lookup failed") {
+		t.Errorf("result = %+v, want failed lookup", result)
+	}
+}
+
+func TestLookupUnsupportedRecordType(t *testing.T) {
+	_, err := lookup(context.Background(), newResolver("127.0.0.1:53"), "SRV", "example.test")
+	if err == nil || err.Error() != "unsupported record type SRV" {
+		t.Errorf("lookup() error = %v, want unsupported record type", err)
+	}
+}
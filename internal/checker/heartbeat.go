@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"shm/internal/model"
+	"time"
+)
+
+// checkHeartbeat reports whether the last ping of the heartbeat was received
+// in time. The site is reloaded, because a ping could arrive after the site
+// was published by the scheduler.
+func (c *Checker) checkHeartbeat(
+	ctx context.Context,
+	site model.Site,
+) (result model.CheckResult, err error) {
+	start := time.Now()
+	result = model.CheckResult{
+		Site: site,
+		Time: start,
+	}
+
+	current, err := c.sitesService.GetSiteById(ctx, site.Id)
+	if err != nil {
+		result.FailureReason = fmt.Sprintf("failed to get heartbeat: %v", err)
+		return result, err
+	}
+	if current == nil {
+		result.FailureReason = "heartbeat was deleted"
+		return result, fmt.Errorf("heartbeat was deleted")
+	}
+	result.Site = *current
+
+	heartbeat := current.Heartbeat
+	if !heartbeat.LastPingAt.Valid {
+		result.FailureReason = "no ping received"
+		return result, nil
+	}
+	if start.After(heartbeat.Deadline()) {
+		result.FailureReason = fmt.Sprintf(
+			"no ping received for %s",
+			start.Sub(heartbeat.LastPingAt.Time).Round(time.Second),
+		)
+		return result, nil
+	}
+
+	result.Successful = true
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package checker
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"shm/internal/service"
+	"testing"
+	"time"
+)
+
+func TestCheckHeartbeat(t *testing.T) {
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	sites := service.NewSitesService(database.SitesRepo(), config.CommonConfig{DbQueryTimeoutSec: 5 * time.Second})
+	c := &Checker{sitesService: sites}
+	ctx := context.Background()
+
+	site := model.Site{Type: model.CheckTypeHeartbeat, Heartbeat: model.HeartbeatSpec{PeriodSec: 60, GraceSec: 30}}
+	if err := site.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sites.AddSite(ctx, site); err != nil {
+		t.Fatal(err)
+	}
+	added, err := sites.GetSiteByUrl(ctx, site.Url)
+	if err != nil || added == nil {
+		t.Fatalf("GetSiteByUrl() = %v, %v", added, err)
+	}
+
+	tests := []struct {
+		name        string
+		lastPingAgo time.Duration
+		wantSuccess bool
+	}{
+		{name: "recent ping", lastPingAgo: 10 * time.Second, wantSuccess: true},
+		{name: "ping within grace", lastPingAgo: 80 * time.Second, wantSuccess: true},
+		{name: "late ping", lastPingAgo: 91 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := sites.UpdateLastPing(ctx, added.Id, time.Now().Add(-tt.lastPingAgo)); err != nil {
+				t.Fatal(err)
+			}
+
+			// the published site is outdated, the last ping is read again
+			published := *added
+			published.Heartbeat.LastPingAt = sql.NullTime{}
+			result, err := c.checkHeartbeat(ctx, published)
+			if err != nil {
+				t.Fatalf("checkHeartbeat() error = %v", err)
+			}
+			if result.Successful != tt.wantSuccess {
+				t.Errorf("successful = %v, want %v (%s)", result.Successful, tt.wantSuccess, result.FailureReason)
+			}
+		})
+	}
+
+	t.Run("deleted heartbeat", func(t *testing.T) {
+		if err := sites.DeleteSiteById(ctx, added.Id); err != nil {
+			t.Fatal(err)
+		}
+		result, err := c.checkHeartbeat(ctx, *added)
+		if err == nil || result.Successful {
+			t.Errorf("checkHeartbeat() = %+v, %v, want error", result, err)
+		}
+	})
+}
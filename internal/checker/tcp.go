@@ -0,0 +1,96 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"shm/internal/model"
+	"time"
+)
+
+func (c *Checker) checkTCP(
+	ctx context.Context,
+	site model.Site,
+) (result model.CheckResult, err error) {
+	start := time.Now()
+	result = model.CheckResult{
+		Site: site,
+		Time: start,
+	}
+
+	address, err := site.TCPAddress()
+	if err != nil {
+		result.FailureReason = err.Error()
+		return result, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		result.FailureReason = fmt.Sprintf("connection failed: %v", err)
+		return result, err
+	}
+	defer conn.Close()
+	result.Timings.Connect = since(start)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			result.FailureReason = fmt.Sprintf("failed to set deadline: %v", err)
+			return result, err
+		}
+	}
+
+	result.FailureReason = c.talk(conn, site.TCP, &result)
+	result.Latency = since(start)
+	result.Successful = result.FailureReason == ""
+	return result, nil
+}
+
+// talk performs the banner/send/expect exchange and returns the reason of
+// failure or empty string.
+func (c *Checker) talk(conn net.Conn, spec model.TCPSpec, result *model.CheckResult) string {
+	start := result.Time
+
+	if spec.Banner != "" {
+		if err := c.readUntil(conn, spec.Banner); err != nil {
+			return fmt.Sprintf("banner '%s' not received: %v", spec.Banner, err)
+		}
+		result.Timings.FirstByte = since(start)
+	}
+
+	if spec.Send != "" {
+		if _, err := conn.Write([]byte(spec.Send)); err != nil {
+			return fmt.Sprintf("failed to send payload: %v", err)
+		}
+	}
+
+	if spec.Expect != "" {
+		if err := c.readUntil(conn, spec.Expect); err != nil {
+			return fmt.Sprintf("response '%s' not received: %v", spec.Expect, err)
+		}
+		if !result.Timings.FirstByte.Valid {
+			result.Timings.FirstByte = since(start)
+		}
+	}
+
+	return ""
+}
+
+// readUntil reads from conn until expected is received, reading no more than
+// MaxBodyBytes.
+func (c *Checker) readUntil(conn net.Conn, expected string) error {
+	var received []byte
+	buf := make([]byte, 4096)
+	for int64(len(received)) < c.config.MaxBodyBytes {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		if bytes.Contains(received, []byte(expected)) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("read limit exceeded")
+}
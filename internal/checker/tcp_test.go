@@ -0,0 +1,102 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"shm/internal/config"
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+// serveTCP accepts connections, greets them with a banner and answers PONG to
+// PING until the listener is closed.
+func serveTCP(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte("220 ready\r\n"))
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err == nil && line == "PING\r\n" {
+					conn.Write([]byte("PONG\r\n"))
+				}
+			}()
+		}
+	}()
+	return ln
+}
+
+func TestCheckTCP(t *testing.T) {
+	ln := serveTCP(t)
+	c := &Checker{config: config.CheckerConfig{MaxBodyBytes: 1024}}
+
+	// a closed port of the same host
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name        string
+		address     string
+		spec        model.TCPSpec
+		wantErr     bool
+		wantSuccess bool
+	}{
+		{name: "port accepts connections", address: ln.Addr().String(), wantSuccess: true},
+		{name: "banner", address: ln.Addr().String(), spec: model.TCPSpec{Banner: "220"}, wantSuccess: true},
+		{
+			name:        "send and expect",
+			address:     ln.Addr().String(),
+			spec:        model.TCPSpec{Banner: "220", Send: "PING\r\n", Expect: "PONG"},
+			wantSuccess: true,
+		},
+		{name: "wrong banner", address: ln.Addr().String(), spec: model.TCPSpec{Banner: "SSH-2.0"}},
+		{
+			name:    "unexpected response",
+			address: ln.Addr().String(),
+			spec:    model.TCPSpec{Send: "HELO\r\n", Expect: "PONG"},
+		},
+		{name: "closed port", address: closedAddr, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := model.Site{Url: "tcp://" + tt.address, TCP: tt.spec}
+			if err := site.Normalize(); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			result, err := c.checkTCP(ctx, site)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkTCP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Successful != tt.wantSuccess {
+				t.Errorf("successful = %v, want %v (%s)", result.Successful, tt.wantSuccess, result.FailureReason)
+			}
+			if !tt.wantErr && !result.Timings.Connect.Valid {
+				t.Error("connect timing is not recorded")
+			}
+			if tt.spec.Banner != "" && tt.wantSuccess && !result.Timings.FirstByte.Valid {
+				t.Error("first byte timing is not recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"shm/internal/model"
+	"time"
+)
+
+// certificateRoots are the trusted root certificates, nil means the roots of
+// the system.
+var certificateRoots *x509.CertPool
+
+// newHTTPClient returns a client for checks of sites. Redirects are followed
+// unless followRedirects is false, then the redirect response itself is
+// checked.
+func newHTTPClient(followRedirects bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Certificates are verified by inspectCertificate, so details of invalid
+	// chains are recorded instead of failing on handshake.
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	// Every check opens a new connection, so timings of DNS lookup, connect
+	// and TLS handshake are always measured.
+	transport.DisableKeepAlives = true
+	client := &http.Client{Transport: transport}
+	if !followRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// inspectCertificate describes the peer certificate chain of the response.
+// It returns nil if the response was not received over TLS.
+func inspectCertificate(siteId int64, resp *http.Response, now time.Time) *model.Certificate {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+
+	chain := resp.TLS.PeerCertificates
+	leaf := chain[0]
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	cert := &model.Certificate{
+		SiteId:     siteId,
+		Subject:    leaf.Subject.String(),
+		Issuer:     leaf.Issuer.String(),
+		SANs:       model.Strings(leaf.DNSNames),
+		NotBefore:  leaf.NotBefore,
+		ExpiresAt:  leaf.NotAfter,
+		ChainValid: true,
+		CheckedAt:  now,
+	}
+	if cert.SANs == nil {
+		cert.SANs = model.Strings{}
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         certificateRoots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		cert.ChainValid = false
+		cert.ChainError = err.Error()
+	}
+
+	cert.HostnameValid = leaf.VerifyHostname(resp.Request.URL.Hostname()) == nil
+	return cert
+}
+
+// certificateFailure returns the reason why the certificate is not trusted or
+// empty string if it is valid.
+func certificateFailure(cert *model.Certificate) string {
+	if cert == nil {
+		return ""
+	}
+	if !cert.ChainValid {
+		return fmt.Sprintf("invalid certificate: %s", cert.ChainError)
+	}
+	if !cert.HostnameValid {
+		return "certificate does not match hostname"
+	}
+	return ""
+}
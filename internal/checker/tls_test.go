@@ -0,0 +1,241 @@
+package checker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/url"
+	"shm/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate signed by parent or a self-signed one if
+// parent is nil.
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+func TestInspectCertificate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	root := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             now.AddDate(-1, 0, 0),
+		NotAfter:              now.AddDate(10, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	intermediate := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate"},
+		NotBefore:             now.AddDate(-1, 0, 0),
+		NotAfter:              now.AddDate(5, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root)
+	leaf := func(serial int64, notAfter time.Time, parent *testCert) *x509.Certificate {
+		return newTestCert(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "example.com"},
+			DNSNames:     []string{"example.com", "*.example.com"},
+			NotBefore:    now.AddDate(0, -1, 0),
+			NotAfter:     notAfter,
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}, parent).cert
+	}
+	validLeaf := leaf(3, now.AddDate(0, 2, 0), intermediate)
+	expiredLeaf := leaf(4, now.Add(-time.Hour), intermediate)
+	selfSigned := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(5),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    now.AddDate(0, -1, 0),
+		NotAfter:     now.AddDate(1, 0, 0),
+	}, nil).cert
+
+	certificateRoots = x509.NewCertPool()
+	certificateRoots.AddCert(root.cert)
+	t.Cleanup(func() { certificateRoots = nil })
+
+	tests := []struct {
+		name         string
+		host         string
+		chain        []*x509.Certificate
+		wantChain    bool
+		wantHostname bool
+	}{
+		{
+			name:         "valid chain",
+			host:         "example.com",
+			chain:        []*x509.Certificate{validLeaf, intermediate.cert},
+			wantChain:    true,
+			wantHostname: true,
+		},
+		{
+			name:         "wildcard hostname",
+			host:         "www.example.com",
+			chain:        []*x509.Certificate{validLeaf, intermediate.cert},
+			wantChain:    true,
+			wantHostname: true,
+		},
+		{
+			name:         "hostname mismatch",
+			host:         "example.org",
+			chain:        []*x509.Certificate{validLeaf, intermediate.cert},
+			wantChain:    true,
+			wantHostname: false,
+		},
+		{
+			name:         "wildcard matches one label only",
+			host:         "a.b.example.com",
+			chain:        []*x509.Certificate{validLeaf, intermediate.cert},
+			wantChain:    true,
+			wantHostname: false,
+		},
+		{
+			name:         "missing intermediate",
+			host:         "example.com",
+			chain:        []*x509.Certificate{validLeaf},
+			wantChain:    false,
+			wantHostname: true,
+		},
+		{
+			name:         "expired",
+			host:         "example.com",
+			chain:        []*x509.Certificate{expiredLeaf, intermediate.cert},
+			wantChain:    false,
+			wantHostname: true,
+		},
+		{
+			name:         "self-signed",
+			host:         "example.com",
+			chain:        []*x509.Certificate{selfSigned},
+			wantChain:    false,
+			wantHostname: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Request: &http.Request{URL: &url.URL{Scheme: "https", Host: tt.host + ":443"}},
+				TLS:     &tls.ConnectionState{PeerCertificates: tt.chain},
+			}
+
+			cert := inspectCertificate(7, resp, now)
+			if cert == nil {
+				t.Fatal("inspectCertificate() = nil")
+			}
+
+			if cert.ChainValid != tt.wantChain {
+				t.Errorf("ChainValid = %v, want %v (%s)", cert.ChainValid, tt.wantChain, cert.ChainError)
+			}
+			if !tt.wantChain && cert.ChainError == "" {
+				t.Error("ChainError is empty for invalid chain")
+			}
+			if cert.HostnameValid != tt.wantHostname {
+				t.Errorf("HostnameValid = %v, want %v", cert.HostnameValid, tt.wantHostname)
+			}
+			if cert.SiteId != 7 || !cert.CheckedAt.Equal(now) {
+				t.Errorf("SiteId, CheckedAt = %d, %v", cert.SiteId, cert.CheckedAt)
+			}
+			if !cert.ExpiresAt.Equal(tt.chain[0].NotAfter) {
+				t.Errorf("ExpiresAt = %v, want %v", cert.ExpiresAt, tt.chain[0].NotAfter)
+			}
+			if !strings.Contains(cert.Subject, "example.com") {
+				t.Errorf("Subject = %q", cert.Subject)
+			}
+		})
+	}
+}
+
+func TestInspectCertificateWithoutTLS(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  *tls.ConnectionState
+	}{
+		{name: "plain http", tls: nil},
+		{name: "no peer certificates", tls: &tls.ConnectionState{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Request: &http.Request{URL: &url.URL{Scheme: "http", Host: "example.com"}},
+				TLS:     tt.tls,
+			}
+			if cert := inspectCertificate(1, resp, time.Now()); cert != nil {
+				t.Errorf("inspectCertificate() = %+v, want nil", cert)
+			}
+		})
+	}
+}
+
+func TestCertificateFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		cert *model.Certificate
+		want string
+	}{
+		{name: "no certificate", cert: nil, want: ""},
+		{name: "valid", cert: &model.Certificate{ChainValid: true, HostnameValid: true}, want: ""},
+		{
+			name: "invalid chain",
+			cert: &model.Certificate{ChainError: "x509: certificate has expired", HostnameValid: true},
+			want: "invalid certificate: x509: certificate has expired",
+		},
+		{
+			name: "invalid chain takes precedence",
+			cert: &model.Certificate{ChainError: "x509: unknown authority"},
+			want: "invalid certificate: x509: unknown authority",
+		},
+		{
+			name: "hostname mismatch",
+			cert: &model.Certificate{ChainValid: true},
+			want: "certificate does not match hostname",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certificateFailure(tt.cert); got != tt.want {
+				t.Errorf("certificateFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
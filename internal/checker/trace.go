@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"crypto/tls"
+	"database/sql"
+	"net/http/httptrace"
+	"shm/internal/model"
+	"sync"
+	"time"
+)
+
+// tracer collects timings of request phases via httptrace.
+type tracer struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+
+	timings model.Timings
+}
+
+func newTracer(start time.Time) *tracer {
+	return &tracer{start: start}
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.DNSLookup = since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil {
+				t.timings.Connect = since(t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil {
+				t.timings.TLSHandshake = since(t.tlsStart)
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			t.timings.FirstByte = since(t.start)
+		},
+	}
+}
+
+// finish records the end of the body transfer and returns collected timings.
+func (t *tracer) finish() model.Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.firstByte.IsZero() {
+		t.timings.Transfer = since(t.firstByte)
+	}
+	return t.timings
+}
+
+func since(start time.Time) sql.NullInt64 {
+	if start.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{
+		Int64: time.Since(start).Milliseconds(),
+		Valid: true,
+	}
+}
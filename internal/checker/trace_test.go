@@ -0,0 +1,76 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shm/internal/config"
+	"shm/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTPTimings(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		// the body is transferred after the first byte
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	secure := httptest.NewTLSServer(handler)
+	defer secure.Close()
+
+	c := &Checker{
+		client:            newHTTPClient(true),
+		noRedirectsClient: newHTTPClient(false),
+		config:            config.CheckerConfig{MaxBodyBytes: 1024},
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		wantDNS bool
+		wantTLS bool
+	}{
+		{name: "ip address", url: plain.URL},
+		{name: "host name", url: strings.Replace(plain.URL, "127.0.0.1", "localhost", 1), wantDNS: true},
+		{name: "tls", url: secure.URL, wantTLS: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := model.Site{Url: tt.url}
+			if err := site.Normalize(); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			result, err := c.checkHTTP(ctx, site)
+			if err != nil {
+				t.Fatalf("checkHTTP() error = %v", err)
+			}
+
+			timings := result.Timings
+			if timings.DNSLookup.Valid != tt.wantDNS {
+				t.Errorf("dns lookup recorded: %v, want %v", timings.DNSLookup.Valid, tt.wantDNS)
+			}
+			if timings.TLSHandshake.Valid != tt.wantTLS {
+				t.Errorf("tls handshake recorded: %v, want %v", timings.TLSHandshake.Valid, tt.wantTLS)
+			}
+			if !timings.Connect.Valid || !timings.FirstByte.Valid || !timings.Transfer.Valid {
+				t.Fatalf("timings = %+v, want connect, first byte and transfer", timings)
+			}
+			if timings.Transfer.Int64 < 20 {
+				t.Errorf("transfer = %d ms, want at least 20 ms", timings.Transfer.Int64)
+			}
+			if timings.FirstByte.Int64 > result.Latency.Int64 {
+				t.Errorf("first byte at %d ms is after latency %d ms", timings.FirstByte.Int64, result.Latency.Int64)
+			}
+		})
+	}
+}
@@ -1,13 +1,31 @@
 package config
 
+import "time"
+
 type AlertServiceConfig struct {
-	NumberOrFailedChecks int
+	NumberOrFailedChecks  int
+	CertExpiryWarningDays []int
+	LatencyWindow         int
+	FlapWindow            int
+	FlapStartPercent      int
+	FlapStopPercent       int
+	EscalationTickSec     time.Duration
+	// RelayTickSec is the period of publishing notifications left pending
+	// after a failed publish
+	RelayTickSec time.Duration
 	CommonConfig
 }
 
 func NewAlertServiceConfig() AlertServiceConfig {
 	return AlertServiceConfig{
-		NumberOrFailedChecks: getEnvAsInt("NUMBER_OF_FAILED_CHECKS", 3),
-		CommonConfig:         NewCommonConfig(),
+		NumberOrFailedChecks:  getEnvAsInt("NUMBER_OF_FAILED_CHECKS", 3),
+		CertExpiryWarningDays: getEnvAsIntSlice("CERT_EXPIRY_WARNING_DAYS", []int{30, 14, 7, 1}),
+		LatencyWindow:         getEnvAsInt("LATENCY_WINDOW", 10),
+		FlapWindow:            getEnvAsInt("FLAP_WINDOW", 20),
+		FlapStartPercent:      getEnvAsInt("FLAP_START_PERCENT", 50),
+		FlapStopPercent:       getEnvAsInt("FLAP_STOP_PERCENT", 25),
+		EscalationTickSec:     getEnvAsDuration("ESCALATION_TICK_SEC", 30*time.Second),
+		RelayTickSec:          getEnvAsDuration("NOTIFICATION_RELAY_TICK_SEC", 5*time.Second),
+		CommonConfig:          NewCommonConfig(),
 	}
 }
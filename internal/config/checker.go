@@ -1,13 +1,15 @@
 package config
 
 type CheckerConfig struct {
-	Workers int
+	Workers      int
+	MaxBodyBytes int64
 	CommonConfig
 }
 
 func NewCheckerConfig() CheckerConfig {
 	return CheckerConfig{
 		Workers:      getEnvAsInt("CHECKER_WORKERS", 1000),
+		MaxBodyBytes: int64(getEnvAsInt("CHECKER_MAX_BODY_BYTES", 1<<20)),
 		CommonConfig: NewCommonConfig(),
 	}
 }
@@ -68,6 +68,37 @@ func getEnvAsInt(key string, defaultVal int) int {
 	return value
 }
 
+func getEnvAsIntSlice(key string, defaultVal []int) []int {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultVal
+	}
+
+	var values []int
+	for _, part := range strings.Split(valueStr, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			slog.Error("failed to parse env variable as list of int", slog.String("env_var", key), sl.Error(err))
+			os.Exit(1)
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// getEnvAsPositiveInt is getEnvAsInt for counts and limits, which stop the
+// service from working when they are not positive.
+func getEnvAsPositiveInt(key string, defaultVal int) int {
+	value := getEnvAsInt(key, defaultVal)
+	if value < 1 {
+		slog.Error("env variable must be positive", slog.String("env_var", key), slog.Int("value", value))
+		os.Exit(1)
+	}
+
+	return value
+}
+
 func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
 	valueInt := getEnvAsInt(key, -1)
 	if valueInt == -1 {
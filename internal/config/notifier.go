@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type NotifierConfig struct {
+	TelegramToken    string
+	NotifyTimeoutSec time.Duration
+	SMTP             SMTPConfig
+	Outbox           OutboxConfig
+	CommonConfig
+}
+
+func NewNotifierConfig() NotifierConfig {
+	return NotifierConfig{
+		TelegramToken:    getEnvFromFile("TELEGRAM_TOKEN_FILE", getEnv("TELEGRAM_TOKEN", "")),
+		NotifyTimeoutSec: getEnvAsDuration("NOTIFY_TIMEOUT_SEC", 60*time.Second),
+		SMTP:             NewSMTPConfig(),
+		Outbox:           NewOutboxConfig(),
+		CommonConfig:     NewCommonConfig(),
+	}
+}
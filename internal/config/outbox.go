@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// OutboxConfig configures retries of notification deliveries.
+type OutboxConfig struct {
+	// MaxAttempts is the number of attempts after which a delivery fails
+	MaxAttempts int
+	// BackoffSec is the delay after the first failed attempt, it doubles
+	// after each next one up to MaxBackoffSec
+	BackoffSec    time.Duration
+	MaxBackoffSec time.Duration
+	PollSec       time.Duration
+	BatchSize     int
+	Workers       int
+}
+
+func NewOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		MaxAttempts:   getEnvAsPositiveInt("OUTBOX_MAX_ATTEMPTS", 10),
+		BackoffSec:    getEnvAsDuration("OUTBOX_BACKOFF_SEC", 30*time.Second),
+		MaxBackoffSec: getEnvAsDuration("OUTBOX_MAX_BACKOFF_SEC", time.Hour),
+		PollSec:       getEnvAsDuration("OUTBOX_POLL_SEC", 5*time.Second),
+		BatchSize:     getEnvAsPositiveInt("OUTBOX_BATCH_SIZE", 100),
+		Workers:       getEnvAsPositiveInt("OUTBOX_WORKERS", 8),
+	}
+}
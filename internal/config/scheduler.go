@@ -3,13 +3,21 @@ package config
 import "time"
 
 type SchedulerConfig struct {
-	IntervalMin time.Duration
+	IntervalMin   time.Duration
+	TickSec       time.Duration
+	JitterPercent int
+	LeaseTtlSec   time.Duration
+	DigestCron    string
 	CommonConfig
 }
 
 func NewSchedulerConfig() SchedulerConfig {
 	return SchedulerConfig{
-		IntervalMin:  getEnvAsDuration("SCHEDULER_INTERVAL_MIN", 1*time.Minute),
-		CommonConfig: NewCommonConfig(),
+		IntervalMin:   getEnvAsDuration("SCHEDULER_INTERVAL_MIN", 1*time.Minute),
+		TickSec:       getEnvAsDuration("SCHEDULER_TICK_SEC", 1*time.Second),
+		JitterPercent: getEnvAsInt("SCHEDULER_JITTER_PERCENT", 10),
+		LeaseTtlSec:   getEnvAsDuration("SCHEDULER_LEASE_TTL_SEC", 15*time.Second),
+		DigestCron:    getEnv("DIGEST_CRON", ""),
+		CommonConfig:  NewCommonConfig(),
 	}
 }
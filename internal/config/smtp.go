@@ -0,0 +1,22 @@
+package config
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// TLS is "starttls", "tls" for implicit TLS or "none"
+	TLS string
+}
+
+func NewSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		Host:     getEnv("SMTP_HOST", ""),
+		Port:     getEnv("SMTP_PORT", "587"),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnvFromFile("SMTP_PASSWORD_FILE", getEnv("SMTP_PASSWORD", "")),
+		From:     getEnv("SMTP_FROM", ""),
+		TLS:      getEnv("SMTP_TLS", "starttls"),
+	}
+}
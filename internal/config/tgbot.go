@@ -1,13 +1,15 @@
 package config
 
 type TelegramBotConfig struct {
-	Token string
+	Token            string
+	HeartbeatBaseUrl string
 	CommonConfig
 }
 
 func NewTelegramBotConfig() TelegramBotConfig {
 	return TelegramBotConfig{
-		Token:        getEnvFromFile("TELEGRAM_TOKEN_FILE", getEnv("TELEGRAM_TOKEN", "")),
-		CommonConfig: NewCommonConfig(),
+		Token:            getEnvFromFile("TELEGRAM_TOKEN_FILE", getEnv("TELEGRAM_TOKEN", "")),
+		HeartbeatBaseUrl: getEnv("HEARTBEAT_BASE_URL", "http://server:8080"),
+		CommonConfig:     NewCommonConfig(),
 	}
 }
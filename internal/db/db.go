@@ -8,7 +8,17 @@ import (
 type Database interface {
 	DB() *sql.DB
 
+	AlertRulesRepo() repository.AlertRulesProvider
+	CertificatesRepo() repository.CertificatesProvider
+	ChannelsRepo() repository.ChannelsProvider
 	ChatsRepo() repository.ChatsProvider
+	EmailsRepo() repository.EmailsProvider
+	EscalationPoliciesRepo() repository.EscalationPoliciesProvider
+	IncidentsRepo() repository.IncidentsProvider
+	LeasesRepo() repository.LeasesProvider
+	MaintenanceRepo() repository.MaintenanceProvider
+	NotificationDeliveriesRepo() repository.NotificationDeliveriesProvider
+	PendingNotificationsRepo() repository.PendingNotificationsProvider
 	ResultsRepo() repository.ResultsProvider
 	SitesRepo() repository.SitesProvider
 
@@ -11,10 +11,20 @@ import (
 )
 
 type Postgres struct {
-	db      *sql.DB
-	chats   repository.ChatsProvider
-	results repository.ResultsProvider
-	sites   repository.SitesProvider
+	db           *sql.DB
+	alertRules   repository.AlertRulesProvider
+	certificates repository.CertificatesProvider
+	channels     repository.ChannelsProvider
+	chats        repository.ChatsProvider
+	emails       repository.EmailsProvider
+	escalations  repository.EscalationPoliciesProvider
+	incidents    repository.IncidentsProvider
+	leases       repository.LeasesProvider
+	maintenance  repository.MaintenanceProvider
+	outbox       repository.NotificationDeliveriesProvider
+	pending      repository.PendingNotificationsProvider
+	results      repository.ResultsProvider
+	sites        repository.SitesProvider
 }
 
 func NewPostgres(url string) (*Postgres, error) {
@@ -30,10 +40,20 @@ func NewPostgres(url string) (*Postgres, error) {
 	}
 
 	return &Postgres{
-		db:      db,
-		chats:   repo.NewChatsRepo(db),
-		results: repo.NewResultsRepo(db),
-		sites:   repo.NewSitesRepo(db),
+		db:           db,
+		alertRules:   repo.NewAlertRulesRepo(db),
+		certificates: repo.NewCertificatesRepo(db),
+		channels:     repo.NewChannelsRepo(db),
+		chats:        repo.NewChatsRepo(db),
+		emails:       repo.NewEmailsRepo(db),
+		escalations:  repo.NewEscalationPoliciesRepo(db),
+		incidents:    repo.NewIncidentsRepo(db),
+		leases:       repo.NewLeasesRepo(db),
+		maintenance:  repo.NewMaintenanceRepo(db),
+		outbox:       repo.NewNotificationDeliveriesRepo(db),
+		pending:      repo.NewPendingNotificationsRepo(db),
+		results:      repo.NewResultsRepo(db),
+		sites:        repo.NewSitesRepo(db),
 	}, nil
 }
 
@@ -41,10 +61,50 @@ func (p *Postgres) DB() *sql.DB {
 	return p.db
 }
 
+func (p *Postgres) AlertRulesRepo() repository.AlertRulesProvider {
+	return p.alertRules
+}
+
+func (p *Postgres) CertificatesRepo() repository.CertificatesProvider {
+	return p.certificates
+}
+
+func (p *Postgres) ChannelsRepo() repository.ChannelsProvider {
+	return p.channels
+}
+
 func (p *Postgres) ChatsRepo() repository.ChatsProvider {
 	return p.chats
 }
 
+func (p *Postgres) EmailsRepo() repository.EmailsProvider {
+	return p.emails
+}
+
+func (p *Postgres) EscalationPoliciesRepo() repository.EscalationPoliciesProvider {
+	return p.escalations
+}
+
+func (p *Postgres) IncidentsRepo() repository.IncidentsProvider {
+	return p.incidents
+}
+
+func (p *Postgres) LeasesRepo() repository.LeasesProvider {
+	return p.leases
+}
+
+func (p *Postgres) MaintenanceRepo() repository.MaintenanceProvider {
+	return p.maintenance
+}
+
+func (p *Postgres) NotificationDeliveriesRepo() repository.NotificationDeliveriesProvider {
+	return p.outbox
+}
+
+func (p *Postgres) PendingNotificationsRepo() repository.PendingNotificationsProvider {
+	return p.pending
+}
+
 func (p *Postgres) ResultsRepo() repository.ResultsProvider {
 	return p.results
 }
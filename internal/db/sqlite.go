@@ -13,12 +13,29 @@ import (
 
 const checkResultsScheme = `
 CREATE TABLE IF NOT EXISTS check_results(
+	id INTEGER PRIMARY KEY,
 	site_id INTEGER NOT NULL,
 	time TIMESTAMP NOT NULL,
 	latency INTEGER,
-	code INTEGER
+	code INTEGER,
+	successful BOOLEAN NOT NULL DEFAULT FALSE CHECK (successful IN (0, 1)),
+	failure_reason TEXT NOT NULL DEFAULT '',
+	dns_ms INTEGER,
+	connect_ms INTEGER,
+	tls_ms INTEGER,
+	ttfb_ms INTEGER,
+	transfer_ms INTEGER,
+	answers TEXT NOT NULL DEFAULT '[]',
+	assertion_failed BOOLEAN NOT NULL DEFAULT FALSE CHECK (assertion_failed IN (0, 1)),
+	scheduled_at TIMESTAMP
 )`
 
+// a run of the scheduler checked again has a single result, results without
+// a run (e.g. pings of heartbeats) are not unique
+const checkResultsRunIndex = `
+CREATE UNIQUE INDEX IF NOT EXISTS check_results_run_idx
+ON check_results (site_id, scheduled_at)`
+
 const chatsScheme = `
 CREATE TABLE IF NOT EXISTS chats(
 	id INTEGER PRIMARY KEY,
@@ -35,14 +52,176 @@ CREATE TABLE IF NOT EXISTS chat_to_site(
 const sitesScheme = `
 CREATE TABLE IF NOT EXISTS sites(
 	id INTEGER PRIMARY KEY,
-	url TEXT UNIQUE NOT NULL
+	url TEXT UNIQUE NOT NULL,
+	type TEXT NOT NULL DEFAULT 'http',
+	method TEXT NOT NULL DEFAULT 'GET',
+	headers TEXT NOT NULL DEFAULT '{}',
+	body TEXT NOT NULL DEFAULT '',
+	accepted_codes TEXT NOT NULL DEFAULT '200',
+	assertions TEXT NOT NULL DEFAULT '[]',
+	no_redirects BOOLEAN NOT NULL DEFAULT FALSE CHECK (no_redirects IN (0, 1)),
+	tcp_banner TEXT NOT NULL DEFAULT '',
+	tcp_send TEXT NOT NULL DEFAULT '',
+	tcp_expect TEXT NOT NULL DEFAULT '',
+	dns_record_type TEXT NOT NULL DEFAULT 'A',
+	dns_resolver TEXT NOT NULL DEFAULT '',
+	dns_expected TEXT NOT NULL DEFAULT '[]',
+	dns_match TEXT NOT NULL DEFAULT 'equals',
+	dns_answers TEXT NOT NULL DEFAULT '[]',
+	heartbeat_period_sec INTEGER NOT NULL DEFAULT 0,
+	heartbeat_grace_sec INTEGER NOT NULL DEFAULT 0,
+	last_ping_at TIMESTAMP,
+	interval_sec INTEGER NOT NULL DEFAULT 0,
+	next_run_at TIMESTAMP,
+	base_run_at TIMESTAMP,
+	state TEXT NOT NULL DEFAULT 'unknown',
+	latency_max_ms INTEGER NOT NULL DEFAULT 0,
+	latency_p95_ms INTEGER NOT NULL DEFAULT 0,
+	tags TEXT NOT NULL DEFAULT '[]'
+)`
+
+const certificatesScheme = `
+CREATE TABLE IF NOT EXISTS certificates(
+	site_id INTEGER PRIMARY KEY,
+	subject TEXT NOT NULL,
+	issuer TEXT NOT NULL,
+	sans TEXT NOT NULL,
+	not_before TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	hostname_valid BOOLEAN NOT NULL CHECK (hostname_valid IN (0, 1)),
+	chain_valid BOOLEAN NOT NULL CHECK (chain_valid IN (0, 1)),
+	chain_error TEXT NOT NULL,
+	checked_at TIMESTAMP NOT NULL,
+	warned_days INTEGER
+)`
+
+const leasesScheme = `
+CREATE TABLE IF NOT EXISTS leases(
+	name TEXT PRIMARY KEY,
+	holder TEXT NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	state TEXT NOT NULL DEFAULT ''
+)`
+
+const incidentsScheme = `
+CREATE TABLE IF NOT EXISTS incidents(
+	id INTEGER PRIMARY KEY,
+	site_id INTEGER NOT NULL,
+	kind TEXT NOT NULL DEFAULT 'down',
+	started_at TIMESTAMP NOT NULL,
+	ended_at TIMESTAMP,
+	cause TEXT NOT NULL,
+	first_failed_result_id INTEGER NOT NULL,
+	last_failed_result_id INTEGER NOT NULL,
+	acked_at TIMESTAMP,
+	acked_by TEXT NOT NULL DEFAULT '',
+	escalation_step INTEGER NOT NULL DEFAULT 0
+)`
+
+const alertRulesScheme = `
+CREATE TABLE IF NOT EXISTS alert_rules(
+	id INTEGER PRIMARY KEY,
+	site_id INTEGER,
+	tag TEXT NOT NULL DEFAULT '',
+	condition TEXT NOT NULL
+)`
+
+const maintenanceWindowsScheme = `
+CREATE TABLE IF NOT EXISTS maintenance_windows(
+	id INTEGER PRIMARY KEY,
+	site_id INTEGER,
+	tag TEXT NOT NULL DEFAULT '',
+	starts_at TIMESTAMP,
+	ends_at TIMESTAMP,
+	cron TEXT NOT NULL DEFAULT '',
+	duration_min INTEGER NOT NULL DEFAULT 0,
+	announce BOOLEAN NOT NULL DEFAULT FALSE CHECK (announce IN (0, 1)),
+	active BOOLEAN NOT NULL DEFAULT FALSE CHECK (active IN (0, 1))
+)`
+
+const escalationPoliciesScheme = `
+CREATE TABLE IF NOT EXISTS escalation_policies(
+	id INTEGER PRIMARY KEY,
+	site_id INTEGER,
+	tag TEXT NOT NULL DEFAULT '',
+	steps TEXT NOT NULL DEFAULT '[]'
+)`
+
+const channelsScheme = `
+CREATE TABLE IF NOT EXISTS channels(
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	config TEXT NOT NULL DEFAULT '{}'
+)`
+
+const channelToSiteScheme = `
+CREATE TABLE IF NOT EXISTS channel_to_site(
+	channel_id INTEGER NOT NULL,
+	site_id INTEGER NOT NULL,
+	PRIMARY KEY(channel_id, site_id)
+)`
+
+// webhook deliveries are recorded in the outbox with other notifications
+const dropWebhookDeliveries = "DROP TABLE IF EXISTS webhook_deliveries"
+
+const emailToSiteScheme = `
+CREATE TABLE IF NOT EXISTS email_to_site(
+	email TEXT NOT NULL,
+	site_id INTEGER NOT NULL,
+	PRIMARY KEY(email, site_id)
+)`
+
+const notificationDeliveriesScheme = `
+CREATE TABLE IF NOT EXISTS notification_deliveries(
+	id INTEGER PRIMARY KEY,
+	notification TEXT NOT NULL,
+	channel_id INTEGER NOT NULL DEFAULT 0,
+	channel_name TEXT NOT NULL,
+	channel_type TEXT NOT NULL,
+	channel_config TEXT NOT NULL DEFAULT '{}',
+	recipient TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	delivered_at TIMESTAMP,
+	notification_id INTEGER
+)`
+
+const notificationDeliveriesIndex = `
+CREATE INDEX IF NOT EXISTS notification_deliveries_status_idx
+ON notification_deliveries (status, recipient, id)`
+
+// a notification published again by the alert service is delivered once to
+// each recipient, notifications without id are not unique
+const notificationDeliveriesNotificationIndex = `
+CREATE UNIQUE INDEX IF NOT EXISTS notification_deliveries_notification_idx
+ON notification_deliveries (notification_id, recipient)`
+
+const pendingNotificationsScheme = `
+CREATE TABLE IF NOT EXISTS pending_notifications(
+	id INTEGER PRIMARY KEY,
+	notification TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
 )`
 
 type SQLite struct {
-	db      *sql.DB
-	chats   repository.ChatsProvider
-	results repository.ResultsProvider
-	sites   repository.SitesProvider
+	db           *sql.DB
+	alertRules   repository.AlertRulesProvider
+	certificates repository.CertificatesProvider
+	channels     repository.ChannelsProvider
+	chats        repository.ChatsProvider
+	emails       repository.EmailsProvider
+	escalations  repository.EscalationPoliciesProvider
+	incidents    repository.IncidentsProvider
+	leases       repository.LeasesProvider
+	maintenance  repository.MaintenanceProvider
+	outbox       repository.NotificationDeliveriesProvider
+	pending      repository.PendingNotificationsProvider
+	results      repository.ResultsProvider
+	sites        repository.SitesProvider
 }
 
 func NewSQLite(dataSourceName string) (*SQLite, error) {
@@ -60,10 +239,20 @@ func NewSQLite(dataSourceName string) (*SQLite, error) {
 	}
 
 	return &SQLite{
-		db:      db,
-		chats:   repo.NewChatsRepo(db),
-		results: repo.NewResultsRepo(db),
-		sites:   repo.NewSitesRepo(db),
+		db:           db,
+		alertRules:   repo.NewAlertRulesRepo(db),
+		certificates: repo.NewCertificatesRepo(db),
+		channels:     repo.NewChannelsRepo(db),
+		chats:        repo.NewChatsRepo(db),
+		emails:       repo.NewEmailsRepo(db),
+		escalations:  repo.NewEscalationPoliciesRepo(db),
+		incidents:    repo.NewIncidentsRepo(db),
+		leases:       repo.NewLeasesRepo(db),
+		maintenance:  repo.NewMaintenanceRepo(db),
+		outbox:       repo.NewNotificationDeliveriesRepo(db),
+		pending:      repo.NewPendingNotificationsRepo(db),
+		results:      repo.NewResultsRepo(db),
+		sites:        repo.NewSitesRepo(db),
 	}, nil
 }
 
@@ -97,17 +286,119 @@ func initDB(ctx context.Context, db *sql.DB) error {
 		return err
 	}
 
-	return nil
+	if _, err := db.ExecContext(ctx, certificatesScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, leasesScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, incidentsScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, alertRulesScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, maintenanceWindowsScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, escalationPoliciesScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, channelsScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, channelToSiteScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, dropWebhookDeliveries); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, emailToSiteScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, notificationDeliveriesScheme); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, notificationDeliveriesIndex); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, pendingNotificationsScheme); err != nil {
+		return err
+	}
+
+	if err := migrateDB(ctx, db); err != nil {
+		return err
+	}
+
+	// columns of these indexes may be added to existing tables by migrateDB
+	if _, err := db.ExecContext(ctx, checkResultsRunIndex); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, notificationDeliveriesNotificationIndex)
+	return err
 }
 
 func (s *SQLite) DB() *sql.DB {
 	return s.db
 }
 
+func (s *SQLite) AlertRulesRepo() repository.AlertRulesProvider {
+	return s.alertRules
+}
+
+func (s *SQLite) CertificatesRepo() repository.CertificatesProvider {
+	return s.certificates
+}
+
+func (s *SQLite) ChannelsRepo() repository.ChannelsProvider {
+	return s.channels
+}
+
 func (s *SQLite) ChatsRepo() repository.ChatsProvider {
 	return s.chats
 }
 
+func (s *SQLite) EmailsRepo() repository.EmailsProvider {
+	return s.emails
+}
+
+func (s *SQLite) EscalationPoliciesRepo() repository.EscalationPoliciesProvider {
+	return s.escalations
+}
+
+func (s *SQLite) IncidentsRepo() repository.IncidentsProvider {
+	return s.incidents
+}
+
+func (s *SQLite) LeasesRepo() repository.LeasesProvider {
+	return s.leases
+}
+
+func (s *SQLite) MaintenanceRepo() repository.MaintenanceProvider {
+	return s.maintenance
+}
+
+func (s *SQLite) NotificationDeliveriesRepo() repository.NotificationDeliveriesProvider {
+	return s.outbox
+}
+
+func (s *SQLite) PendingNotificationsRepo() repository.PendingNotificationsProvider {
+	return s.pending
+}
+
 func (s *SQLite) ResultsRepo() repository.ResultsProvider {
 	return s.results
 }
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// addedColumns are columns added to tables after the tables were created.
+// CREATE TABLE IF NOT EXISTS does not change existing tables, so the columns
+// are added to databases created by earlier versions on start.
+var addedColumns = []struct {
+	table      string
+	definition string
+}{
+	{"check_results", "successful BOOLEAN NOT NULL DEFAULT FALSE CHECK (successful IN (0, 1))"},
+	{"check_results", "failure_reason TEXT NOT NULL DEFAULT ''"},
+	{"check_results", "dns_ms INTEGER"},
+	{"check_results", "connect_ms INTEGER"},
+	{"check_results", "tls_ms INTEGER"},
+	{"check_results", "ttfb_ms INTEGER"},
+	{"check_results", "transfer_ms INTEGER"},
+	{"check_results", "answers TEXT NOT NULL DEFAULT '[]'"},
+	{"check_results", "assertion_failed BOOLEAN NOT NULL DEFAULT FALSE CHECK (assertion_failed IN (0, 1))"},
+	{"check_results", "scheduled_at TIMESTAMP"},
+	{"sites", "type TEXT NOT NULL DEFAULT 'http'"},
+	{"sites", "method TEXT NOT NULL DEFAULT 'GET'"},
+	{"sites", "headers TEXT NOT NULL DEFAULT '{}'"},
+	{"sites", "body TEXT NOT NULL DEFAULT ''"},
+	{"sites", "accepted_codes TEXT NOT NULL DEFAULT '200'"},
+	{"sites", "assertions TEXT NOT NULL DEFAULT '[]'"},
+	{"sites", "tcp_banner TEXT NOT NULL DEFAULT ''"},
+	{"sites", "tcp_send TEXT NOT NULL DEFAULT ''"},
+	{"sites", "tcp_expect TEXT NOT NULL DEFAULT ''"},
+	{"sites", "dns_record_type TEXT NOT NULL DEFAULT 'A'"},
+	{"sites", "dns_resolver TEXT NOT NULL DEFAULT ''"},
+	{"sites", "dns_expected TEXT NOT NULL DEFAULT '[]'"},
+	{"sites", "dns_match TEXT NOT NULL DEFAULT 'equals'"},
+	{"sites", "heartbeat_period_sec INTEGER NOT NULL DEFAULT 0"},
+	{"sites", "heartbeat_grace_sec INTEGER NOT NULL DEFAULT 0"},
+	{"sites", "last_ping_at TIMESTAMP"},
+	{"sites", "interval_sec INTEGER NOT NULL DEFAULT 0"},
+	{"sites", "next_run_at TIMESTAMP"},
+	{"sites", "state TEXT NOT NULL DEFAULT 'unknown'"},
+	{"sites", "latency_max_ms INTEGER NOT NULL DEFAULT 0"},
+	{"sites", "latency_p95_ms INTEGER NOT NULL DEFAULT 0"},
+	{"sites", "tags TEXT NOT NULL DEFAULT '[]'"},
+	{"sites", "no_redirects BOOLEAN NOT NULL DEFAULT FALSE CHECK (no_redirects IN (0, 1))"},
+	{"sites", "dns_answers TEXT NOT NULL DEFAULT '[]'"},
+	{"sites", "base_run_at TIMESTAMP"},
+	{"leases", "state TEXT NOT NULL DEFAULT ''"},
+	{"incidents", "kind TEXT NOT NULL DEFAULT 'down'"},
+	{"incidents", "acked_at TIMESTAMP"},
+	{"incidents", "acked_by TEXT NOT NULL DEFAULT ''"},
+	{"incidents", "escalation_step INTEGER NOT NULL DEFAULT 0"},
+	{"notification_deliveries", "notification_id INTEGER"},
+}
+
+// columnBackfills fill added columns of existing rows by table.column, so
+// old rows agree with the migrations of Postgres.
+var columnBackfills = map[string]string{
+	"check_results.successful": "UPDATE check_results SET successful = COALESCE(code = 200, FALSE)",
+}
+
+// migrateDB brings tables of a database created by an earlier version up
+// to date.
+func migrateDB(ctx context.Context, db *sql.DB) error {
+	for _, added := range addedColumns {
+		column, _, _ := strings.Cut(added.definition, " ")
+		exists, err := columnExists(ctx, db, added.table, column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", added.table, added.definition))
+		if err != nil {
+			return fmt.Errorf("failed to add column %s to %s: %w", column, added.table, err)
+		}
+		backfill, ok := columnBackfills[added.table+"."+column]
+		if !ok {
+			continue
+		}
+		if _, err = db.ExecContext(ctx, backfill); err != nil {
+			return fmt.Errorf("failed to backfill column %s of %s: %w", column, added.table, err)
+		}
+	}
+
+	return addCheckResultIds(ctx, db)
+}
+
+// addCheckResultIds recreates the table of check results created before
+// results had ids, since a primary key can not be added to an existing
+// table. Existing results get their row ids.
+func addCheckResultIds(ctx context.Context, db *sql.DB) error {
+	exists, err := columnExists(ctx, db, "check_results", "id")
+	if err != nil || exists {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE check_results RENAME TO check_results_old"); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, checkResultsScheme); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT name FROM pragma_table_info('check_results_old')")
+	if err != nil {
+		return err
+	}
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return err
+		}
+		columns = append(columns, column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	list := strings.Join(columns, ", ")
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO check_results (id, "+list+") SELECT rowid, "+list+" FROM check_results_old",
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DROP TABLE check_results_old"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func columnExists(ctx context.Context, db *sql.DB, table string, column string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?",
+		table, column,
+	).Scan(&count)
+	return count > 0, err
+}
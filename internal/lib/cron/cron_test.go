@@ -0,0 +1,288 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "0 0 1 1 0"},
+		{expr: "59 23 31 12 7"},
+		{expr: "*/15 * * * *"},
+		{expr: "0-30/10 9-17 * * 1-5"},
+		{expr: "5/20 * * * *"},
+		{expr: "0 0,12 1,15 * *"},
+		{expr: "  0   3  *  *  6  "},
+		{expr: "", wantErr: true},
+		{expr: "* * * *", wantErr: true},
+		{expr: "* * * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * 32 * *", wantErr: true},
+		{expr: "* * * 0 *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "-1 * * * *", wantErr: true},
+		{expr: "30-10 * * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "*/-5 * * * *", wantErr: true},
+		{expr: "*/x * * * *", wantErr: true},
+		{expr: "1, * * * *", wantErr: true},
+		{expr: "1-2-3 * * * *", wantErr: true},
+		{expr: "* * * JAN *", wantErr: true},
+		{expr: "* * * * MON", wantErr: true},
+		{expr: "@daily", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if err := Validate(tt.expr); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestNext(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			expr: "* * * * *",
+			from: utc(2026, 3, 1, 12, 0),
+			want: utc(2026, 3, 1, 12, 1),
+		},
+		{
+			name: "seconds are truncated",
+			expr: "* * * * *",
+			from: time.Date(2026, 3, 1, 12, 0, 59, 999, time.UTC),
+			want: utc(2026, 3, 1, 12, 1),
+		},
+		{
+			name: "strictly after",
+			expr: "0 12 * * *",
+			from: utc(2026, 3, 1, 12, 0),
+			want: utc(2026, 3, 2, 12, 0),
+		},
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			from: utc(2026, 3, 1, 12, 7),
+			want: utc(2026, 3, 1, 12, 15),
+		},
+		{
+			name: "step wraps to next hour",
+			expr: "*/15 * * * *",
+			from: utc(2026, 3, 1, 12, 45),
+			want: utc(2026, 3, 1, 13, 0),
+		},
+		{
+			name: "step from value",
+			expr: "5/20 * * * *",
+			from: utc(2026, 3, 1, 12, 30),
+			want: utc(2026, 3, 1, 12, 45),
+		},
+		{
+			name: "ranges on weekdays",
+			expr: "0 9-17 * * 1-5",
+			from: utc(2026, 3, 6, 17, 0), // Friday
+			want: utc(2026, 3, 9, 9, 0),  // Monday
+		},
+		{
+			name: "list",
+			expr: "30 6,18 * * *",
+			from: utc(2026, 3, 1, 7, 0),
+			want: utc(2026, 3, 1, 18, 30),
+		},
+		{
+			name: "end of year",
+			expr: "0 0 1 1 *",
+			from: utc(2026, 12, 31, 23, 59),
+			want: utc(2027, 1, 1, 0, 0),
+		},
+		{
+			name: "31st skips short months",
+			expr: "0 0 31 * *",
+			from: utc(2026, 3, 31, 0, 0),
+			want: utc(2026, 5, 31, 0, 0),
+		},
+		{
+			name: "leap day",
+			expr: "0 0 29 2 *",
+			from: utc(2026, 3, 1, 0, 0),
+			want: utc(2028, 2, 29, 0, 0),
+		},
+		{
+			name: "impossible date",
+			expr: "0 0 30 2 *",
+			from: utc(2026, 3, 1, 0, 0),
+			want: time.Time{},
+		},
+		{
+			name: "sunday as 0",
+			expr: "0 0 * * 0",
+			from: utc(2026, 3, 2, 0, 0), // Monday
+			want: utc(2026, 3, 8, 0, 0),
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 0 * * 7",
+			from: utc(2026, 3, 2, 0, 0),
+			want: utc(2026, 3, 8, 0, 0),
+		},
+		{
+			name: "range ending on sunday as 7",
+			expr: "0 0 * * 6-7",
+			from: utc(2026, 3, 2, 0, 0),
+			want: utc(2026, 3, 7, 0, 0), // Saturday
+		},
+		{
+			name: "restricted day of month and day of week match either",
+			expr: "0 0 13 * 5",
+			from: utc(2026, 3, 1, 0, 0),
+			want: utc(2026, 3, 6, 0, 0), // Friday before the 13th
+		},
+		{
+			name: "restricted day of month matches when day of week does not",
+			expr: "0 0 13 * 5",
+			from: utc(2026, 3, 6, 0, 0),
+			want: utc(2026, 3, 13, 0, 0), // also a Friday
+		},
+		{
+			name: "either day field after the other",
+			expr: "0 0 2 * 1",
+			from: utc(2026, 3, 10, 0, 0), // Tuesday
+			want: utc(2026, 3, 16, 0, 0), // Monday before April 2
+		},
+		{
+			name: "unrestricted day of week requires day of month",
+			expr: "0 0 15 * *",
+			from: utc(2026, 3, 1, 0, 0),
+			want: utc(2026, 3, 15, 0, 0),
+		},
+		{
+			name: "unrestricted day of month requires day of week",
+			expr: "0 0 * * 3",
+			from: utc(2026, 3, 1, 0, 0),
+			want: utc(2026, 3, 4, 0, 0), // Wednesday
+		},
+		{
+			name: "stepped day of month is unrestricted",
+			expr: "0 0 */2 * 1",
+			from: utc(2026, 3, 1, 0, 0),
+			want: utc(2026, 3, 9, 0, 0), // the first odd Monday
+		},
+		{
+			name: "stepped day of week is unrestricted",
+			expr: "0 0 10 * */2",
+			from: utc(2026, 3, 1, 0, 0),
+			want: utc(2026, 3, 10, 0, 0), // Tuesday
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNextTimeZones checks that schedules are evaluated in UTC regardless of
+// the location of the time, including daylight saving time transitions.
+func TestNextTimeZones(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "fixed zone",
+			expr: "0 12 * * *",
+			from: time.Date(2026, 3, 1, 11, 30, 0, 0, time.FixedZone("UTC+5", 5*60*60)),
+			want: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day in utc differs from local day",
+			expr: "0 1 * * 1",
+			from: time.Date(2026, 3, 1, 21, 0, 0, 0, newYork), // Sunday 21:00, Monday 02:00 UTC
+			want: time.Date(2026, 3, 9, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "daily across spring forward",
+			expr: "30 7 * * *",
+			from: time.Date(2026, 3, 8, 1, 0, 0, 0, newYork), // 06:00 UTC
+			want: time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "daily across fall back runs once",
+			expr: "30 5 * * *",
+			from: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(newYork), // the first 01:30 local
+			want: time.Date(2026, 11, 2, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly across spring forward",
+			expr: "0 * * * *",
+			from: time.Date(2026, 3, 29, 1, 30, 0, 0, berlin), // 00:30 UTC
+			want: time.Date(2026, 3, 29, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly across fall back",
+			expr: "0 * * * *",
+			from: time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC).In(berlin), // the first 02:30 local
+			want: time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			got := schedule.Next(tt.from)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("Next(%v) location = %v, want UTC", tt.from, got.Location())
+			}
+		})
+	}
+}
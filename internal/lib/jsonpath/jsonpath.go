@@ -0,0 +1,81 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lookup returns the value located at path inside doc, which must be a value
+// decoded by encoding/json. Supported syntax is a subset of JSONPath:
+// "$.data.items[0].name". The leading "$" is optional.
+func Lookup(doc any, path string) (any, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]any:
+			value, exists := node[segment]
+			if !exists {
+				return nil, fmt.Errorf("key %q not found", segment)
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q", segment)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("array index %d out of range", index)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("cannot select %q from scalar value", segment)
+		}
+	}
+
+	return current, nil
+}
+
+// Validate checks that path can be parsed.
+func Validate(path string) error {
+	_, err := parse(path)
+	return err
+}
+
+func parse(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			segments = append(segments, name)
+		} else if rest == "" {
+			return nil, fmt.Errorf("empty segment in path %q", path)
+		}
+
+		for rest != "" {
+			index, tail, found := strings.Cut(rest, "]")
+			if !found || index == "" {
+				return nil, fmt.Errorf("unclosed bracket in path %q", path)
+			}
+			segments = append(segments, strings.Trim(index, `'"`))
+			rest = strings.TrimPrefix(tail, "[")
+			if tail != "" && !strings.HasPrefix(tail, "[") {
+				return nil, fmt.Errorf("unexpected %q in path %q", tail, path)
+			}
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,123 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testDoc = `{
+	"status": "ok",
+	"count": 2,
+	"data": {
+		"items": [
+			{"name": "first", "tags": ["a", "b"]},
+			{"name": "second", "tags": []}
+		],
+		"empty": null
+	},
+	"matrix": [[1, 2], [3, 4]]
+}`
+
+func TestLookup(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(testDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{name: "root key", path: "$.status", want: "ok"},
+		{name: "without dollar", path: "status", want: "ok"},
+		{name: "dollar without dot", path: "$status", want: "ok"},
+		{name: "number", path: "$.count", want: float64(2)},
+		{name: "null", path: "$.data.empty", want: nil},
+		{name: "nested key", path: "$.data.items[1].name", want: "second"},
+		{name: "nested index", path: "$.data.items[0].tags[1]", want: "b"},
+		{name: "chained indices", path: "$.matrix[1][0]", want: float64(3)},
+		{name: "quoted key", path: `$.data["items"][0]['name']`, want: "first"},
+		{name: "surrounding spaces", path: "  $.status  ", want: "ok"},
+		{name: "empty array", path: "$.data.items[1].tags", want: []any{}},
+		{name: "missing key", path: "$.missing", wantErr: true},
+		{name: "index out of range", path: "$.data.items[2]", wantErr: true},
+		{name: "negative index", path: "$.data.items[-1]", wantErr: true},
+		{name: "index of empty array", path: "$.data.items[1].tags[0]", wantErr: true},
+		{name: "key of array", path: "$.data.items.name", wantErr: true},
+		{name: "index of object", path: "$.data[0]", wantErr: true},
+		{name: "key of scalar", path: "$.status.length", wantErr: true},
+		{name: "key of null", path: "$.data.empty.value", wantErr: true},
+		{name: "unclosed bracket", path: "$.data.items[0", wantErr: true},
+		{name: "empty segment", path: "$.data..items", wantErr: true},
+		{name: "trailing dot", path: "$.data.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lookup(doc, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupRoot(t *testing.T) {
+	doc := []any{"a", "b"}
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{path: "", want: doc},
+		{path: "$", want: doc},
+		{path: "$.", want: doc},
+		{path: "$[1]", want: "b"},
+		{path: "[0]", want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Lookup(doc, tt.path)
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", tt.path, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "$.data.items[0].name"},
+		{path: "data.items"},
+		{path: "$"},
+		{path: "$.a[0][1]"},
+		{path: `$.a["b"]`},
+		{path: "$.a..b", wantErr: true},
+		{path: "$.a.", wantErr: true},
+		{path: "$.a[0", wantErr: true},
+		{path: "$.a[]", wantErr: true},
+		{path: "$.a[0]b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := Validate(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,10 +8,11 @@ import (
 	"shm/internal/config"
 	"shm/internal/db"
 	"shm/internal/lib/sl"
+	"time"
 )
 
 type DatabaseCreator = func() db.Database
-type BrokerCreator = func() broker.MessageBroker
+type BrokerCreator = func(config config.CommonConfig) broker.MessageBroker
 
 var drivers = map[string]DatabaseCreator{
 	"postgres": func() db.Database {
@@ -23,8 +24,8 @@ var drivers = map[string]DatabaseCreator{
 }
 
 var brokers = map[string]BrokerCreator{
-	"rabbitmq": func() broker.MessageBroker {
-		return connectToRabbitMQ(config.NewRabbitMQConfig())
+	"rabbitmq": func(common config.CommonConfig) broker.MessageBroker {
+		return connectToRabbitMQ(config.NewRabbitMQConfig(), common.BrokerTimeoutSec)
 	},
 }
 
@@ -61,19 +62,19 @@ func connectToPostgres(config config.PostgresConfig) *db.Postgres {
 	return db
 }
 
-func ConnectToMessageBroker(brokerName string) broker.MessageBroker {
-	brokerCreator, exists := brokers[brokerName]
+func ConnectToMessageBroker(config config.CommonConfig) broker.MessageBroker {
+	brokerCreator, exists := brokers[config.MessageBroker]
 	if !exists {
-		slog.Error("unknown message broker", slog.String("message_broker", brokerName))
+		slog.Error("unknown message broker", slog.String("message_broker", config.MessageBroker))
 		os.Exit(1)
 	}
-	return brokerCreator()
+	return brokerCreator(config)
 }
 
-func connectToRabbitMQ(config config.RabbitMQConfig) *broker.RabbitMQ {
+func connectToRabbitMQ(config config.RabbitMQConfig, timeout time.Duration) *broker.RabbitMQ {
 	slog.Info("connecting to RabbitMQ")
 	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", config.User, config.Pass, config.Host, config.Port)
-	broker, err := broker.NewRabbitMQ(url)
+	broker, err := broker.NewRabbitMQ(url, timeout)
 	if err != nil {
 		slog.Error("failed to connect to RabbitMQ", sl.Error(err))
 		os.Exit(1)
@@ -14,6 +14,15 @@ func CheckResult(result model.CheckResult) slog.Attr {
 		slog.String("url", result.Site.Url),
 		slog.Int64("code", result.Code.Int64),
 		slog.Int64("latency_ms", result.Latency.Int64),
+		slog.Bool("successful", result.Successful),
+		slog.String("failure_reason", result.FailureReason),
+		slog.Group("timings_ms",
+			slog.Int64("dns", result.Timings.DNSLookup.Int64),
+			slog.Int64("connect", result.Timings.Connect.Int64),
+			slog.Int64("tls", result.Timings.TLSHandshake.Int64),
+			slog.Int64("ttfb", result.Timings.FirstByte.Int64),
+			slog.Int64("transfer", result.Timings.Transfer.Int64),
+		),
 	)
 }
 
@@ -28,5 +37,6 @@ func Notification(notification model.Notification) slog.Attr {
 	return slog.Group("notification",
 		slog.String("url", notification.Url),
 		slog.String("message", notification.Message),
+		slog.String("event", string(notification.Event)),
 	)
 }
@@ -9,9 +9,27 @@ import (
 var (
 	defaultUrlScheme = "http://"
 	urlRegex         = regexp.MustCompile(`^(https?:\/\/)?([a-zA-Z0-9-]+\.)+[a-zA-Z]{2,63}/?$`)
+	tcpUrlRegex      = regexp.MustCompile(
+		`^tcp:\/\/(([a-zA-Z0-9-]+\.)*[a-zA-Z0-9-]+|\[[0-9a-fA-F:.]+\]):[0-9]{1,5}/?$`,
+	)
+	dnsUrlRegex       = regexp.MustCompile(`^dns:\/\/([a-zA-Z0-9_-]+\.)+[a-zA-Z]{2,63}/?$`)
+	heartbeatUrlRegex = regexp.MustCompile(`^heartbeat:\/\/[a-zA-Z0-9]+$`)
 )
 
 func ConvertToExpectedUrl(url string) (string, error) {
+	if strings.HasPrefix(url, "tcp://") {
+		return convertToExpectedTcpUrl(url)
+	}
+	if strings.HasPrefix(url, "dns://") {
+		return convertToExpectedDnsUrl(url)
+	}
+	if strings.HasPrefix(url, "heartbeat://") {
+		if !heartbeatUrlRegex.MatchString(url) {
+			return url, fmt.Errorf("invalid heartbeat url")
+		}
+		return url, nil
+	}
+
 	if !urlRegex.MatchString(url) {
 		return url, fmt.Errorf("invalid url")
 	}
@@ -23,3 +41,21 @@ func ConvertToExpectedUrl(url string) (string, error) {
 	url, _ = strings.CutSuffix(url, "/")
 	return url, nil
 }
+
+func convertToExpectedTcpUrl(url string) (string, error) {
+	if !tcpUrlRegex.MatchString(url) {
+		return url, fmt.Errorf("invalid tcp url")
+	}
+
+	url, _ = strings.CutSuffix(url, "/")
+	return url, nil
+}
+
+func convertToExpectedDnsUrl(url string) (string, error) {
+	if !dnsUrlRegex.MatchString(url) {
+		return url, fmt.Errorf("invalid dns url")
+	}
+
+	url, _ = strings.CutSuffix(url, "/")
+	return url, nil
+}
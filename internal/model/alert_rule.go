@@ -0,0 +1,245 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ConditionType string
+
+const (
+	ConditionAnd                 ConditionType = "and"
+	ConditionOr                  ConditionType = "or"
+	ConditionConsecutiveFailures ConditionType = "consecutive_failures"
+	ConditionFailureRatio        ConditionType = "failure_ratio"
+	ConditionLatencyPercentile   ConditionType = "latency_percentile"
+	ConditionStatusClass         ConditionType = "status_class"
+	ConditionAssertionFailed     ConditionType = "assertion_failed"
+)
+
+// Condition is a node of the condition tree of an alert rule. and/or nodes
+// combine Conditions, other nodes are evaluated on the last check results of
+// a site:
+//   - consecutive_failures: the last Count checks failed;
+//   - failure_ratio: at least Percent of checks in the last WindowMin
+//     minutes failed;
+//   - latency_percentile: the Percentile of latencies of successful checks in
+//     the last WindowMin minutes exceeds ThresholdMs;
+//   - status_class: the last Count checks returned status codes of
+//     StatusClass, e.g. 5 for 5xx;
+//   - assertion_failed: the last Count checks failed content assertions.
+type Condition struct {
+	Type        ConditionType `json:"type"`
+	Conditions  []Condition   `json:"conditions,omitempty"`
+	Count       int           `json:"count,omitempty"`
+	Percent     int           `json:"percent,omitempty"`
+	Percentile  int           `json:"percentile,omitempty"`
+	ThresholdMs int64         `json:"thresholdMs,omitempty"`
+	WindowMin   int           `json:"windowMin,omitempty"`
+	StatusClass int           `json:"statusClass,omitempty"`
+}
+
+// Normalize fills empty fields with default values and validates the
+// condition.
+func (c *Condition) Normalize() error {
+	switch c.Type {
+	case ConditionAnd, ConditionOr:
+		if len(c.Conditions) == 0 {
+			return fmt.Errorf("%s condition requires nested conditions", c.Type)
+		}
+		for i := range c.Conditions {
+			if err := c.Conditions[i].Normalize(); err != nil {
+				return err
+			}
+		}
+	case ConditionConsecutiveFailures, ConditionAssertionFailed:
+		if c.Count == 0 {
+			c.Count = 1
+		}
+		if c.Count < 0 {
+			return fmt.Errorf("count of %s condition must be positive", c.Type)
+		}
+	case ConditionFailureRatio:
+		if c.Percent <= 0 || c.Percent > 100 {
+			return fmt.Errorf("percent of failure_ratio condition must be in range 1-100")
+		}
+		if c.WindowMin <= 0 {
+			return fmt.Errorf("window of failure_ratio condition must be positive")
+		}
+	case ConditionLatencyPercentile:
+		if c.Percentile == 0 {
+			c.Percentile = 95
+		}
+		if c.Percentile < 0 || c.Percentile > 100 {
+			return fmt.Errorf("percentile of latency_percentile condition must be in range 1-100")
+		}
+		if c.ThresholdMs <= 0 {
+			return fmt.Errorf("threshold of latency_percentile condition must be positive")
+		}
+		if c.WindowMin <= 0 {
+			return fmt.Errorf("window of latency_percentile condition must be positive")
+		}
+	case ConditionStatusClass:
+		if c.StatusClass < 1 || c.StatusClass > 5 {
+			return fmt.Errorf("status class must be in range 1-5")
+		}
+		if c.Count == 0 {
+			c.Count = 1
+		}
+		if c.Count < 0 {
+			return fmt.Errorf("count of status_class condition must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown condition type %q", c.Type)
+	}
+	return nil
+}
+
+// Requirements returns the number of last results and the period before the
+// last result which are needed to evaluate the condition.
+func (c *Condition) Requirements() (int, time.Duration) {
+	count := c.Count
+	window := time.Duration(c.WindowMin) * time.Minute
+	for _, nested := range c.Conditions {
+		nestedCount, nestedWindow := nested.Requirements()
+		count = max(count, nestedCount)
+		window = max(window, nestedWindow)
+	}
+	return count, window
+}
+
+// Matches evaluates the condition on results ordered from the newest to the
+// oldest.
+func (c *Condition) Matches(results []CheckResult) bool {
+	switch c.Type {
+	case ConditionAnd:
+		for _, nested := range c.Conditions {
+			if !nested.Matches(results) {
+				return false
+			}
+		}
+		return true
+	case ConditionOr:
+		for _, nested := range c.Conditions {
+			if nested.Matches(results) {
+				return true
+			}
+		}
+		return false
+	case ConditionConsecutiveFailures:
+		return lastMatch(results, c.Count, func(result CheckResult) bool {
+			return !result.IsSuccessful()
+		})
+	case ConditionAssertionFailed:
+		return lastMatch(results, c.Count, func(result CheckResult) bool {
+			return result.AssertionFailed
+		})
+	case ConditionStatusClass:
+		return lastMatch(results, c.Count, func(result CheckResult) bool {
+			return result.Code.Valid && int(result.Code.Int64/100) == c.StatusClass
+		})
+	case ConditionFailureRatio:
+		window := resultsInWindow(results, c.WindowMin)
+		if len(window) == 0 {
+			return false
+		}
+		failed := 0
+		for _, result := range window {
+			if !result.IsSuccessful() {
+				failed++
+			}
+		}
+		return failed*100 >= c.Percent*len(window)
+	case ConditionLatencyPercentile:
+		latency, ok := latencyPercentile(resultsInWindow(results, c.WindowMin), c.Percentile)
+		return ok && latency > c.ThresholdMs
+	}
+	return false
+}
+
+func (c Condition) String() string {
+	switch c.Type {
+	case ConditionAnd, ConditionOr:
+		list := make([]string, len(c.Conditions))
+		for i, nested := range c.Conditions {
+			list[i] = nested.String()
+		}
+		return "(" + strings.Join(list, " "+string(c.Type)+" ") + ")"
+	case ConditionConsecutiveFailures:
+		return fmt.Sprintf("%d consecutive failed checks", c.Count)
+	case ConditionAssertionFailed:
+		return fmt.Sprintf("%d consecutive failed assertions", c.Count)
+	case ConditionStatusClass:
+		return fmt.Sprintf("%d consecutive %dxx status codes", c.Count, c.StatusClass)
+	case ConditionFailureRatio:
+		return fmt.Sprintf("%d%% of checks failed in %d minutes", c.Percent, c.WindowMin)
+	case ConditionLatencyPercentile:
+		return fmt.Sprintf(
+			"p%d latency above %d ms in %d minutes",
+			c.Percentile, c.ThresholdMs, c.WindowMin,
+		)
+	}
+	return string(c.Type)
+}
+
+func (c Condition) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *Condition) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+// lastMatch reports whether the last count results satisfy match.
+func lastMatch(results []CheckResult, count int, match func(CheckResult) bool) bool {
+	if len(results) < count {
+		return false
+	}
+	for _, result := range results[:count] {
+		if !match(result) {
+			return false
+		}
+	}
+	return true
+}
+
+// resultsInWindow returns results checked in the last minutes before the
+// newest result.
+func resultsInWindow(results []CheckResult, minutes int) []CheckResult {
+	if len(results) == 0 {
+		return nil
+	}
+	since := results[0].Time.Add(-time.Duration(minutes) * time.Minute)
+	for i, result := range results {
+		if result.Time.Before(since) {
+			return results[:i]
+		}
+	}
+	return results
+}
+
+// AlertRule decides when a site is considered down. A rule belongs either to
+// a site or to a tag, rules of the site take precedence over rules of its
+// tags.
+type AlertRule struct {
+	Id        int64     `json:"id"`
+	SiteId    int64     `json:"siteId,omitempty"`
+	Tag       string    `json:"tag,omitempty"`
+	Condition Condition `json:"condition"`
+}
+
+// Normalize fills empty fields with default values and validates the rule.
+func (r *AlertRule) Normalize() error {
+	r.Tag = strings.TrimSpace(r.Tag)
+	if (r.SiteId != 0) == (r.Tag != "") {
+		return fmt.Errorf("rule must belong either to a site or to a tag")
+	}
+	return r.Condition.Normalize()
+}
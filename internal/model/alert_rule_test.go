@@ -0,0 +1,455 @@
+package model
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var ruleTestNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// passed returns a successful result checked minutesAgo before now.
+func passed(minutesAgo int, latencyMs int64) CheckResult {
+	return CheckResult{
+		Time:       ruleTestNow.Add(-time.Duration(minutesAgo) * time.Minute),
+		Latency:    sql.NullInt64{Int64: latencyMs, Valid: true},
+		Code:       sql.NullInt64{Int64: 200, Valid: true},
+		Successful: true,
+	}
+}
+
+// failed returns a failed result checked minutesAgo before now, code 0 means
+// that no response was received.
+func failed(minutesAgo int, code int64) CheckResult {
+	return CheckResult{
+		Time: ruleTestNow.Add(-time.Duration(minutesAgo) * time.Minute),
+		Code: sql.NullInt64{Int64: code, Valid: code != 0},
+	}
+}
+
+func assertionFailed(minutesAgo int) CheckResult {
+	result := failed(minutesAgo, 200)
+	result.AssertionFailed = true
+	return result
+}
+
+func TestConditionNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition Condition
+		want      Condition
+		wantErr   bool
+	}{
+		{
+			name:      "consecutive failures default count",
+			condition: Condition{Type: ConditionConsecutiveFailures},
+			want:      Condition{Type: ConditionConsecutiveFailures, Count: 1},
+		},
+		{
+			name:      "consecutive failures",
+			condition: Condition{Type: ConditionConsecutiveFailures, Count: 3},
+			want:      Condition{Type: ConditionConsecutiveFailures, Count: 3},
+		},
+		{
+			name:      "negative count",
+			condition: Condition{Type: ConditionConsecutiveFailures, Count: -1},
+			wantErr:   true,
+		},
+		{
+			name:      "assertion failed default count",
+			condition: Condition{Type: ConditionAssertionFailed},
+			want:      Condition{Type: ConditionAssertionFailed, Count: 1},
+		},
+		{
+			name:      "failure ratio",
+			condition: Condition{Type: ConditionFailureRatio, Percent: 100, WindowMin: 1},
+			want:      Condition{Type: ConditionFailureRatio, Percent: 100, WindowMin: 1},
+		},
+		{
+			name:      "failure ratio without percent",
+			condition: Condition{Type: ConditionFailureRatio, WindowMin: 10},
+			wantErr:   true,
+		},
+		{
+			name:      "failure ratio above 100 percent",
+			condition: Condition{Type: ConditionFailureRatio, Percent: 101, WindowMin: 10},
+			wantErr:   true,
+		},
+		{
+			name:      "failure ratio without window",
+			condition: Condition{Type: ConditionFailureRatio, Percent: 50},
+			wantErr:   true,
+		},
+		{
+			name:      "latency percentile default",
+			condition: Condition{Type: ConditionLatencyPercentile, ThresholdMs: 500, WindowMin: 5},
+			want:      Condition{Type: ConditionLatencyPercentile, Percentile: 95, ThresholdMs: 500, WindowMin: 5},
+		},
+		{
+			name:      "latency percentile above 100",
+			condition: Condition{Type: ConditionLatencyPercentile, Percentile: 101, ThresholdMs: 500, WindowMin: 5},
+			wantErr:   true,
+		},
+		{
+			name:      "latency percentile without threshold",
+			condition: Condition{Type: ConditionLatencyPercentile, WindowMin: 5},
+			wantErr:   true,
+		},
+		{
+			name:      "latency percentile without window",
+			condition: Condition{Type: ConditionLatencyPercentile, ThresholdMs: 500},
+			wantErr:   true,
+		},
+		{
+			name:      "status class default count",
+			condition: Condition{Type: ConditionStatusClass, StatusClass: 5},
+			want:      Condition{Type: ConditionStatusClass, StatusClass: 5, Count: 1},
+		},
+		{
+			name:      "status class out of range",
+			condition: Condition{Type: ConditionStatusClass, StatusClass: 6},
+			wantErr:   true,
+		},
+		{
+			name:      "status class missing",
+			condition: Condition{Type: ConditionStatusClass},
+			wantErr:   true,
+		},
+		{
+			name: "nested defaults",
+			condition: Condition{Type: ConditionAnd, Conditions: []Condition{
+				{Type: ConditionConsecutiveFailures},
+				{Type: ConditionOr, Conditions: []Condition{
+					{Type: ConditionStatusClass, StatusClass: 5},
+					{Type: ConditionAssertionFailed, Count: 2},
+				}},
+			}},
+			want: Condition{Type: ConditionAnd, Conditions: []Condition{
+				{Type: ConditionConsecutiveFailures, Count: 1},
+				{Type: ConditionOr, Conditions: []Condition{
+					{Type: ConditionStatusClass, StatusClass: 5, Count: 1},
+					{Type: ConditionAssertionFailed, Count: 2},
+				}},
+			}},
+		},
+		{
+			name:      "empty and",
+			condition: Condition{Type: ConditionAnd},
+			wantErr:   true,
+		},
+		{
+			name:      "empty or",
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{}},
+			wantErr:   true,
+		},
+		{
+			name: "invalid deeply nested condition",
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{
+				{Type: ConditionConsecutiveFailures},
+				{Type: ConditionAnd, Conditions: []Condition{
+					{Type: ConditionFailureRatio, Percent: 50},
+				}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:      "unknown type",
+			condition: Condition{Type: "not"},
+			wantErr:   true,
+		},
+		{
+			name:      "empty type",
+			condition: Condition{},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := tt.condition
+			err := condition.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(condition, tt.want) {
+				t.Errorf("Normalize() = %+v, want %+v", condition, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	threeFailures := Condition{Type: ConditionConsecutiveFailures, Count: 3}
+	serverErrors := Condition{Type: ConditionStatusClass, StatusClass: 5, Count: 2}
+	halfFailed := Condition{Type: ConditionFailureRatio, Percent: 50, WindowMin: 10}
+	slow := Condition{Type: ConditionLatencyPercentile, Percentile: 90, ThresholdMs: 500, WindowMin: 10}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		results   []CheckResult
+		want      bool
+	}{
+		{
+			name:      "consecutive failures",
+			condition: threeFailures,
+			results:   []CheckResult{failed(0, 0), failed(1, 0), failed(2, 0), passed(3, 100)},
+			want:      true,
+		},
+		{
+			name:      "failures interrupted by success",
+			condition: threeFailures,
+			results:   []CheckResult{failed(0, 0), passed(1, 100), failed(2, 0), failed(3, 0)},
+		},
+		{
+			name:      "not enough results",
+			condition: threeFailures,
+			results:   []CheckResult{failed(0, 0), failed(1, 0)},
+		},
+		{
+			name:      "no results",
+			condition: Condition{Type: ConditionConsecutiveFailures, Count: 1},
+		},
+		{
+			name:      "status class",
+			condition: serverErrors,
+			results:   []CheckResult{failed(0, 503), failed(1, 500)},
+			want:      true,
+		},
+		{
+			name:      "status class of other class",
+			condition: serverErrors,
+			results:   []CheckResult{failed(0, 503), failed(1, 404)},
+		},
+		{
+			name:      "status class without response",
+			condition: serverErrors,
+			results:   []CheckResult{failed(0, 503), failed(1, 0)},
+		},
+		{
+			name:      "assertion failed",
+			condition: Condition{Type: ConditionAssertionFailed, Count: 2},
+			results:   []CheckResult{assertionFailed(0), assertionFailed(1), passed(2, 100)},
+			want:      true,
+		},
+		{
+			name:      "assertion failed after other failure",
+			condition: Condition{Type: ConditionAssertionFailed, Count: 2},
+			results:   []CheckResult{assertionFailed(0), failed(1, 0)},
+		},
+		{
+			name:      "failure ratio reached",
+			condition: halfFailed,
+			results:   []CheckResult{failed(0, 0), passed(3, 100), failed(6, 0), passed(9, 100)},
+			want:      true,
+		},
+		{
+			name:      "failure ratio below threshold",
+			condition: halfFailed,
+			results:   []CheckResult{failed(0, 0), passed(3, 100), passed(6, 100)},
+		},
+		{
+			name:      "failure ratio ignores results outside of window",
+			condition: halfFailed,
+			results:   []CheckResult{passed(0, 100), passed(5, 100), failed(11, 0), failed(12, 0), failed(13, 0)},
+		},
+		{
+			name:      "window is counted from the newest result",
+			condition: halfFailed,
+			results:   []CheckResult{failed(60, 0), passed(65, 100), failed(70, 0)},
+			want:      true,
+		},
+		{
+			name:      "window boundary is inclusive",
+			condition: halfFailed,
+			results:   []CheckResult{passed(0, 100), failed(10, 0)},
+			want:      true,
+		},
+		{
+			name:      "latency percentile exceeded",
+			condition: slow,
+			results: []CheckResult{
+				passed(0, 900), passed(1, 100), passed(2, 100), passed(3, 100), passed(4, 100),
+				passed(5, 100), passed(6, 100), passed(7, 100), passed(8, 100), passed(9, 800),
+			},
+			want: true,
+		},
+		{
+			name:      "latency percentile within threshold",
+			condition: slow,
+			results: []CheckResult{
+				passed(0, 900), passed(1, 100), passed(2, 100), passed(3, 100), passed(4, 100),
+				passed(5, 100), passed(6, 100), passed(7, 100), passed(8, 100), passed(9, 100),
+			},
+		},
+		{
+			name:      "latency percentile equal to threshold",
+			condition: slow,
+			results:   []CheckResult{passed(0, 500)},
+		},
+		{
+			name:      "latency percentile ignores failed checks",
+			condition: slow,
+			results:   []CheckResult{failed(0, 0), failed(1, 0)},
+		},
+		{
+			name:      "and of matching conditions",
+			condition: Condition{Type: ConditionAnd, Conditions: []Condition{threeFailures, serverErrors}},
+			results:   []CheckResult{failed(0, 500), failed(1, 502), failed(2, 0)},
+			want:      true,
+		},
+		{
+			name:      "and with one condition not matching",
+			condition: Condition{Type: ConditionAnd, Conditions: []Condition{threeFailures, serverErrors}},
+			results:   []CheckResult{failed(0, 500), failed(1, 0), failed(2, 0)},
+		},
+		{
+			name:      "or with one condition matching",
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{threeFailures, serverErrors}},
+			results:   []CheckResult{failed(0, 500), failed(1, 502), passed(2, 100)},
+			want:      true,
+		},
+		{
+			name:      "or without matching conditions",
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{threeFailures, serverErrors}},
+			results:   []CheckResult{failed(0, 500), passed(1, 100)},
+		},
+		{
+			name: "nested tree",
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{
+				threeFailures,
+				{Type: ConditionAnd, Conditions: []Condition{halfFailed, serverErrors}},
+			}},
+			results: []CheckResult{failed(0, 503), failed(4, 500), passed(8, 100)},
+			want:    true,
+		},
+		{
+			name: "nested tree not matching",
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{
+				threeFailures,
+				{Type: ConditionAnd, Conditions: []Condition{halfFailed, serverErrors}},
+			}},
+			results: []CheckResult{failed(0, 503), failed(4, 0), passed(8, 100)},
+		},
+		{
+			name:      "unknown type",
+			condition: Condition{Type: "not"},
+			results:   []CheckResult{failed(0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Matches(tt.results); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionRequirements(t *testing.T) {
+	tests := []struct {
+		name       string
+		condition  Condition
+		wantCount  int
+		wantWindow time.Duration
+	}{
+		{
+			name:      "count",
+			condition: Condition{Type: ConditionConsecutiveFailures, Count: 3},
+			wantCount: 3,
+		},
+		{
+			name:       "window",
+			condition:  Condition{Type: ConditionFailureRatio, Percent: 50, WindowMin: 15},
+			wantWindow: 15 * time.Minute,
+		},
+		{
+			name: "largest of nested conditions",
+			condition: Condition{Type: ConditionAnd, Conditions: []Condition{
+				{Type: ConditionConsecutiveFailures, Count: 2},
+				{Type: ConditionOr, Conditions: []Condition{
+					{Type: ConditionStatusClass, StatusClass: 5, Count: 4},
+					{Type: ConditionLatencyPercentile, Percentile: 95, ThresholdMs: 300, WindowMin: 30},
+				}},
+				{Type: ConditionFailureRatio, Percent: 50, WindowMin: 10},
+			}},
+			wantCount:  4,
+			wantWindow: 30 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, window := tt.condition.Requirements()
+			if count != tt.wantCount || window != tt.wantWindow {
+				t.Errorf("Requirements() = %d, %v, want %d, %v", count, window, tt.wantCount, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestConditionString(t *testing.T) {
+	tests := []struct {
+		condition Condition
+		want      string
+	}{
+		{
+			condition: Condition{Type: ConditionConsecutiveFailures, Count: 3},
+			want:      "3 consecutive failed checks",
+		},
+		{
+			condition: Condition{Type: ConditionFailureRatio, Percent: 50, WindowMin: 10},
+			want:      "50% of checks failed in 10 minutes",
+		},
+		{
+			condition: Condition{Type: ConditionOr, Conditions: []Condition{
+				{Type: ConditionStatusClass, StatusClass: 5, Count: 2},
+				{Type: ConditionAnd, Conditions: []Condition{
+					{Type: ConditionAssertionFailed, Count: 1},
+					{Type: ConditionLatencyPercentile, Percentile: 99, ThresholdMs: 800, WindowMin: 5},
+				}},
+			}},
+			want: "(2 consecutive 5xx status codes or " +
+				"(1 consecutive failed assertions and p99 latency above 800 ms in 5 minutes))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.condition.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertRuleNormalize(t *testing.T) {
+	condition := Condition{Type: ConditionConsecutiveFailures}
+
+	tests := []struct {
+		name    string
+		rule    AlertRule
+		wantErr bool
+	}{
+		{name: "site rule", rule: AlertRule{SiteId: 1, Condition: condition}},
+		{name: "tag rule", rule: AlertRule{Tag: " prod ", Condition: condition}},
+		{name: "site and tag", rule: AlertRule{SiteId: 1, Tag: "prod", Condition: condition}, wantErr: true},
+		{name: "neither site nor tag", rule: AlertRule{Tag: "  ", Condition: condition}, wantErr: true},
+		{name: "invalid condition", rule: AlertRule{SiteId: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			err := rule.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && rule.Tag != "" && rule.Tag != "prod" {
+				t.Errorf("Tag = %q, want trimmed", rule.Tag)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"shm/internal/lib/jsonpath"
+)
+
+type AssertionType string
+
+const (
+	AssertionContains    AssertionType = "contains"
+	AssertionNotContains AssertionType = "not_contains"
+	AssertionRegex       AssertionType = "regex"
+	AssertionJSONPath    AssertionType = "json_path"
+)
+
+// Assertion is a check of the response body. For json_path assertions Path
+// selects the value which must be equal to Value.
+type Assertion struct {
+	Type  AssertionType `json:"type"`
+	Path  string        `json:"path,omitempty"`
+	Value string        `json:"value"`
+}
+
+func (a Assertion) Validate() error {
+	switch a.Type {
+	case AssertionContains, AssertionNotContains:
+		if a.Value == "" {
+			return fmt.Errorf("keyword of %s assertion is empty", a.Type)
+		}
+	case AssertionRegex:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", a.Value, err)
+		}
+	case AssertionJSONPath:
+		if a.Path == "" {
+			return fmt.Errorf("path of json_path assertion is empty")
+		}
+		if err := jsonpath.Validate(a.Path); err != nil {
+			return fmt.Errorf("invalid json path: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	return nil
+}
+
+// Assertions are stored in the database as a JSON array.
+type Assertions []Assertion
+
+func (a Assertions) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Assertions) Scan(src any) error {
+	return scanJSON(src, a)
+}
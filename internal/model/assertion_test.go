@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestAssertionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion Assertion
+		wantErr   bool
+	}{
+		{name: "contains", assertion: Assertion{Type: AssertionContains, Value: "ok"}},
+		{name: "empty keyword", assertion: Assertion{Type: AssertionContains}, wantErr: true},
+		{name: "empty excluded keyword", assertion: Assertion{Type: AssertionNotContains}, wantErr: true},
+		{name: "regex", assertion: Assertion{Type: AssertionRegex, Value: `^\{.*\}$`}},
+		{name: "invalid regex", assertion: Assertion{Type: AssertionRegex, Value: "[a-"}, wantErr: true},
+		{name: "json path", assertion: Assertion{Type: AssertionJSONPath, Path: "$.data[0].id", Value: "1"}},
+		{name: "json path with empty value", assertion: Assertion{Type: AssertionJSONPath, Path: "$.name"}},
+		{name: "empty json path", assertion: Assertion{Type: AssertionJSONPath, Value: "1"}, wantErr: true},
+		{name: "invalid json path", assertion: Assertion{Type: AssertionJSONPath, Path: "$.data[0", Value: "1"}, wantErr: true},
+		{name: "unknown type", assertion: Assertion{Type: "xpath", Value: "/a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.assertion.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
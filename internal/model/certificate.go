@@ -0,0 +1,27 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Certificate describes the peer certificate chain received during the last
+// HTTPS check of a site.
+type Certificate struct {
+	SiteId        int64         `json:"siteId"`
+	Subject       string        `json:"subject"`
+	Issuer        string        `json:"issuer"`
+	SANs          Strings       `json:"sans"`
+	NotBefore     time.Time     `json:"notBefore"`
+	ExpiresAt     time.Time     `json:"expiresAt"`
+	HostnameValid bool          `json:"hostnameValid"`
+	ChainValid    bool          `json:"chainValid"`
+	ChainError    string        `json:"chainError,omitempty"`
+	CheckedAt     time.Time     `json:"checkedAt"`
+	WarnedDays    sql.NullInt64 `json:"-"`
+}
+
+// DaysLeft returns the number of whole days before the certificate expires.
+func (c *Certificate) DaysLeft(now time.Time) int {
+	return int(c.ExpiresAt.Sub(now).Hours() / 24)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertificateDaysLeft(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      int
+	}{
+		{name: "exactly 30 days", expiresAt: now.Add(30 * 24 * time.Hour), want: 30},
+		{name: "partial day is truncated", expiresAt: now.Add(30*24*time.Hour - time.Second), want: 29},
+		{name: "less than a day", expiresAt: now.Add(23 * time.Hour), want: 0},
+		{name: "expires now", expiresAt: now, want: 0},
+		{name: "expired less than a day ago", expiresAt: now.Add(-23 * time.Hour), want: 0},
+		{name: "expired two days ago", expiresAt: now.Add(-48 * time.Hour), want: -2},
+		{
+			name:      "other time zone",
+			expiresAt: time.Date(2026, 3, 8, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)),
+			want:      6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &Certificate{ExpiresAt: tt.expiresAt}
+			if got := cert.DaysLeft(now); got != tt.want {
+				t.Errorf("DaysLeft() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
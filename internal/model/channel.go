@@ -0,0 +1,137 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ChannelType string
+
+const (
+	ChannelTypeTelegram  ChannelType = "telegram"
+	ChannelTypeWebhook   ChannelType = "webhook"
+	ChannelTypeEmail     ChannelType = "email"
+	ChannelTypeSlack     ChannelType = "slack"
+	ChannelTypeDiscord   ChannelType = "discord"
+	ChannelTypeTeams     ChannelType = "teams"
+	ChannelTypePagerDuty ChannelType = "pagerduty"
+	ChannelTypeOpsgenie  ChannelType = "opsgenie"
+	ChannelTypeNtfy      ChannelType = "ntfy"
+	ChannelTypeGotify    ChannelType = "gotify"
+	ChannelTypeMatrix    ChannelType = "matrix"
+)
+
+var channelTypes = map[ChannelType]struct{}{
+	ChannelTypeTelegram:  {},
+	ChannelTypeWebhook:   {},
+	ChannelTypeEmail:     {},
+	ChannelTypeSlack:     {},
+	ChannelTypeDiscord:   {},
+	ChannelTypeTeams:     {},
+	ChannelTypePagerDuty: {},
+	ChannelTypeOpsgenie:  {},
+	ChannelTypeNtfy:      {},
+	ChannelTypeGotify:    {},
+	ChannelTypeMatrix:    {},
+}
+
+// ChannelConfig is the raw JSON config of a channel, its schema depends on
+// the type of the channel. It is stored in the database as text.
+type ChannelConfig []byte
+
+func (c ChannelConfig) MarshalJSON() ([]byte, error) {
+	if len(c) == 0 {
+		return []byte("{}"), nil
+	}
+	return c, nil
+}
+
+func (c *ChannelConfig) UnmarshalJSON(data []byte) error {
+	*c = append((*c)[:0], data...)
+	return nil
+}
+
+func (c ChannelConfig) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return "{}", nil
+	}
+	return string(c), nil
+}
+
+func (c *ChannelConfig) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+	case string:
+		*c = ChannelConfig(v)
+	case []byte:
+		*c = append(ChannelConfig(nil), v...)
+	default:
+		return fmt.Errorf("unsupported type %T for channel config", src)
+	}
+	return nil
+}
+
+// Channel is a configured destination of notifications. Channels receive
+// notifications of the sites they are subscribed to.
+type Channel struct {
+	Id     int64         `json:"id"`
+	Name   string        `json:"name"`
+	Type   ChannelType   `json:"type"`
+	Config ChannelConfig `json:"config"`
+}
+
+// Normalize validates the channel. The config is only checked to be a JSON
+// object, its fields are validated by the notifier of the channel type.
+func (c *Channel) Normalize() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("channel requires a name")
+	}
+	if _, ok := channelTypes[c.Type]; !ok {
+		return fmt.Errorf("unknown channel type %q", c.Type)
+	}
+
+	if len(bytes.TrimSpace(c.Config)) == 0 {
+		c.Config = ChannelConfig("{}")
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(c.Config, &fields); err != nil || fields == nil {
+		return fmt.Errorf("channel config must be a JSON object")
+	}
+	return nil
+}
+
+// Recipient returns the key of the destination of the channel. Channels
+// without id, such as chats and email recipients subscribed to sites, are
+// identified by their type and config.
+func (c Channel) Recipient() string {
+	if c.Id != 0 {
+		return fmt.Sprintf("channel:%d", c.Id)
+	}
+	return string(c.Type) + ":" + string(c.Config)
+}
+
+// TelegramChannel returns the channel of a chat subscribed to sites with
+// the telegram bot.
+func TelegramChannel(chatId int64) Channel {
+	return Channel{
+		Name:   fmt.Sprintf("chat %d", chatId),
+		Type:   ChannelTypeTelegram,
+		Config: ChannelConfig(fmt.Sprintf(`{"chatId":%d}`, chatId)),
+	}
+}
+
+// EmailChannel returns the channel of an email recipient subscribed to
+// sites.
+func EmailChannel(email string) Channel {
+	config, _ := json.Marshal(map[string][]string{"to": {email}})
+	return Channel{
+		Name:   email,
+		Type:   ChannelTypeEmail,
+		Config: ChannelConfig(config),
+	}
+}
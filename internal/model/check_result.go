@@ -6,12 +6,22 @@ import (
 )
 
 type CheckResult struct {
-	Site    Site          `json:"site"`
-	Time    time.Time     `json:"time"`
-	Latency sql.NullInt64 `json:"latency"`
-	Code    sql.NullInt64 `json:"code"`
+	Id              int64         `json:"id"`
+	Site            Site          `json:"site"`
+	Time            time.Time     `json:"time"`
+	Latency         sql.NullInt64 `json:"latency"`
+	Code            sql.NullInt64 `json:"code"`
+	Timings         Timings       `json:"timings"`
+	Successful      bool          `json:"successful"`
+	FailureReason   string        `json:"failureReason,omitempty"`
+	AssertionFailed bool          `json:"assertionFailed,omitempty"`
+	Certificate     *Certificate  `json:"certificate,omitempty"`
+	Answers         Strings       `json:"answers,omitempty"`
+	// ScheduledAt is the run of the scheduler the result was checked for,
+	// so a run checked again after a failure has a single result.
+	ScheduledAt sql.NullTime `json:"scheduledAt"`
 }
 
 func (c *CheckResult) IsSuccessful() bool {
-	return c.Code.Valid && c.Code.Int64 == 200
+	return c.Successful
 }
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+type DNSMatch string
+
+const (
+	DNSMatchEquals   DNSMatch = "equals"
+	DNSMatchContains DNSMatch = "contains"
+)
+
+var dnsRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "NS"}
+
+// DNSSpec describes a DNS check. Resolver is host:port of the name server,
+// system resolver is used if it is empty. If Expected is not empty, answers
+// must be equal to it or contain all of its values depending on Match.
+type DNSSpec struct {
+	RecordType string   `json:"recordType"`
+	Resolver   string   `json:"resolver"`
+	Expected   Strings  `json:"expected"`
+	Match      DNSMatch `json:"match"`
+}
+
+// Normalize fills empty fields with default values and validates the spec.
+func (d *DNSSpec) Normalize() error {
+	d.RecordType = strings.ToUpper(strings.TrimSpace(d.RecordType))
+	if d.RecordType == "" {
+		d.RecordType = "A"
+	}
+	if !slices.Contains(dnsRecordTypes, d.RecordType) {
+		return fmt.Errorf("unsupported dns record type %q", d.RecordType)
+	}
+
+	if d.Resolver != "" {
+		if _, _, err := net.SplitHostPort(d.Resolver); err != nil {
+			d.Resolver = net.JoinHostPort(d.Resolver, "53")
+		}
+	}
+
+	if d.Expected == nil {
+		d.Expected = Strings{}
+	}
+	for i, value := range d.Expected {
+		d.Expected[i] = normalizeAnswer(d.RecordType, value)
+	}
+
+	if d.Match == "" {
+		d.Match = DNSMatchEquals
+	}
+	if d.Match != DNSMatchEquals && d.Match != DNSMatchContains {
+		return fmt.Errorf("unknown dns match %q", d.Match)
+	}
+
+	return nil
+}
+
+// normalizeAnswer brings expected value to the form in which answers of the
+// record type are reported by the checker.
+func normalizeAnswer(recordType, value string) string {
+	value = strings.TrimSpace(value)
+	switch recordType {
+	case "A", "AAAA":
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	case "CNAME", "MX", "NS":
+		return strings.ToLower(strings.TrimSuffix(value, "."))
+	}
+	return value
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDNSSpecNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    DNSSpec
+		want    DNSSpec
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			spec: DNSSpec{},
+			want: DNSSpec{RecordType: "A", Expected: Strings{}, Match: DNSMatchEquals},
+		},
+		{
+			name: "record type case and spaces",
+			spec: DNSSpec{RecordType: " aaaa ", Match: DNSMatchContains},
+			want: DNSSpec{RecordType: "AAAA", Expected: Strings{}, Match: DNSMatchContains},
+		},
+		{
+			name: "resolver without port",
+			spec: DNSSpec{Resolver: "1.1.1.1"},
+			want: DNSSpec{RecordType: "A", Resolver: "1.1.1.1:53", Expected: Strings{}, Match: DNSMatchEquals},
+		},
+		{
+			name: "resolver with port",
+			spec: DNSSpec{Resolver: "ns.example.com:5353"},
+			want: DNSSpec{RecordType: "A", Resolver: "ns.example.com:5353", Expected: Strings{}, Match: DNSMatchEquals},
+		},
+		{
+			name: "ipv6 resolver without port",
+			spec: DNSSpec{Resolver: "2606:4700:4700::1111"},
+			want: DNSSpec{RecordType: "A", Resolver: "[2606:4700:4700::1111]:53", Expected: Strings{}, Match: DNSMatchEquals},
+		},
+		{
+			name: "ipv4 answers",
+			spec: DNSSpec{Expected: Strings{" 192.0.2.1 ", "not an ip"}},
+			want: DNSSpec{RecordType: "A", Expected: Strings{"192.0.2.1", "not an ip"}, Match: DNSMatchEquals},
+		},
+		{
+			name: "ipv6 answers",
+			spec: DNSSpec{RecordType: "AAAA", Expected: Strings{"2001:DB8:0:0:0:0:0:1"}},
+			want: DNSSpec{RecordType: "AAAA", Expected: Strings{"2001:db8::1"}, Match: DNSMatchEquals},
+		},
+		{
+			name: "host names",
+			spec: DNSSpec{RecordType: "CNAME", Expected: Strings{"Edge.Example.COM."}},
+			want: DNSSpec{RecordType: "CNAME", Expected: Strings{"edge.example.com"}, Match: DNSMatchEquals},
+		},
+		{
+			name: "mail exchangers",
+			spec: DNSSpec{RecordType: "MX", Expected: Strings{"10 MX1.Example.com."}},
+			want: DNSSpec{RecordType: "MX", Expected: Strings{"10 mx1.example.com"}, Match: DNSMatchEquals},
+		},
+		{
+			name: "name servers",
+			spec: DNSSpec{RecordType: "ns", Expected: Strings{"NS1.example.com"}},
+			want: DNSSpec{RecordType: "NS", Expected: Strings{"ns1.example.com"}, Match: DNSMatchEquals},
+		},
+		{
+			name: "text records keep case",
+			spec: DNSSpec{RecordType: "TXT", Expected: Strings{" v=spf1 -all "}},
+			want: DNSSpec{RecordType: "TXT", Expected: Strings{"v=spf1 -all"}, Match: DNSMatchEquals},
+		},
+		{name: "unsupported record type", spec: DNSSpec{RecordType: "SRV"}, wantErr: true},
+		{name: "unknown match", spec: DNSSpec{Match: "prefix"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			err := spec.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(spec, tt.want) {
+				t.Errorf("Normalize() = %+v, want %+v", spec, tt.want)
+			}
+		})
+	}
+}
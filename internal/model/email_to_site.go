@@ -0,0 +1,24 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// EmailToSite subscribes an email recipient to notifications of a site.
+type EmailToSite struct {
+	Email  string `json:"email"`
+	SiteId int64  `json:"siteId"`
+}
+
+// Normalize validates the address and keeps only its email part, so that
+// "Name <user@example.com>" and "user@example.com" are the same recipient.
+func (e *EmailToSite) Normalize() error {
+	address, err := mail.ParseAddress(strings.TrimSpace(e.Email))
+	if err != nil {
+		return fmt.Errorf("invalid email address")
+	}
+	e.Email = strings.ToLower(address.Address)
+	return nil
+}
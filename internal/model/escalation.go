@@ -0,0 +1,74 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EscalationStep notifies a chat or a channel once an incident stays
+// unacknowledged for DelayMin minutes after it was opened.
+type EscalationStep struct {
+	DelayMin  int   `json:"delayMin"`
+	ChatId    int64 `json:"chatId,omitempty"`
+	ChannelId int64 `json:"channelId,omitempty"`
+}
+
+func (e EscalationStep) Validate() error {
+	if e.DelayMin < 0 {
+		return fmt.Errorf("delay of escalation step must not be negative")
+	}
+	if (e.ChatId != 0) == (e.ChannelId != 0) {
+		return fmt.Errorf("escalation step must notify either a chat or a channel")
+	}
+	return nil
+}
+
+// EscalationSteps are stored in the database as a JSON array.
+type EscalationSteps []EscalationStep
+
+func (e EscalationSteps) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (e *EscalationSteps) Scan(src any) error {
+	return scanJSON(src, e)
+}
+
+// EscalationPolicy describes who is notified while a down incident of a site
+// is not acknowledged. A policy belongs either to a site or to a tag, the
+// policy of the site takes precedence over policies of its tags.
+type EscalationPolicy struct {
+	Id     int64           `json:"id"`
+	SiteId int64           `json:"siteId,omitempty"`
+	Tag    string          `json:"tag,omitempty"`
+	Steps  EscalationSteps `json:"steps"`
+}
+
+// Normalize validates the policy. Steps must be ordered by delay.
+func (p *EscalationPolicy) Normalize() error {
+	p.Tag = strings.TrimSpace(p.Tag)
+	if (p.SiteId != 0) == (p.Tag != "") {
+		return fmt.Errorf("escalation policy must belong either to a site or to a tag")
+	}
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("escalation policy requires at least one step")
+	}
+	for i, step := range p.Steps {
+		if err := step.Validate(); err != nil {
+			return err
+		}
+		if i > 0 && step.DelayMin < p.Steps[i-1].DelayMin {
+			return fmt.Errorf("escalation steps must be ordered by delay")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// HeartbeatSpec describes a push based monitor. The monitored job is expected
+// to ping the server at least every PeriodSec seconds, GraceSec seconds of
+// delay are tolerated.
+type HeartbeatSpec struct {
+	PeriodSec  int64        `json:"periodSec"`
+	GraceSec   int64        `json:"graceSec"`
+	LastPingAt sql.NullTime `json:"lastPingAt"`
+}
+
+// Normalize validates the spec. Time of creation counts as the first ping, so
+// a new heartbeat is not reported as missed before its first period ends.
+func (h *HeartbeatSpec) Normalize() error {
+	if h.PeriodSec <= 0 {
+		return fmt.Errorf("period of heartbeat must be positive")
+	}
+	if h.GraceSec < 0 {
+		return fmt.Errorf("grace time of heartbeat must not be negative")
+	}
+	if !h.LastPingAt.Valid {
+		h.LastPingAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+// Deadline returns the time after which the heartbeat is considered missed.
+func (h *HeartbeatSpec) Deadline() time.Time {
+	return h.LastPingAt.Time.Add(time.Duration(h.PeriodSec+h.GraceSec) * time.Second)
+}
+
+// NewHeartbeatUrl returns url with a new random token.
+func NewHeartbeatUrl() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate heartbeat token: %w", err)
+	}
+	return HeartbeatUrl(hex.EncodeToString(token)), nil
+}
+
+func HeartbeatUrl(token string) string {
+	return "heartbeat://" + token
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultMethod        = http.MethodGet
+	DefaultAcceptedCodes = StatusCodes("200")
+)
+
+// HTTPSpec describes how a site is requested and which responses are
+// considered successful.
+type HTTPSpec struct {
+	Method        string      `json:"method"`
+	Headers       Headers     `json:"headers"`
+	Body          string      `json:"body"`
+	AcceptedCodes StatusCodes `json:"acceptedCodes"`
+	Assertions    Assertions  `json:"assertions"`
+	// NoRedirects disables following of redirects, so their status codes
+	// can be accepted or rejected.
+	NoRedirects bool `json:"noRedirects"`
+}
+
+// Normalize fills empty fields with default values and validates the spec.
+func (h *HTTPSpec) Normalize() error {
+	h.Method = strings.ToUpper(strings.TrimSpace(h.Method))
+	if h.Method == "" {
+		h.Method = DefaultMethod
+	}
+	if h.Headers == nil {
+		h.Headers = Headers{}
+	}
+	if h.AcceptedCodes == "" {
+		h.AcceptedCodes = DefaultAcceptedCodes
+	}
+	if err := h.AcceptedCodes.Validate(); err != nil {
+		return err
+	}
+	for _, assertion := range h.Assertions {
+		if err := assertion.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Headers are stored in the database as a JSON object.
+type Headers map[string]string
+
+func (h Headers) Value() (driver.Value, error) {
+	if h == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (h *Headers) Scan(src any) error {
+	return scanJSON(src, h)
+}
+
+// StatusCodes is a comma separated list of status codes and inclusive ranges,
+// e.g. "200-299,401".
+type StatusCodes string
+
+type statusRange struct {
+	from, to int64
+}
+
+func (s StatusCodes) ranges() ([]statusRange, error) {
+	var ranges []statusRange
+	for _, part := range strings.Split(string(s), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fromStr, toStr, isRange := strings.Cut(part, "-")
+		from, err := strconv.ParseInt(strings.TrimSpace(fromStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		to := from
+		if isRange {
+			to, err = strconv.ParseInt(strings.TrimSpace(toStr), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status code range %q", part)
+			}
+		}
+
+		if from < 100 || to > 599 || from > to {
+			return nil, fmt.Errorf("invalid status code range %q", part)
+		}
+		ranges = append(ranges, statusRange{from, to})
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no status codes")
+	}
+	return ranges, nil
+}
+
+func (s StatusCodes) Validate() error {
+	_, err := s.ranges()
+	return err
+}
+
+// Contains reports whether code is accepted. Empty list accepts only
+// DefaultAcceptedCodes.
+func (s StatusCodes) Contains(code int64) bool {
+	if s == "" {
+		s = DefaultAcceptedCodes
+	}
+	ranges, err := s.ranges()
+	if err != nil {
+		return false
+	}
+	for _, r := range ranges {
+		if r.from <= code && code <= r.to {
+			return true
+		}
+	}
+	return false
+}
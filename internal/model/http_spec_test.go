@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStatusCodesValidate(t *testing.T) {
+	tests := []struct {
+		codes   StatusCodes
+		wantErr bool
+	}{
+		{codes: "200"},
+		{codes: "200-299"},
+		{codes: "200-299,401"},
+		{codes: " 200 - 299 , 401 "},
+		{codes: "200,,204"},
+		{codes: "100-599"},
+		{codes: "", wantErr: true},
+		{codes: ",", wantErr: true},
+		{codes: "ok", wantErr: true},
+		{codes: "200-", wantErr: true},
+		{codes: "-299", wantErr: true},
+		{codes: "200-abc", wantErr: true},
+		{codes: "299-200", wantErr: true},
+		{codes: "99", wantErr: true},
+		{codes: "600", wantErr: true},
+		{codes: "500-600", wantErr: true},
+		{codes: "200-299-399", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.codes), func(t *testing.T) {
+			err := tt.codes.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StatusCodes(%q).Validate() error = %v, wantErr %v", tt.codes, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatusCodesContains(t *testing.T) {
+	tests := []struct {
+		codes StatusCodes
+		code  int64
+		want  bool
+	}{
+		{codes: "200", code: 200, want: true},
+		{codes: "200", code: 201, want: false},
+		{codes: "200-299", code: 200, want: true},
+		{codes: "200-299", code: 299, want: true},
+		{codes: "200-299", code: 300, want: false},
+		{codes: "200-299", code: 199, want: false},
+		{codes: "200-299,401", code: 401, want: true},
+		{codes: "200-299,401", code: 403, want: false},
+		{codes: "301, 302", code: 302, want: true},
+		{codes: "", code: 200, want: true},
+		{codes: "", code: 204, want: false},
+		{codes: "invalid", code: 200, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.codes, tt.code), func(t *testing.T) {
+			if got := tt.codes.Contains(tt.code); got != tt.want {
+				t.Errorf("StatusCodes(%q).Contains(%d) = %v, want %v", tt.codes, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPSpecNormalize(t *testing.T) {
+	spec := HTTPSpec{Method: " head "}
+	if err := spec.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Method != "HEAD" {
+		t.Errorf("Method = %q, want %q", spec.Method, "HEAD")
+	}
+	if spec.AcceptedCodes != DefaultAcceptedCodes {
+		t.Errorf("AcceptedCodes = %q, want %q", spec.AcceptedCodes, DefaultAcceptedCodes)
+	}
+	if spec.Headers == nil {
+		t.Error("Headers = nil, want empty headers")
+	}
+
+	spec = HTTPSpec{AcceptedCodes: "200-"}
+	if err := spec.Normalize(); err == nil {
+		t.Error("Normalize() of invalid accepted codes returned no error")
+	}
+}
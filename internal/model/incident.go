@@ -0,0 +1,67 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type SiteState string
+
+const (
+	SiteStateUnknown  SiteState = "unknown"
+	SiteStateUp       SiteState = "up"
+	SiteStateDown     SiteState = "down"
+	SiteStateDegraded SiteState = "degraded"
+	SiteStatePaused   SiteState = "paused"
+	// SiteStateFlapping means that a site changes its state too often, so
+	// notifications about it going up and down are suppressed.
+	SiteStateFlapping SiteState = "flapping"
+)
+
+type IncidentKind string
+
+const (
+	IncidentKindDown     IncidentKind = "down"
+	IncidentKindDegraded IncidentKind = "degraded"
+)
+
+// Incident is a period of time when a site was down or degraded. A down
+// incident starts at the first failed check and ends at the first successful
+// check after it. A degraded incident lasts while checks pass but exceed
+// latency thresholds of the site.
+type Incident struct {
+	Id                  int64        `json:"id"`
+	SiteId              int64        `json:"siteId"`
+	Kind                IncidentKind `json:"kind"`
+	StartedAt           time.Time    `json:"startedAt"`
+	EndedAt             sql.NullTime `json:"endedAt"`
+	Cause               string       `json:"cause"`
+	FirstFailedResultId int64        `json:"firstFailedResultId"`
+	LastFailedResultId  int64        `json:"lastFailedResultId"`
+	AckedAt             sql.NullTime `json:"ackedAt"`
+	AckedBy             string       `json:"ackedBy,omitempty"`
+	EscalationStep      int          `json:"escalationStep"`
+}
+
+// IsAcknowledged reports whether somebody took the incident, which stops its
+// escalation.
+func (i *Incident) IsAcknowledged() bool {
+	return i.AckedAt.Valid
+}
+
+// Duration returns the duration of the incident, which is still ongoing if it
+// has not ended yet.
+func (i *Incident) Duration(now time.Time) time.Duration {
+	if i.EndedAt.Valid {
+		return i.EndedAt.Time.Sub(i.StartedAt)
+	}
+	return now.Sub(i.StartedAt)
+}
+
+// State returns the state of the site while the incident is open.
+func (i *Incident) State() SiteState {
+	if i.Kind == IncidentKindDegraded {
+		return SiteStateDegraded
+	}
+	return SiteStateDown
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LatencySpec describes when a site which passes its checks is considered
+// degraded: the latency of the last check exceeds MaxMs, or the 95th
+// percentile of latencies of recent successful checks exceeds P95Ms. Zero
+// disables the threshold.
+type LatencySpec struct {
+	MaxMs int64 `json:"maxMs"`
+	P95Ms int64 `json:"p95Ms"`
+}
+
+func (l *LatencySpec) Validate() error {
+	if l.MaxMs < 0 || l.P95Ms < 0 {
+		return fmt.Errorf("latency thresholds must not be negative")
+	}
+	return nil
+}
+
+func (l *LatencySpec) Enabled() bool {
+	return l.MaxMs > 0 || l.P95Ms > 0
+}
+
+// Degradation returns the reason why the site is degraded, or empty string if
+// it is not. Results must be ordered from the newest to the oldest.
+func (l *LatencySpec) Degradation(results []CheckResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	last := results[0]
+	if l.MaxMs > 0 && last.Latency.Valid && last.Latency.Int64 > l.MaxMs {
+		return fmt.Sprintf("latency %d ms exceeds %d ms", last.Latency.Int64, l.MaxMs)
+	}
+
+	if l.P95Ms > 0 {
+		p95, ok := latencyPercentile(results, 95)
+		if ok && p95 > l.P95Ms {
+			return fmt.Sprintf("p95 latency %d ms exceeds %d ms", p95, l.P95Ms)
+		}
+	}
+	return ""
+}
+
+// latencyPercentile returns the nearest-rank percentile of latencies of the
+// successful results.
+func latencyPercentile(results []CheckResult, percentile int) (int64, bool) {
+	var latencies []int64
+	for _, result := range results {
+		if result.IsSuccessful() && result.Latency.Valid {
+			latencies = append(latencies, result.Latency.Int64)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0, false
+	}
+
+	slices.Sort(latencies)
+	rank := (len(latencies)*percentile + 99) / 100
+	return latencies[max(rank, 1)-1], true
+}
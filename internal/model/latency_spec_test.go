@@ -0,0 +1,116 @@
+package model
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// latencyResults returns successful results with the latencies, the newest
+// first.
+func latencyResults(latencies ...int64) []CheckResult {
+	results := make([]CheckResult, 0, len(latencies))
+	for _, latency := range latencies {
+		results = append(results, CheckResult{
+			Successful: true,
+			Latency:    sql.NullInt64{Int64: latency, Valid: true},
+		})
+	}
+	return results
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []CheckResult
+		want    int64
+		wantOk  bool
+	}{
+		{name: "no results"},
+		{name: "single result", results: latencyResults(120), want: 120, wantOk: true},
+		{name: "twenty results", results: latencyResults(
+			10, 20, 30, 40, 50, 60, 70, 80, 90, 100,
+			110, 120, 130, 140, 150, 160, 170, 180, 190, 1000,
+		), want: 190, wantOk: true},
+		{name: "twenty one results", results: latencyResults(
+			1000, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100,
+			110, 120, 130, 140, 150, 160, 170, 180, 190, 200,
+		), want: 200, wantOk: true},
+		{name: "unordered results", results: latencyResults(300, 100, 200), want: 300, wantOk: true},
+		{
+			name: "failed results and missing latencies are skipped",
+			results: append(
+				latencyResults(100, 200),
+				CheckResult{Successful: false, Latency: sql.NullInt64{Int64: 5000, Valid: true}},
+				CheckResult{Successful: true},
+			),
+			want:   200,
+			wantOk: true,
+		},
+		{
+			name:    "only failed results",
+			results: []CheckResult{{Successful: false, Latency: sql.NullInt64{Int64: 5000, Valid: true}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := latencyPercentile(tt.results, 95)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("latencyPercentile() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestLatencySpecDegradation(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    LatencySpec
+		results []CheckResult
+		want    string
+	}{
+		{name: "disabled", spec: LatencySpec{}, results: latencyResults(5000)},
+		{name: "no results", spec: LatencySpec{MaxMs: 100, P95Ms: 100}},
+		{name: "last check within max", spec: LatencySpec{MaxMs: 500}, results: latencyResults(500, 900)},
+		{
+			name:    "last check exceeds max",
+			spec:    LatencySpec{MaxMs: 500},
+			results: latencyResults(501, 100),
+			want:    "latency 501 ms exceeds 500 ms",
+		},
+		{
+			name:    "p95 within threshold",
+			spec:    LatencySpec{P95Ms: 300},
+			results: latencyResults(100, 200, 300, 100, 200, 300, 100, 200, 300, 100),
+		},
+		{
+			name:    "p95 exceeds threshold",
+			spec:    LatencySpec{P95Ms: 300},
+			results: latencyResults(100, 200, 300, 100, 200, 300, 100, 200, 300, 400),
+			want:    "p95 latency 400 ms exceeds 300 ms",
+		},
+		{
+			// a single slow check out of twenty is above the 95th percentile
+			name: "single outlier within threshold",
+			spec: LatencySpec{P95Ms: 300},
+			results: latencyResults(
+				100, 100, 100, 100, 100, 100, 100, 100, 100, 100,
+				100, 100, 100, 100, 100, 100, 100, 100, 100, 2000,
+			),
+		},
+		{
+			name:    "max is checked before p95",
+			spec:    LatencySpec{MaxMs: 500, P95Ms: 300},
+			results: latencyResults(600, 400),
+			want:    "latency 600 ms exceeds 500 ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.Degradation(tt.results); got != tt.want {
+				t.Errorf("Degradation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"shm/internal/lib/cron"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a period of planned work on a site or on sites with a
+// tag, during which the site is not checked and notifications about it are
+// suppressed. A one-off window lasts from StartsAt to EndsAt, a recurring one
+// starts by the Cron expression (in UTC) and lasts DurationMin minutes.
+type MaintenanceWindow struct {
+	Id          int64     `json:"id"`
+	SiteId      int64     `json:"siteId,omitempty"`
+	Tag         string    `json:"tag,omitempty"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	Cron        string    `json:"cron,omitempty"`
+	DurationMin int       `json:"durationMin,omitempty"`
+	Announce    bool      `json:"announce"`
+	Active      bool      `json:"active"`
+}
+
+// Normalize fills empty fields with default values and validates the window.
+func (m *MaintenanceWindow) Normalize() error {
+	m.Tag = strings.TrimSpace(m.Tag)
+	if (m.SiteId != 0) == (m.Tag != "") {
+		return fmt.Errorf("maintenance window must belong either to a site or to a tag")
+	}
+
+	m.Cron = strings.TrimSpace(m.Cron)
+	if m.Cron == "" {
+		if m.StartsAt.IsZero() || !m.EndsAt.After(m.StartsAt) {
+			return fmt.Errorf("one-off maintenance window requires start before end")
+		}
+		m.DurationMin = 0
+		return nil
+	}
+
+	if err := cron.Validate(m.Cron); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	if m.DurationMin <= 0 {
+		return fmt.Errorf("duration of recurring maintenance window must be positive")
+	}
+	m.StartsAt = time.Time{}
+	m.EndsAt = time.Time{}
+	return nil
+}
+
+// ActiveAt reports whether the window is in progress at the moment.
+func (m *MaintenanceWindow) ActiveAt(now time.Time) bool {
+	if m.Cron == "" {
+		return !now.Before(m.StartsAt) && now.Before(m.EndsAt)
+	}
+
+	schedule, err := cron.Parse(m.Cron)
+	if err != nil {
+		return false
+	}
+	// the window is active if it started within the last duration
+	duration := time.Duration(m.DurationMin) * time.Minute
+	start := schedule.Next(now.UTC().Add(-duration - time.Minute))
+	for !start.IsZero() && !start.After(now) {
+		if now.Before(start.Add(duration)) {
+			return true
+		}
+		start = schedule.Next(start)
+	}
+	return false
+}
+
+// AppliesTo reports whether the window belongs to the site or to its tag.
+func (m *MaintenanceWindow) AppliesTo(site Site) bool {
+	if m.SiteId != 0 {
+		return m.SiteId == site.Id
+	}
+	return slices.Contains(site.Tags, m.Tag)
+}
+
+func (m MaintenanceWindow) String() string {
+	if m.Cron == "" {
+		return fmt.Sprintf(
+			"from %s to %s",
+			m.StartsAt.UTC().Format(time.DateTime),
+			m.EndsAt.UTC().Format(time.DateTime),
+		)
+	}
+	return fmt.Sprintf("%q for %d minutes", m.Cron, m.DurationMin)
+}
+
+// InMaintenance reports whether any of the windows of the site is in progress
+// at the moment.
+func InMaintenance(windows []MaintenanceWindow, site Site, now time.Time) bool {
+	for _, window := range windows {
+		if window.AppliesTo(site) && window.ActiveAt(now) {
+			return true
+		}
+	}
+	return false
+}
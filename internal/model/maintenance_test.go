@@ -0,0 +1,198 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowNormalize(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		window  MaintenanceWindow
+		wantErr bool
+	}{
+		{name: "one-off", window: MaintenanceWindow{SiteId: 1, StartsAt: start, EndsAt: start.Add(time.Hour)}},
+		{name: "recurring", window: MaintenanceWindow{Tag: " db ", Cron: " 0 3 * * 0 ", DurationMin: 30}},
+		{name: "site and tag", window: MaintenanceWindow{SiteId: 1, Tag: "db", Cron: "0 3 * * *", DurationMin: 30}, wantErr: true},
+		{name: "neither site nor tag", window: MaintenanceWindow{Cron: "0 3 * * *", DurationMin: 30}, wantErr: true},
+		{name: "one-off without start", window: MaintenanceWindow{SiteId: 1, EndsAt: start}, wantErr: true},
+		{name: "one-off ending at start", window: MaintenanceWindow{SiteId: 1, StartsAt: start, EndsAt: start}, wantErr: true},
+		{name: "one-off ending before start", window: MaintenanceWindow{SiteId: 1, StartsAt: start, EndsAt: start.Add(-time.Minute)}, wantErr: true},
+		{name: "invalid cron", window: MaintenanceWindow{SiteId: 1, Cron: "0 25 * * *", DurationMin: 30}, wantErr: true},
+		{name: "recurring without duration", window: MaintenanceWindow{SiteId: 1, Cron: "0 3 * * *"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := tt.window
+			err := window.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if window.Cron != "" && (!window.StartsAt.IsZero() || !window.EndsAt.IsZero()) {
+				t.Errorf("recurring window keeps start and end: %+v", window)
+			}
+			if window.Cron == "" && window.DurationMin != 0 {
+				t.Errorf("one-off window keeps duration: %+v", window)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowActiveAt(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone America/New_York is not available: %v", err)
+	}
+
+	oneOff := MaintenanceWindow{StartsAt: utc(2026, 3, 1, 12, 0), EndsAt: utc(2026, 3, 1, 14, 0)}
+	nightly := MaintenanceWindow{Cron: "0 3 * * *", DurationMin: 30}
+	midnight := MaintenanceWindow{Cron: "45 23 * * *", DurationMin: 30}
+
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		now    time.Time
+		want   bool
+	}{
+		{name: "one-off before start", window: oneOff, now: utc(2026, 3, 1, 11, 59)},
+		{name: "one-off at start", window: oneOff, now: utc(2026, 3, 1, 12, 0), want: true},
+		{name: "one-off in progress", window: oneOff, now: utc(2026, 3, 1, 13, 0), want: true},
+		{name: "one-off at end", window: oneOff, now: utc(2026, 3, 1, 14, 0)},
+		{name: "one-off in other zone", window: oneOff, now: utc(2026, 3, 1, 13, 0).In(newYork), want: true},
+
+		{name: "recurring before start", window: nightly, now: utc(2026, 3, 1, 2, 59)},
+		{name: "recurring at start", window: nightly, now: utc(2026, 3, 1, 3, 0), want: true},
+		{name: "recurring in progress", window: nightly, now: time.Date(2026, 3, 1, 3, 29, 59, 0, time.UTC), want: true},
+		{name: "recurring at end", window: nightly, now: utc(2026, 3, 1, 3, 30)},
+		{name: "recurring between runs", window: nightly, now: utc(2026, 3, 1, 15, 0)},
+		{name: "recurring across midnight", window: midnight, now: utc(2026, 3, 2, 0, 10), want: true},
+		{name: "recurring after midnight end", window: midnight, now: utc(2026, 3, 2, 0, 15)},
+		{
+			name:   "longer than period",
+			window: MaintenanceWindow{Cron: "0 * * * *", DurationMin: 90},
+			now:    utc(2026, 3, 1, 12, 59),
+			want:   true,
+		},
+		{
+			name:   "restricted day of week",
+			window: MaintenanceWindow{Cron: "0 2 * * 0", DurationMin: 120},
+			now:    utc(2026, 3, 8, 3, 0), // Sunday
+			want:   true,
+		},
+		{
+			name:   "other day of week",
+			window: MaintenanceWindow{Cron: "0 2 * * 0", DurationMin: 120},
+			now:    utc(2026, 3, 9, 3, 0), // Monday
+		},
+		{
+			name:   "either day field on day of month",
+			window: MaintenanceWindow{Cron: "0 2 1 * 0", DurationMin: 60},
+			now:    utc(2026, 4, 1, 2, 30), // Wednesday
+			want:   true,
+		},
+		{
+			name:   "either day field on day of week",
+			window: MaintenanceWindow{Cron: "0 2 1 * 0", DurationMin: 60},
+			now:    utc(2026, 4, 5, 2, 30), // Sunday
+			want:   true,
+		},
+		{
+			name:   "neither day field",
+			window: MaintenanceWindow{Cron: "0 2 1 * 0", DurationMin: 60},
+			now:    utc(2026, 4, 6, 2, 30),
+		},
+		{
+			name:   "window started on the previous day of week",
+			window: MaintenanceWindow{Cron: "0 23 * * 6", DurationMin: 180},
+			now:    utc(2026, 3, 8, 1, 0), // Sunday, window started on Saturday
+			want:   true,
+		},
+		{
+			name:   "evaluated in utc",
+			window: nightly,
+			now:    utc(2026, 3, 1, 3, 15).In(newYork), // 22:15 local on the previous day
+			want:   true,
+		},
+		{
+			name:   "local time of start is not used",
+			window: nightly,
+			now:    time.Date(2026, 3, 1, 3, 15, 0, 0, newYork),
+		},
+		{
+			name:   "spring forward does not shift utc schedule",
+			window: MaintenanceWindow{Cron: "30 7 * * *", DurationMin: 30},
+			now:    time.Date(2026, 3, 8, 3, 45, 0, 0, newYork), // 07:45 UTC
+			want:   true,
+		},
+		{
+			name:   "fall back does not shift utc schedule",
+			window: MaintenanceWindow{Cron: "30 5 * * *", DurationMin: 30},
+			now:    utc(2026, 11, 1, 6, 45).In(newYork), // the second 01:45 local
+		},
+		{name: "invalid cron", window: MaintenanceWindow{Cron: "bad", DurationMin: 30}, now: utc(2026, 3, 1, 3, 0)},
+		{
+			name:   "impossible date",
+			window: MaintenanceWindow{Cron: "0 0 30 2 *", DurationMin: 60},
+			now:    utc(2026, 3, 2, 0, 30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.ActiveAt(tt.now); got != tt.want {
+				t.Errorf("ActiveAt(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInMaintenance(t *testing.T) {
+	now := time.Date(2026, 3, 1, 3, 10, 0, 0, time.UTC)
+	active := MaintenanceWindow{Cron: "0 3 * * *", DurationMin: 30}
+	inactive := MaintenanceWindow{Cron: "0 4 * * *", DurationMin: 30}
+	site := Site{Id: 1, Tags: Strings{"db", "prod"}}
+
+	withSite := func(window MaintenanceWindow, siteId int64) MaintenanceWindow {
+		window.SiteId = siteId
+		return window
+	}
+	withTag := func(window MaintenanceWindow, tag string) MaintenanceWindow {
+		window.Tag = tag
+		return window
+	}
+
+	tests := []struct {
+		name    string
+		windows []MaintenanceWindow
+		want    bool
+	}{
+		{name: "no windows"},
+		{name: "active window of site", windows: []MaintenanceWindow{withSite(active, 1)}, want: true},
+		{name: "active window of other site", windows: []MaintenanceWindow{withSite(active, 2)}},
+		{name: "active window of tag", windows: []MaintenanceWindow{withTag(active, "prod")}, want: true},
+		{name: "active window of other tag", windows: []MaintenanceWindow{withTag(active, "web")}},
+		{name: "inactive window of site", windows: []MaintenanceWindow{withSite(inactive, 1)}},
+		{
+			name:    "any of windows",
+			windows: []MaintenanceWindow{withSite(inactive, 1), withTag(active, "db")},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InMaintenance(tt.windows, site, now); got != tt.want {
+				t.Errorf("InMaintenance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,127 @@
 package model
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+type NotificationEvent string
+
+const (
+	NotificationEventDown       NotificationEvent = "down"
+	NotificationEventUp         NotificationEvent = "up"
+	NotificationEventDegraded   NotificationEvent = "degraded"
+	NotificationEventRecovered  NotificationEvent = "recovered"
+	NotificationEventCertExpiry NotificationEvent = "cert_expiry"
+	NotificationEventDNSChanged NotificationEvent = "dns_changed"
+	NotificationEventFlapping   NotificationEvent = "flapping"
+	NotificationEventStabilized NotificationEvent = "stabilized"
+	NotificationEventEscalation NotificationEvent = "escalation"
+	NotificationEventDigest     NotificationEvent = "digest"
+
+	NotificationEventMaintenanceStarted NotificationEvent = "maintenance_started"
+	NotificationEventMaintenanceEnded   NotificationEvent = "maintenance_ended"
+)
+
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityWarning  Severity = "warning"
+	SeverityOk       Severity = "ok"
+	SeverityInfo     Severity = "info"
+)
+
+// Severity returns how urgent notifications about the event are.
+func (e NotificationEvent) Severity() Severity {
+	switch e {
+	case NotificationEventDown, NotificationEventEscalation:
+		return SeverityCritical
+	case NotificationEventDegraded, NotificationEventCertExpiry,
+		NotificationEventDNSChanged, NotificationEventFlapping:
+		return SeverityWarning
+	case NotificationEventUp, NotificationEventRecovered, NotificationEventStabilized:
+		return SeverityOk
+	default:
+		return SeverityInfo
+	}
+}
+
+// Title returns a short human readable description of the event.
+func (e NotificationEvent) Title() string {
+	switch e {
+	case NotificationEventDown:
+		return "Site is down"
+	case NotificationEventUp:
+		return "Site is up"
+	case NotificationEventDegraded:
+		return "Site is degraded"
+	case NotificationEventRecovered:
+		return "Site has recovered"
+	case NotificationEventCertExpiry:
+		return "Certificate expires soon"
+	case NotificationEventDNSChanged:
+		return "DNS answers changed"
+	case NotificationEventFlapping:
+		return "Site is flapping"
+	case NotificationEventStabilized:
+		return "Site has stabilized"
+	case NotificationEventEscalation:
+		return "Incident is not acknowledged"
+	case NotificationEventDigest:
+		return "Digest"
+	case NotificationEventMaintenanceStarted:
+		return "Maintenance has started"
+	case NotificationEventMaintenanceEnded:
+		return "Maintenance has ended"
+	default:
+		return string(e)
+	}
+}
+
+// StateTransition is the change of the state of a site which caused a
+// notification.
+type StateTransition struct {
+	From SiteState `json:"from"`
+	To   SiteState `json:"to"`
+}
+
+// Notification is sent to subscribers of the site with Url, or only to
+// ChatIds and ChannelIds if any of them is set. Notifications about state changes also carry the
+// site, its incident, the transition and the check result which caused it,
+// for channels that deliver machine-readable events. Digests carry the
+// uptime report of the site.
 type Notification struct {
-	Url     string `json:"url"`
-	Message string `json:"message"`
+	// Id is the id of the notification in pending notifications of the alert
+	// service, so that the notification published again is not delivered
+	// twice. Notifications sent by other services have no id.
+	Id         int64             `json:"id,omitempty"`
+	Url        string            `json:"url"`
+	Message    string            `json:"message"`
+	Event      NotificationEvent `json:"event"`
+	IncidentId int64             `json:"incidentId,omitempty"`
+	ChatIds    []int64           `json:"chatIds,omitempty"`
+	ChannelIds []int64           `json:"channelIds,omitempty"`
+	Site       *Site             `json:"site,omitempty"`
+	Incident   *Incident         `json:"incident,omitempty"`
+	Transition *StateTransition  `json:"transition,omitempty"`
+	Result     *CheckResult      `json:"result,omitempty"`
+	Report     *UptimeReport     `json:"report,omitempty"`
+
+	// ReplacedIncidentId is the degraded incident which ended when the down
+	// incident of the notification started.
+	ReplacedIncidentId int64 `json:"replacedIncidentId,omitempty"`
+}
+
+// Notifications are stored in the database as a JSON object.
+func (n Notification) Value() (driver.Value, error) {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (n *Notification) Scan(src any) error {
+	return scanJSON(src, n)
 }
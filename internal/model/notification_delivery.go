@@ -0,0 +1,49 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// NotificationDelivery is a notification waiting in the outbox for delivery
+// to one channel. Pending deliveries are retried until they are delivered or
+// run out of attempts and fail.
+type NotificationDelivery struct {
+	Id           int64        `json:"id"`
+	Notification Notification `json:"notification"`
+	// Channel is the snapshot of the channel at the time the notification
+	// was sent or the delivery was replayed
+	Channel Channel `json:"channel"`
+	// Recipient identifies the channel, deliveries to the same recipient
+	// are sent in order
+	Recipient     string         `json:"recipient"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt time.Time      `json:"nextAttemptAt"`
+	LastError     string         `json:"lastError,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	DeliveredAt   sql.NullTime   `json:"deliveredAt"`
+}
+
+func NewNotificationDelivery(
+	notification Notification,
+	channel Channel,
+	now time.Time,
+) NotificationDelivery {
+	return NotificationDelivery{
+		Notification:  notification,
+		Channel:       channel,
+		Recipient:     channel.Recipient(),
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
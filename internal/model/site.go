@@ -1,6 +1,186 @@
 package model
 
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type CheckType string
+
+const (
+	CheckTypeHTTP      CheckType = "http"
+	CheckTypeTCP       CheckType = "tcp"
+	CheckTypeDNS       CheckType = "dns"
+	CheckTypeHeartbeat CheckType = "heartbeat"
+)
+
 type Site struct {
-	Id  int64  `json:"id"`
-	Url string `json:"url"`
+	Id          int64         `json:"id"`
+	Url         string        `json:"url"`
+	Type        CheckType     `json:"type"`
+	HTTP        HTTPSpec      `json:"http"`
+	TCP         TCPSpec       `json:"tcp"`
+	DNS         DNSSpec       `json:"dns"`
+	Heartbeat   HeartbeatSpec `json:"heartbeat"`
+	Latency     LatencySpec   `json:"latency"`
+	Tags        Strings       `json:"tags"`
+	IntervalSec int64         `json:"intervalSec"`
+	NextRunAt   sql.NullTime  `json:"nextRunAt"`
+	BaseRunAt   sql.NullTime  `json:"baseRunAt"`
+	State       SiteState     `json:"state"`
+}
+
+// SiteSummary identifies a site in notifications sent outside of the service.
+// Specs of the site are left out, since they may contain credentials.
+type SiteSummary struct {
+	Id   int64     `json:"id"`
+	Url  string    `json:"url"`
+	Type CheckType `json:"type"`
+}
+
+// Summary returns the summary of the site with redacted url.
+func (s *Site) Summary() SiteSummary {
+	return SiteSummary{
+		Id:   s.Id,
+		Url:  RedactUrl(s.Url),
+		Type: s.Type,
+	}
+}
+
+// RedactUrl masks secrets of a site url: the token of a heartbeat url and the
+// password of an http url.
+func RedactUrl(url string) string {
+	if strings.HasPrefix(url, "heartbeat://") {
+		return HeartbeatUrl("xxxxx")
+	}
+	u, err := neturl.Parse(url)
+	if err != nil || u.User == nil {
+		return url
+	}
+	return u.Redacted()
+}
+
+// CheckTypeFromUrl derives type of the check from the scheme of url.
+func CheckTypeFromUrl(url string) CheckType {
+	if strings.HasPrefix(url, "tcp://") {
+		return CheckTypeTCP
+	}
+	if strings.HasPrefix(url, "dns://") {
+		return CheckTypeDNS
+	}
+	if strings.HasPrefix(url, "heartbeat://") {
+		return CheckTypeHeartbeat
+	}
+	return CheckTypeHTTP
+}
+
+// Normalize fills empty fields with default values and validates the site.
+func (s *Site) Normalize() error {
+	if s.Type == "" {
+		s.Type = CheckTypeFromUrl(s.Url)
+	}
+
+	if s.IntervalSec < 0 {
+		return fmt.Errorf("interval of checks must not be negative")
+	}
+	if err := s.Latency.Validate(); err != nil {
+		return err
+	}
+	s.normalizeTags()
+
+	switch s.Type {
+	case CheckTypeHTTP:
+		u, err := neturl.Parse(s.Url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http check requires http or https url")
+		}
+	case CheckTypeTCP:
+		if _, err := s.TCPAddress(); err != nil {
+			return err
+		}
+	case CheckTypeDNS:
+		if _, err := s.DNSName(); err != nil {
+			return err
+		}
+		if err := s.DNS.Normalize(); err != nil {
+			return err
+		}
+	case CheckTypeHeartbeat:
+		if s.Url == "" {
+			url, err := NewHeartbeatUrl()
+			if err != nil {
+				return err
+			}
+			s.Url = url
+		}
+		if _, err := s.HeartbeatToken(); err != nil {
+			return err
+		}
+		if err := s.Heartbeat.Normalize(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown check type %q", s.Type)
+	}
+
+	return s.HTTP.Normalize()
+}
+
+// TCPAddress returns host:port of a site with url in form tcp://host:port.
+func (s *Site) TCPAddress() (string, error) {
+	address, found := strings.CutPrefix(s.Url, "tcp://")
+	if !found {
+		return "", fmt.Errorf("tcp check requires url in form tcp://host:port")
+	}
+	address = strings.TrimSuffix(address, "/")
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", fmt.Errorf("invalid tcp address: %w", err)
+	}
+	return address, nil
+}
+
+// DNSName returns the resolved name of a site with url in form dns://name.
+func (s *Site) DNSName() (string, error) {
+	name, found := strings.CutPrefix(s.Url, "dns://")
+	name = strings.TrimSuffix(name, "/")
+	if !found || name == "" || strings.ContainsAny(name, "/:") {
+		return "", fmt.Errorf("dns check requires url in form dns://name")
+	}
+	return name, nil
+}
+
+// HeartbeatToken returns the token of a site with url in form
+// heartbeat://token.
+func (s *Site) HeartbeatToken() (string, error) {
+	token, found := strings.CutPrefix(s.Url, "heartbeat://")
+	if !found || token == "" || strings.Contains(token, "/") {
+		return "", fmt.Errorf("heartbeat requires url in form heartbeat://token")
+	}
+	return token, nil
+}
+
+// Interval returns the period of checks of the site. Zero IntervalSec means
+// the default interval of the scheduler.
+func (s *Site) Interval(defaultInterval time.Duration) time.Duration {
+	if s.IntervalSec == 0 {
+		return defaultInterval
+	}
+	return time.Duration(s.IntervalSec) * time.Second
+}
+
+// normalizeTags trims tags and removes empty and duplicate ones.
+func (s *Site) normalizeTags() {
+	tags := make(Strings, 0, len(s.Tags))
+	for _, tag := range s.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	s.Tags = tags
 }
@@ -0,0 +1,11 @@
+package model
+
+// TCPSpec describes a raw socket check. After connecting, Banner is expected
+// to be sent by the server, then Send is written and Expect is awaited. All
+// steps are optional; empty spec checks only that the port accepts
+// connections.
+type TCPSpec struct {
+	Banner string `json:"banner"`
+	Send   string `json:"send"`
+	Expect string `json:"expect"`
+}
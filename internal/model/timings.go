@@ -0,0 +1,13 @@
+package model
+
+import "database/sql"
+
+// Timings is a breakdown of an HTTP check in milliseconds. Phases which did
+// not happen (e.g. TLS handshake for plain HTTP) are null.
+type Timings struct {
+	DNSLookup    sql.NullInt64 `json:"dnsLookup"`
+	Connect      sql.NullInt64 `json:"connect"`
+	TLSHandshake sql.NullInt64 `json:"tlsHandshake"`
+	FirstByte    sql.NullInt64 `json:"firstByte"`
+	Transfer     sql.NullInt64 `json:"transfer"`
+}
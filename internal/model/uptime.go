@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// UptimeReport shows how long a site was down and degraded during a period.
+// Time when the site was degraded counts as uptime.
+type UptimeReport struct {
+	SiteId        int64     `json:"siteId"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	DownSec       int64     `json:"downSec"`
+	DegradedSec   int64     `json:"degradedSec"`
+	UptimePercent float64   `json:"uptimePercent"`
+	Incidents     int       `json:"incidents"`
+}
+
+// NewUptimeReport builds the report from incidents which overlap the period.
+func NewUptimeReport(siteId int64, incidents []Incident, from, to time.Time) UptimeReport {
+	report := UptimeReport{
+		SiteId:    siteId,
+		From:      from,
+		To:        to,
+		Incidents: len(incidents),
+	}
+
+	var down, degraded time.Duration
+	for _, incident := range incidents {
+		start := incident.StartedAt
+		if start.Before(from) {
+			start = from
+		}
+		end := to
+		if incident.EndedAt.Valid && incident.EndedAt.Time.Before(to) {
+			end = incident.EndedAt.Time
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		if incident.Kind == IncidentKindDegraded {
+			degraded += end.Sub(start)
+		} else {
+			down += end.Sub(start)
+		}
+	}
+
+	report.DownSec = int64(down.Seconds())
+	report.DegradedSec = int64(degraded.Seconds())
+	report.UptimePercent = 100
+	if period := to.Sub(from); period > 0 {
+		report.UptimePercent = 100 * (1 - down.Seconds()/period.Seconds())
+	}
+	return report
+}
@@ -0,0 +1,140 @@
+package chatops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"strconv"
+	"time"
+)
+
+type channelConfig struct {
+	Url string `json:"url"`
+	// Format is used by Teams: "messagecard" or "adaptivecard"
+	Format string `json:"format"`
+}
+
+func parseConfig(channel model.Channel) (channelConfig, error) {
+	var config channelConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return channelConfig{}, err
+	}
+
+	u, err := neturl.Parse(config.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return channelConfig{}, fmt.Errorf("incoming webhook requires http or https url")
+	}
+	return config, nil
+}
+
+// formatter builds the body posted to an incoming webhook.
+type formatter func(message message, config channelConfig) any
+
+// Register registers slack, discord and teams channel types.
+func Register() {
+	client := notifier.NewClient()
+	formatters := map[model.ChannelType]formatter{
+		model.ChannelTypeSlack:   slackPayload,
+		model.ChannelTypeDiscord: discordPayload,
+		model.ChannelTypeTeams:   teamsPayload,
+	}
+
+	for channelType, format := range formatters {
+		notifier.Register(channelType, func(channel model.Channel) (notifier.Notifier, error) {
+			config, err := parseConfig(channel)
+			if err != nil {
+				return nil, err
+			}
+			if channelType == model.ChannelTypeTeams {
+				switch config.Format {
+				case "":
+					config.Format = teamsMessageCard
+				case teamsMessageCard, teamsAdaptiveCard:
+				default:
+					return nil, fmt.Errorf("unknown teams card format %q", config.Format)
+				}
+			}
+			return &Notifier{client: client, config: config, format: format}, nil
+		})
+	}
+}
+
+// Notifier posts notifications to an incoming webhook of Slack, Discord or
+// Microsoft Teams.
+type Notifier struct {
+	client *http.Client
+	config channelConfig
+	format formatter
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification model.Notification) error {
+	payload := n.format(newMessage(notification, time.Now()), n.config)
+	err := notifier.SendJSON(ctx, n.client, http.MethodPost, n.config.Url, nil, payload)
+	if err != nil {
+		return fmt.Errorf("incoming webhook %w", err)
+	}
+	return nil
+}
+
+type fact struct {
+	name  string
+	value string
+}
+
+// message is a notification prepared for chat formatting.
+type message struct {
+	title    string
+	text     string
+	url      string
+	severity model.Severity
+	facts    []fact
+	time     time.Time
+}
+
+func newMessage(notification model.Notification, now time.Time) message {
+	notification = notifier.Redact(notification)
+	m := message{
+		title:    notification.Event.Title(),
+		text:     notification.Message,
+		url:      notification.Url,
+		severity: notification.Event.Severity(),
+		facts:    []fact{{"Site", notification.Url}},
+		time:     now.UTC(),
+	}
+
+	if incident := notification.Incident; incident != nil {
+		duration := incident.Duration(now).Round(time.Second)
+		m.facts = append(m.facts, fact{"Duration", duration.String()})
+	}
+	if result := notification.Result; result != nil {
+		if result.Code.Valid {
+			m.facts = append(m.facts, fact{"Status code", strconv.FormatInt(result.Code.Int64, 10)})
+		}
+		if result.Latency.Valid {
+			m.facts = append(m.facts, fact{"Latency", fmt.Sprintf("%d ms", result.Latency.Int64)})
+		}
+	}
+	return m
+}
+
+// color returns RGB color of the severity.
+func color(severity model.Severity) int {
+	switch severity {
+	case model.SeverityCritical:
+		return 0xd32f2f
+	case model.SeverityWarning:
+		return 0xf57c00
+	case model.SeverityOk:
+		return 0x388e3c
+	default:
+		return 0x1976d2
+	}
+}
+
+func hexColor(severity model.Severity) string {
+	return fmt.Sprintf("%06x", color(severity))
+}
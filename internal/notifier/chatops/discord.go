@@ -0,0 +1,33 @@
+package chatops
+
+import (
+	"strings"
+	"time"
+)
+
+func discordPayload(m message, _ channelConfig) any {
+	fields := make([]map[string]any, 0, len(m.facts))
+	for _, f := range m.facts {
+		fields = append(fields, map[string]any{
+			"name":   f.name,
+			"value":  f.value,
+			"inline": true,
+		})
+	}
+
+	embed := map[string]any{
+		"title":       m.title,
+		"description": m.text,
+		"color":       color(m.severity),
+		"fields":      fields,
+		"timestamp":   m.time.Format(time.RFC3339),
+	}
+	// embeds may only link to web pages
+	if strings.HasPrefix(m.url, "http://") || strings.HasPrefix(m.url, "https://") {
+		embed["url"] = m.url
+	}
+
+	return map[string]any{
+		"embeds": []map[string]any{embed},
+	}
+}
@@ -0,0 +1,34 @@
+package chatops
+
+// slackPayload formats the message with Block Kit. Blocks are wrapped in an
+// attachment, since only attachments have a color bar.
+func slackPayload(m message, _ channelConfig) any {
+	fields := make([]map[string]any, 0, len(m.facts))
+	for _, f := range m.facts {
+		fields = append(fields, map[string]any{
+			"type": "mrkdwn",
+			"text": "*" + f.name + "*\n" + f.value,
+		})
+	}
+
+	return map[string]any{
+		"text": m.title + ": " + m.text,
+		"attachments": []map[string]any{{
+			"color": "#" + hexColor(m.severity),
+			"blocks": []map[string]any{
+				{
+					"type": "header",
+					"text": map[string]any{"type": "plain_text", "text": m.title},
+				},
+				{
+					"type": "section",
+					"text": map[string]any{"type": "mrkdwn", "text": m.text},
+				},
+				{
+					"type":   "section",
+					"fields": fields,
+				},
+			},
+		}},
+	}
+}
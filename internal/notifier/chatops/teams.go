@@ -0,0 +1,81 @@
+package chatops
+
+import "shm/internal/model"
+
+const (
+	teamsMessageCard  = "messagecard"
+	teamsAdaptiveCard = "adaptivecard"
+)
+
+func teamsPayload(m message, config channelConfig) any {
+	if config.Format == teamsAdaptiveCard {
+		return teamsAdaptiveCardPayload(m)
+	}
+
+	facts := make([]map[string]any, 0, len(m.facts))
+	for _, f := range m.facts {
+		facts = append(facts, map[string]any{"name": f.name, "value": f.value})
+	}
+
+	return map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"themeColor": hexColor(m.severity),
+		"summary":    m.title,
+		"title":      m.title,
+		"text":       m.text,
+		"sections":   []map[string]any{{"facts": facts}},
+	}
+}
+
+// teamsAdaptiveCardPayload formats the message for webhooks of Teams
+// workflows, which accept only adaptive cards.
+func teamsAdaptiveCardPayload(m message) any {
+	facts := make([]map[string]any, 0, len(m.facts))
+	for _, f := range m.facts {
+		facts = append(facts, map[string]any{"title": f.name, "value": f.value})
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body": []map[string]any{
+					{
+						"type":   "TextBlock",
+						"text":   m.title,
+						"weight": "Bolder",
+						"size":   "Medium",
+						"color":  adaptiveCardColor(m.severity),
+					},
+					{
+						"type": "TextBlock",
+						"text": m.text,
+						"wrap": true,
+					},
+					{
+						"type":  "FactSet",
+						"facts": facts,
+					},
+				},
+			},
+		}},
+	}
+}
+
+func adaptiveCardColor(severity model.Severity) string {
+	switch severity {
+	case model.SeverityCritical:
+		return "Attention"
+	case model.SeverityWarning:
+		return "Warning"
+	case model.SeverityOk:
+		return "Good"
+	default:
+		return "Accent"
+	}
+}
@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"shm/internal/broker"
+	"shm/internal/config"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/service"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Dispatcher consumes the notifications queue and fans each notification out
+// to every channel subscribed to its site. Deliveries to the channels are
+// stored in the outbox first and sent from there, so a failed one is retried
+// without blocking the queue.
+type Dispatcher struct {
+	broker   broker.MessageBroker
+	channels *service.ChannelsService
+	chats    *service.ChatsService
+	emails   *service.EmailsService
+	outbox   *service.NotificationDeliveriesService
+	config   config.NotifierConfig
+	// wake triggers outboxRoutine when new deliveries are added
+	wake chan struct{}
+}
+
+func NewDispatcher(
+	broker broker.MessageBroker,
+	channels *service.ChannelsService,
+	chats *service.ChatsService,
+	emails *service.EmailsService,
+	outbox *service.NotificationDeliveriesService,
+	config config.NotifierConfig,
+) *Dispatcher {
+	return &Dispatcher{
+		broker:   broker,
+		channels: channels,
+		chats:    chats,
+		emails:   emails,
+		outbox:   outbox,
+		config:   config,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	notifications, err := d.broker.ConsumeNotifications(ctx, 1)
+	if err != nil {
+		slog.Error("failed to register a consumer for notifications", sl.Error(err))
+		return
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return d.routine(ctx, notifications)
+	})
+
+	g.Go(func() error {
+		return d.outboxRoutine(ctx)
+	})
+
+	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("error from notifier", sl.Error(err))
+	}
+}
+
+func (d *Dispatcher) routine(
+	ctx context.Context,
+	notifications <-chan broker.Message[model.Notification],
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-notifications:
+			if !ok {
+				return fmt.Errorf("queue with notifications was closed")
+			}
+			// the notification is acked once it is in the outbox
+			if err := d.dispatch(ctx, msg.Body); err != nil {
+				msg.Retry()
+				return fmt.Errorf("failed to handle notification: %w", err)
+			}
+			if err := msg.Ack(); err != nil {
+				slog.Warn("failed to ack notification, it will be consumed again", sl.Error(err))
+			}
+		}
+	}
+}
+
+// dispatch adds a delivery to the outbox for every channel of the
+// notification.
+func (d *Dispatcher) dispatch(ctx context.Context, notification model.Notification) error {
+	channels, err := d.recipients(ctx, notification)
+	if err != nil {
+		return fmt.Errorf("failed to get channels of notification: %w", err)
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	deliveries := make([]model.NotificationDelivery, 0, len(channels))
+	for _, channel := range channels {
+		deliveries = append(deliveries, model.NewNotificationDelivery(notification, channel, now))
+	}
+	if err := d.outbox.AddDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("failed to add deliveries to outbox: %w", err)
+	}
+	slog.Info(
+		"notification was added to outbox",
+		sl.Notification(notification),
+		slog.Int("deliveries", len(deliveries)),
+	)
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// recipients returns the channels of the notification. Chats subscribed to
+// the site with the telegram bot are telegram channels as well, and email
+// recipients of the site are email channels. Notification with ChatIds or
+// ChannelIds is sent only to these chats and channels.
+func (d *Dispatcher) recipients(
+	ctx context.Context,
+	notification model.Notification,
+) ([]model.Channel, error) {
+	var channels []model.Channel
+	if len(notification.ChatIds) > 0 || len(notification.ChannelIds) > 0 {
+		for _, chatId := range notification.ChatIds {
+			channels = append(channels, model.TelegramChannel(chatId))
+		}
+		for _, channelId := range notification.ChannelIds {
+			channel, err := d.channels.GetChannelById(ctx, channelId)
+			if err != nil {
+				return nil, err
+			}
+			if channel == nil {
+				slog.Warn("channel of notification was deleted", slog.Int64("channel_id", channelId))
+				continue
+			}
+			channels = append(channels, *channel)
+		}
+		return channels, nil
+	}
+
+	chats, err := d.chats.GetAllSubscribedOnSiteChats(ctx, notification.Url)
+	if err != nil {
+		return nil, err
+	}
+	for _, chat := range chats {
+		channels = append(channels, model.TelegramChannel(chat.Id))
+	}
+
+	emails, err := d.emails.GetAllSubscribedOnSiteEmails(ctx, notification.Url)
+	if err != nil {
+		return nil, err
+	}
+	for _, email := range emails {
+		channels = append(channels, model.EmailChannel(email))
+	}
+
+	subscribed, err := d.channels.GetAllSubscribedOnSiteChannels(ctx, notification.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(channels, subscribed...), nil
+}
@@ -0,0 +1,231 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"strings"
+	"time"
+)
+
+const (
+	tlsStartTLS = "starttls"
+	tlsImplicit = "tls"
+	tlsNone     = "none"
+)
+
+type channelConfig struct {
+	To []string `json:"to"`
+}
+
+// Sender sends emails through the SMTP server.
+type Sender struct {
+	from   *mail.Address
+	config config.SMTPConfig
+	// rootCAs are trusted by TLS connections, nil means the roots of the
+	// system
+	rootCAs *x509.CertPool
+}
+
+func NewSender(config config.SMTPConfig) (*Sender, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	switch config.TLS {
+	case tlsStartTLS, tlsImplicit, tlsNone:
+	default:
+		return nil, fmt.Errorf("unknown smtp tls mode %q", config.TLS)
+	}
+	return &Sender{from: from, config: config}, nil
+}
+
+// Register registers the email channel type.
+func (s *Sender) Register() {
+	notifier.Register(model.ChannelTypeEmail, func(channel model.Channel) (notifier.Notifier, error) {
+		var cfg channelConfig
+		if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+			return nil, err
+		}
+		if len(cfg.To) == 0 {
+			return nil, fmt.Errorf("at least one recipient is required")
+		}
+
+		to := make([]*mail.Address, 0, len(cfg.To))
+		for _, recipient := range cfg.To {
+			address, err := mail.ParseAddress(recipient)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recipient %q", recipient)
+			}
+			to = append(to, address)
+		}
+		return &Notifier{sender: s, to: to}, nil
+	})
+}
+
+// Send delivers the message to the recipients. The connection is closed
+// once the context is done.
+func (s *Sender) Send(ctx context.Context, to []*mail.Address, message []byte) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if s.config.TLS == tlsStartTLS {
+		if err := c.StartTLS(s.tlsConfig()); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(s.from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (s *Sender) dial(ctx context.Context) (net.Conn, error) {
+	address := net.JoinHostPort(s.config.Host, s.config.Port)
+	if s.config.TLS == tlsImplicit {
+		dialer := &tls.Dialer{Config: s.tlsConfig()}
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+	dialer := &net.Dialer{}
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
+func (s *Sender) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: s.config.Host, RootCAs: s.rootCAs}
+}
+
+// Notifier sends notifications as emails rendered from the templates of
+// their events.
+type Notifier struct {
+	sender *Sender
+	to     []*mail.Address
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification model.Notification) error {
+	message, err := n.render(notification, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+	return n.sender.Send(ctx, n.to, message)
+}
+
+// templateData is rendered by templates. Its notification has the redacted
+// url of the site, since emails are kept in mailboxes and forwarded.
+type templateData struct {
+	model.Notification
+	Time time.Time
+}
+
+// render builds a multipart message with plain text and HTML alternatives.
+func (n *Notifier) render(notification model.Notification, now time.Time) ([]byte, error) {
+	templates := templatesFor(notification.Event)
+	data := templateData{Notification: notifier.Redact(notification), Time: now}
+
+	var subject, text, html bytes.Buffer
+	if err := templates.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := templates.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := templates.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+
+	to := make([]string, 0, len(n.to))
+	for _, recipient := range n.to {
+		to = append(to, recipient.String())
+	}
+	header := []string{
+		"From: " + n.sender.from.String(),
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())),
+		"Date: " + now.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + w.Boundary(),
+	}
+	b.WriteString(strings.Join(header, "\r\n") + "\r\n\r\n")
+
+	if err := writePart(w, "text/plain; charset=utf-8", text.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writePart(w, "text/html; charset=utf-8", html.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writePart(w *multipart.Writer, contentType string, body []byte) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	if _, err := io.Copy(qp, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,159 @@
+package email
+
+import (
+	htmltemplate "html/template"
+	"shm/internal/model"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// templateSet renders an email about one kind of events.
+type templateSet struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+var funcs = map[string]any{
+	"time": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+	"minutes": func(sec int64) int64 {
+		return sec / 60
+	},
+}
+
+func newTemplateSet(subject, text, html string) templateSet {
+	return templateSet{
+		subject: texttemplate.Must(texttemplate.New("subject").Funcs(funcs).Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New("text").Funcs(funcs).Parse(text + textDetails)),
+		html:    htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlLayout(html))),
+	}
+}
+
+// textDetails and htmlDetails describe the incident and the last check of
+// state change notifications.
+const textDetails = `{{define "details"}}
+Site: {{.Url}}
+{{- with .Incident}}
+Incident started at: {{time .StartedAt}}
+Cause: {{.Cause}}
+{{- end}}
+{{- with .Result}}
+{{- if .Code.Valid}}
+Last status code: {{.Code.Int64}}
+{{- end}}
+{{- if .Latency.Valid}}
+Last latency: {{.Latency.Int64}} ms
+{{- end}}
+{{- end}}
+{{end}}`
+
+const htmlDetails = `{{define "details"}}
+<table>
+<tr><td>Site</td><td><a href="{{.Url}}">{{.Url}}</a></td></tr>
+{{- with .Incident}}
+<tr><td>Incident started at</td><td>{{time .StartedAt}}</td></tr>
+<tr><td>Cause</td><td>{{.Cause}}</td></tr>
+{{- end}}
+{{- with .Result}}
+{{- if .Code.Valid}}
+<tr><td>Last status code</td><td>{{.Code.Int64}}</td></tr>
+{{- end}}
+{{- if .Latency.Valid}}
+<tr><td>Last latency</td><td>{{.Latency.Int64}} ms</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{end}}`
+
+func htmlLayout(body string) string {
+	return `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+` + strings.TrimSpace(body) + `
+<p style="color: #888888;">Sent by Site Health Monitor at {{time .Time}}.</p>
+</body>
+</html>
+` + htmlDetails
+}
+
+var (
+	downTemplates = newTemplateSet(
+		`[DOWN] {{.Url}}`,
+		`{{.Message}}
+{{template "details" .}}`,
+		`<h2 style="color: #d32f2f;">{{.Url}} is down</h2>
+<p>{{.Message}}</p>
+{{template "details" .}}`,
+	)
+
+	recoveredTemplates = newTemplateSet(
+		`[RECOVERED] {{.Url}}`,
+		`{{.Message}}
+{{template "details" .}}`,
+		`<h2 style="color: #388e3c;">{{.Url}} has recovered</h2>
+<p>{{.Message}}</p>
+{{template "details" .}}`,
+	)
+
+	certExpiryTemplates = newTemplateSet(
+		`[CERTIFICATE] {{.Url}}`,
+		`{{.Message}}
+
+Renew the certificate of {{.Url}} before it expires.
+`,
+		`<h2 style="color: #f57c00;">Certificate of {{.Url}} expires soon</h2>
+<p>{{.Message}}</p>
+<p>Renew the certificate before it expires.</p>`,
+	)
+
+	digestTemplates = newTemplateSet(
+		`[DIGEST] {{.Url}}`,
+		`{{.Message}}
+{{with .Report}}
+Period: {{time .From}} - {{time .To}}
+Uptime: {{printf "%.2f" .UptimePercent}}%
+Incidents: {{.Incidents}}
+Down: {{minutes .DownSec}} minutes
+Degraded: {{minutes .DegradedSec}} minutes
+{{end}}`,
+		`<h2>Digest for {{.Url}}</h2>
+{{with .Report}}
+<p>{{time .From}} - {{time .To}}</p>
+<table>
+<tr><td>Uptime</td><td>{{printf "%.2f" .UptimePercent}}%</td></tr>
+<tr><td>Incidents</td><td>{{.Incidents}}</td></tr>
+<tr><td>Down</td><td>{{minutes .DownSec}} minutes</td></tr>
+<tr><td>Degraded</td><td>{{minutes .DegradedSec}} minutes</td></tr>
+</table>
+{{else}}
+<p>{{.Message}}</p>
+{{end}}`,
+	)
+
+	defaultTemplates = newTemplateSet(
+		`[{{.Event}}] {{.Url}}`,
+		`{{.Message}}
+{{template "details" .}}`,
+		`<h2>{{.Url}}</h2>
+<p>{{.Message}}</p>
+{{template "details" .}}`,
+	)
+)
+
+func templatesFor(event model.NotificationEvent) templateSet {
+	switch event {
+	case model.NotificationEventDown:
+		return downTemplates
+	case model.NotificationEventUp, model.NotificationEventRecovered:
+		return recoveredTemplates
+	case model.NotificationEventCertExpiry:
+		return certExpiryTemplates
+	case model.NotificationEventDigest:
+		return digestTemplates
+	default:
+		return defaultTemplates
+	}
+}
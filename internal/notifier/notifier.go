@@ -1,9 +1,84 @@
 package notifier
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"shm/internal/model"
+	"strings"
+	"sync"
 )
 
+// Notifier delivers a notification to a single channel.
 type Notifier interface {
-	Notify(result model.CheckResult) error
+	Notify(ctx context.Context, notification model.Notification) error
+}
+
+// Redact returns a copy of the notification which may be sent outside of the
+// service: secrets of the site url are redacted in the url and in the message
+// mentioning it.
+func Redact(notification model.Notification) model.Notification {
+	url := model.RedactUrl(notification.Url)
+	if notification.Url != "" {
+		notification.Message = strings.ReplaceAll(notification.Message, notification.Url, url)
+	}
+	notification.Url = url
+	return notification
+}
+
+type deliveryIdKey struct{}
+
+// WithDeliveryId returns a copy of ctx which carries id of the outbox
+// delivery being sent. The id is the same for all attempts of the delivery,
+// so notifiers may use it to let receivers drop duplicates.
+func WithDeliveryId(ctx context.Context, deliveryId int64) context.Context {
+	return context.WithValue(ctx, deliveryIdKey{}, deliveryId)
+}
+
+// DeliveryId returns id of the outbox delivery carried by ctx.
+func DeliveryId(ctx context.Context) (int64, bool) {
+	deliveryId, ok := ctx.Value(deliveryIdKey{}).(int64)
+	return deliveryId, ok
+}
+
+// ErrNoNotifier is returned by New when the notifier can not be created from
+// the channel, so sending to it will not succeed until the channel changes.
+var ErrNoNotifier = errors.New("no notifier for channel")
+
+// Factory creates a notifier for the channel from its config.
+type Factory func(channel model.Channel) (Notifier, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[model.ChannelType]Factory{}
+)
+
+// Register makes a channel type available to New. It panics if the type is
+// registered twice.
+func Register(channelType model.ChannelType, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := factories[channelType]; exists {
+		panic(fmt.Sprintf("notifier for channel type %q is already registered", channelType))
+	}
+	factories[channelType] = factory
+}
+
+// New creates a notifier for the channel using the factory registered for
+// its type.
+func New(channel model.Channel) (Notifier, error) {
+	mu.RLock()
+	factory, exists := factories[channel.Type]
+	mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w type %q", ErrNoNotifier, channel.Type)
+	}
+
+	notifier, err := factory(channel)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid config of %s channel: %w", ErrNoNotifier, channel.Type, err)
+	}
+	return notifier, nil
 }
@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"shm/internal/model"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	const registryChannelType model.ChannelType = "registry"
+	Register(registryChannelType, func(channel model.Channel) (Notifier, error) {
+		var config struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(channel.Config, &config); err != nil {
+			return nil, err
+		}
+		if config.Name == "" {
+			return nil, errors.New("name is required")
+		}
+		return testNotifier{}, nil
+	})
+
+	tests := []struct {
+		name           string
+		channel        model.Channel
+		wantNoNotifier bool
+	}{
+		{name: "registered type", channel: model.Channel{Type: registryChannelType, Config: model.ChannelConfig(`{"name":"a"}`)}},
+		{name: "unknown type", channel: model.Channel{Type: "unknown", Config: model.ChannelConfig(`{}`)}, wantNoNotifier: true},
+		{name: "invalid config", channel: model.Channel{Type: registryChannelType, Config: model.ChannelConfig(`{}`)}, wantNoNotifier: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.channel)
+			if errors.Is(err, ErrNoNotifier) != tt.wantNoNotifier {
+				t.Fatalf("New() error = %v, want ErrNoNotifier %v", err, tt.wantNoNotifier)
+			}
+			if (n != nil) == tt.wantNoNotifier {
+				t.Errorf("New() = %v, want notifier %v", n, !tt.wantNoNotifier)
+			}
+		})
+	}
+
+	t.Run("registered twice", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Register() of a registered type did not panic")
+			}
+		}()
+		Register(registryChannelType, func(channel model.Channel) (Notifier, error) {
+			return testNotifier{}, nil
+		})
+	})
+}
@@ -0,0 +1,152 @@
+package notifier
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// outboxRoutine sends due deliveries from the outbox every PollSec and as
+// soon as dispatch adds new ones.
+func (d *Dispatcher) outboxRoutine(ctx context.Context) error {
+	t := time.NewTicker(d.config.Outbox.PollSec)
+	defer t.Stop()
+
+	for {
+		if err := d.deliverDue(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Error("failed to send deliveries from outbox", sl.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliverDue attempts due deliveries until there are no more of them. Due
+// deliveries have distinct recipients, so they are attempted concurrently
+// without reordering notifications of a channel.
+func (d *Dispatcher) deliverDue(ctx context.Context) error {
+	for {
+		now := time.Now()
+		deliveries, err := d.outbox.GetDueDeliveries(ctx, now, d.config.Outbox.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get due deliveries: %w", err)
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		var g errgroup.Group
+		g.SetLimit(d.config.Outbox.Workers)
+		for _, delivery := range deliveries {
+			g.Go(func() error {
+				return d.attempt(ctx, delivery, now)
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return err
+		}
+	}
+}
+
+// attempt claims the delivery and sends it once. A failed delivery is
+// retried with exponential backoff until it runs out of attempts, and a
+// delivery to a channel without notifier fails at once.
+func (d *Dispatcher) attempt(
+	ctx context.Context,
+	delivery model.NotificationDelivery,
+	now time.Time,
+) error {
+	// claim outlives the attempt, so that the delivery is not attempted
+	// twice and is retried if the notifier stops during the attempt
+	claimed, err := d.outbox.ClaimDelivery(ctx, delivery.Id, now, now.Add(2*d.config.NotifyTimeoutSec))
+	if err != nil {
+		return fmt.Errorf("failed to claim delivery: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	err = d.notify(WithDeliveryId(ctx, delivery.Id), delivery.Channel, delivery.Notification)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	delivery.Attempts++
+	logAttrs := []any{
+		slog.Int64("delivery_id", delivery.Id),
+		slog.String("channel", delivery.Channel.Name),
+		slog.String("type", string(delivery.Channel.Type)),
+		slog.Int("attempt", delivery.Attempts),
+		sl.Notification(delivery.Notification),
+	}
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+	case errors.Is(err, ErrNoNotifier) || delivery.Attempts >= d.config.Outbox.MaxAttempts:
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.LastError = err.Error()
+		slog.Error("notification delivery failed", append(logAttrs, sl.Error(err))...)
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+		slog.Warn(
+			"failed attempt of notification delivery",
+			append(logAttrs, slog.Time("next_attempt_at", delivery.NextAttemptAt), sl.Error(err))...,
+		)
+	}
+
+	// outcome of the attempt is saved even if the context is done
+	if err := d.outbox.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt after attempts failed
+// ones.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.config.Outbox.BackoffSec
+	for i := 1; i < attempts && backoff < d.config.Outbox.MaxBackoffSec; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.config.Outbox.MaxBackoffSec)
+}
+
+func (d *Dispatcher) notify(
+	ctx context.Context,
+	channel model.Channel,
+	notification model.Notification,
+) error {
+	notifier, err := New(channel)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.config.NotifyTimeoutSec)
+	defer cancel()
+
+	slog.Info(
+		"sending notification to channel",
+		slog.String("channel", channel.Name),
+		slog.String("type", string(channel.Type)),
+		sl.Notification(notification),
+	)
+	return notifier.Notify(ctx, notification)
+}
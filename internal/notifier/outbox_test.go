@@ -0,0 +1,350 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"shm/internal/service"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testChannelType model.ChannelType = "test"
+
+var (
+	registerTestChannel sync.Once
+	// testNotify is called by notifiers of test channels
+	testNotify func(ctx context.Context, notification model.Notification) error
+)
+
+type testNotifier struct{}
+
+func (testNotifier) Notify(ctx context.Context, notification model.Notification) error {
+	return testNotify(ctx, notification)
+}
+
+// newOutboxDispatcher returns a dispatcher with the outbox in a new SQLite
+// database. Notifications to test channels are passed to notify.
+func newOutboxDispatcher(
+	t *testing.T,
+	notify func(ctx context.Context, notification model.Notification) error,
+) *Dispatcher {
+	t.Helper()
+	registerTestChannel.Do(func() {
+		Register(testChannelType, func(channel model.Channel) (Notifier, error) {
+			return testNotifier{}, nil
+		})
+	})
+	testNotify = notify
+
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	common := config.CommonConfig{DbQueryTimeoutSec: 5 * time.Second}
+	return NewDispatcher(
+		nil, nil, nil, nil,
+		service.NewNotificationDeliveriesService(database.NotificationDeliveriesRepo(), common),
+		config.NotifierConfig{
+			NotifyTimeoutSec: time.Minute,
+			Outbox: config.OutboxConfig{
+				MaxAttempts:   3,
+				BackoffSec:    time.Minute,
+				MaxBackoffSec: time.Hour,
+				BatchSize:     10,
+				Workers:       4,
+			},
+			CommonConfig: common,
+		},
+	)
+}
+
+func testChannel(name string) model.Channel {
+	return model.Channel{Name: name, Type: testChannelType, Config: model.ChannelConfig(`{"name":"` + name + `"}`)}
+}
+
+func addDelivery(t *testing.T, d *Dispatcher, notification model.Notification, channel model.Channel, now time.Time) {
+	t.Helper()
+	delivery := model.NewNotificationDelivery(notification, channel, now)
+	if err := d.outbox.AddDeliveries(context.Background(), []model.NotificationDelivery{delivery}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dueDeliveries(t *testing.T, d *Dispatcher, now time.Time) []model.NotificationDelivery {
+	t.Helper()
+	deliveries, err := d.outbox.GetDueDeliveries(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{config: config.NotifierConfig{
+		Outbox: config.OutboxConfig{BackoffSec: 30 * time.Second, MaxBackoffSec: 5 * time.Minute},
+	}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 5, want: 5 * time.Minute},
+		{attempts: 10, want: 5 * time.Minute},
+		{attempts: 1000, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestAttemptClaimsDelivery(t *testing.T) {
+	sent := 0
+	d := newOutboxDispatcher(t, func(ctx context.Context, notification model.Notification) error {
+		sent++
+		return nil
+	})
+	ctx := context.Background()
+	now := time.Now()
+	addDelivery(t, d, model.Notification{Message: "down"}, testChannel("a"), now)
+
+	delivery := dueDeliveries(t, d, now)[0]
+	// the same due delivery is attempted by two workers
+	for range 2 {
+		if err := d.attempt(ctx, delivery, now); err != nil {
+			t.Fatalf("attempt() error = %v", err)
+		}
+	}
+	if sent != 1 {
+		t.Errorf("delivery was sent %d times, want 1", sent)
+	}
+}
+
+func TestClaimExpires(t *testing.T) {
+	d := newOutboxDispatcher(t, func(ctx context.Context, notification model.Notification) error {
+		return nil
+	})
+	ctx := context.Background()
+	now := time.Now()
+	addDelivery(t, d, model.Notification{Message: "down"}, testChannel("a"), now)
+	delivery := dueDeliveries(t, d, now)[0]
+
+	// the notifier stops after claiming the delivery
+	until := now.Add(2 * d.config.NotifyTimeoutSec)
+	claimed, err := d.outbox.ClaimDelivery(ctx, delivery.Id, now, until)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimDelivery() = %v, %v", claimed, err)
+	}
+
+	if due := dueDeliveries(t, d, until.Add(-time.Second)); len(due) != 0 {
+		t.Errorf("claimed delivery is due before its claim expires: %v", due)
+	}
+	due := dueDeliveries(t, d, until)
+	if len(due) != 1 {
+		t.Fatalf("got %d due deliveries after the claim expired, want 1", len(due))
+	}
+	if err := d.attempt(ctx, due[0], until); err != nil {
+		t.Fatalf("attempt() error = %v", err)
+	}
+
+	got, err := d.outbox.GetDeliveryById(ctx, delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.DeliveryStatusDelivered || got.Attempts != 1 {
+		t.Errorf("delivery is %s after %d attempts, want delivered after 1", got.Status, got.Attempts)
+	}
+}
+
+func TestAttemptRetries(t *testing.T) {
+	d := newOutboxDispatcher(t, func(ctx context.Context, notification model.Notification) error {
+		return errors.New("service unavailable")
+	})
+	ctx := context.Background()
+	now := time.Now()
+	addDelivery(t, d, model.Notification{Message: "down"}, testChannel("a"), now)
+
+	tests := []struct {
+		name         string
+		wantStatus   model.DeliveryStatus
+		wantAttempts int
+	}{
+		{name: "first attempt is retried", wantStatus: model.DeliveryStatusPending, wantAttempts: 1},
+		{name: "second attempt is retried", wantStatus: model.DeliveryStatusPending, wantAttempts: 2},
+		{name: "last attempt fails", wantStatus: model.DeliveryStatusFailed, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due := dueDeliveries(t, d, now)
+			if len(due) != 1 {
+				t.Fatalf("got %d due deliveries, want 1", len(due))
+			}
+			attemptedAt := time.Now()
+			if err := d.attempt(ctx, due[0], now); err != nil {
+				t.Fatalf("attempt() error = %v", err)
+			}
+
+			got, err := d.outbox.GetDeliveryById(ctx, due[0].Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus || got.Attempts != tt.wantAttempts {
+				t.Errorf("delivery is %s after %d attempts, want %s after %d",
+					got.Status, got.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if got.LastError != "service unavailable" {
+				t.Errorf("LastError = %q", got.LastError)
+			}
+			if got.Status == model.DeliveryStatusPending {
+				if got.NextAttemptAt.Before(attemptedAt.Add(d.backoff(got.Attempts))) {
+					t.Errorf("next attempt at %s is before the backoff", got.NextAttemptAt)
+				}
+				// time passes until the next attempt
+				now = got.NextAttemptAt
+			}
+		})
+	}
+}
+
+func TestAttemptFailsWithoutNotifier(t *testing.T) {
+	d := newOutboxDispatcher(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+	addDelivery(t, d, model.Notification{Message: "down"}, model.Channel{Name: "a", Type: "unknown"}, now)
+
+	delivery := dueDeliveries(t, d, now)[0]
+	if err := d.attempt(ctx, delivery, now); err != nil {
+		t.Fatalf("attempt() error = %v", err)
+	}
+
+	got, err := d.outbox.GetDeliveryById(ctx, delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.DeliveryStatusFailed || got.Attempts != 1 {
+		t.Errorf("delivery is %s after %d attempts, want failed after 1", got.Status, got.Attempts)
+	}
+}
+
+func TestDeliverDueKeepsOrderOfRecipient(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	failing := map[string]bool{"a1": true}
+	d := newOutboxDispatcher(t, func(ctx context.Context, notification model.Notification) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing[notification.Message] {
+			return errors.New("service unavailable")
+		}
+		sent = append(sent, notification.Message)
+		return nil
+	})
+	ctx := context.Background()
+	now := time.Now().Add(-time.Second)
+	addDelivery(t, d, model.Notification{Message: "a1"}, testChannel("a"), now)
+	addDelivery(t, d, model.Notification{Message: "a2"}, testChannel("a"), now)
+	addDelivery(t, d, model.Notification{Message: "b1"}, testChannel("b"), now)
+
+	if err := d.deliverDue(ctx); err != nil {
+		t.Fatalf("deliverDue() error = %v", err)
+	}
+	// a2 waits for the retry of a1
+	if want := []string{"b1"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+
+	delete(failing, "a1")
+	pending, err := d.outbox.GetDeliveries(ctx, model.DeliveryStatusPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range pending {
+		if delivery.Notification.Message == "a1" {
+			delivery.NextAttemptAt = now
+			if err := d.outbox.UpdateDelivery(ctx, delivery); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := d.deliverDue(ctx); err != nil {
+		t.Fatalf("deliverDue() error = %v", err)
+	}
+	if want := []string{"b1", "a1", "a2"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	fail := true
+	sent := 0
+	d := newOutboxDispatcher(t, func(ctx context.Context, notification model.Notification) error {
+		if fail {
+			return errors.New("service unavailable")
+		}
+		sent++
+		return nil
+	})
+	ctx := context.Background()
+	now := time.Now()
+
+	// a notification consumed again adds no deliveries
+	notification := model.Notification{Id: 7, Message: "down"}
+	addDelivery(t, d, notification, testChannel("a"), now)
+	addDelivery(t, d, notification, testChannel("a"), now)
+	due := dueDeliveries(t, d, now)
+	if len(due) != 1 {
+		t.Fatalf("got %d deliveries of the notification, want 1", len(due))
+	}
+
+	delivery := due[0]
+	for range d.config.Outbox.MaxAttempts {
+		if err := d.attempt(ctx, delivery, now); err != nil {
+			t.Fatalf("attempt() error = %v", err)
+		}
+		got, err := d.outbox.GetDeliveryById(ctx, delivery.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		delivery = *got
+		now = delivery.NextAttemptAt
+	}
+	if delivery.Status != model.DeliveryStatusFailed {
+		t.Fatalf("delivery is %s, want failed", delivery.Status)
+	}
+
+	// failed delivery is replayed the way the server does it
+	fail = false
+	delivery.Status = model.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	if err := d.outbox.UpdateDelivery(ctx, delivery); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.attempt(ctx, delivery, now); err != nil {
+		t.Fatalf("attempt() error = %v", err)
+	}
+
+	got, err := d.outbox.GetDeliveryById(ctx, delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || got.Status != model.DeliveryStatusDelivered || got.Attempts != 1 {
+		t.Errorf("replayed delivery is %s after %d attempts and was sent %d times", got.Status, got.Attempts, sent)
+	}
+}
@@ -0,0 +1,125 @@
+package paging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"shm/internal/model"
+	"shm/internal/notifier"
+)
+
+const opsgenieBaseUrl = "https://api.opsgenie.com"
+
+// opsgenie rejects messages of alerts longer than this
+const opsgenieMaxMessage = 130
+
+type opsgenieConfig struct {
+	ApiKey  string `json:"apiKey"`
+	BaseUrl string `json:"baseUrl"`
+}
+
+// Opsgenie creates and closes Opsgenie alerts with the Alert API, the alias
+// of an alert is its dedup key.
+type Opsgenie struct {
+	client *http.Client
+	config opsgenieConfig
+}
+
+func newOpsgenie(client *http.Client, channel model.Channel) (*Opsgenie, error) {
+	var config opsgenieConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, err
+	}
+	if config.ApiKey == "" {
+		return nil, fmt.Errorf("api key is required")
+	}
+
+	var err error
+	if config.BaseUrl, err = parseBaseUrl(config.BaseUrl, opsgenieBaseUrl); err != nil {
+		return nil, err
+	}
+	return &Opsgenie{client: client, config: config}, nil
+}
+
+func (o *Opsgenie) Notify(ctx context.Context, notification model.Notification) error {
+	action := actionFor(notification.Event)
+	if action == actionNone {
+		return nil
+	}
+
+	notification = notifier.Redact(notification)
+	alias, replaced, err := dedupKeys(notification)
+	if err != nil {
+		return err
+	}
+
+	if action == actionResolve {
+		return o.close(ctx, alias, notification.Message)
+	}
+	if replaced != "" {
+		if err := o.close(ctx, replaced, notification.Message); err != nil {
+			return err
+		}
+	}
+
+	message := []rune(notification.Message)
+	if len(message) > opsgenieMaxMessage {
+		message = message[:opsgenieMaxMessage]
+	}
+	alert := map[string]any{
+		"message":     string(message),
+		"alias":       alias,
+		"description": notification.Message,
+		"source":      "shm",
+		"entity":      notification.Url,
+		"priority":    opsgeniePriority(notification.Event.Severity()),
+		"details":     stringDetails(details(notification)),
+	}
+	if err := notifier.SendJSON(ctx, o.client, http.MethodPost, o.config.BaseUrl+"/v2/alerts", o.header(), alert); err != nil {
+		return fmt.Errorf("failed to create opsgenie alert: %w", err)
+	}
+	return nil
+}
+
+func (o *Opsgenie) close(ctx context.Context, alias string, note string) error {
+	url := fmt.Sprintf(
+		"%s/v2/alerts/%s/close?identifierType=alias",
+		o.config.BaseUrl,
+		neturl.PathEscape(alias),
+	)
+	body := map[string]any{
+		"source": "shm",
+		"note":   note,
+	}
+	if err := notifier.SendJSON(ctx, o.client, http.MethodPost, url, o.header(), body); err != nil {
+		return fmt.Errorf("failed to close opsgenie alert: %w", err)
+	}
+	return nil
+}
+
+func (o *Opsgenie) header() http.Header {
+	return http.Header{"Authorization": {"GenieKey " + o.config.ApiKey}}
+}
+
+func opsgeniePriority(severity model.Severity) string {
+	switch severity {
+	case model.SeverityCritical:
+		return "P1"
+	case model.SeverityWarning:
+		return "P3"
+	default:
+		return "P5"
+	}
+}
+
+// stringDetails converts details to strings, since opsgenie accepts only
+// string values of details.
+func stringDetails(details map[string]any) map[string]string {
+	result := make(map[string]string, len(details))
+	for key, value := range details {
+		result[key] = fmt.Sprint(value)
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package paging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"time"
+)
+
+const pagerDutyBaseUrl = "https://events.pagerduty.com"
+
+type pagerDutyConfig struct {
+	RoutingKey string `json:"routingKey"`
+	BaseUrl    string `json:"baseUrl"`
+}
+
+// PagerDuty sends incidents to PagerDuty with Events API v2.
+type PagerDuty struct {
+	client *http.Client
+	config pagerDutyConfig
+}
+
+func newPagerDuty(client *http.Client, channel model.Channel) (*PagerDuty, error) {
+	var config pagerDutyConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, err
+	}
+	if config.RoutingKey == "" {
+		return nil, fmt.Errorf("routing key is required")
+	}
+
+	var err error
+	if config.BaseUrl, err = parseBaseUrl(config.BaseUrl, pagerDutyBaseUrl); err != nil {
+		return nil, err
+	}
+	return &PagerDuty{client: client, config: config}, nil
+}
+
+func (p *PagerDuty) Notify(ctx context.Context, notification model.Notification) error {
+	action := actionFor(notification.Event)
+	if action == actionNone {
+		return nil
+	}
+
+	// paging tools keep alerts and show them to every responder
+	notification = notifier.Redact(notification)
+	key, replaced, err := dedupKeys(notification)
+	if err != nil {
+		return err
+	}
+
+	if action == actionResolve {
+		return p.resolve(ctx, key)
+	}
+	if replaced != "" {
+		if err := p.resolve(ctx, replaced); err != nil {
+			return err
+		}
+	}
+
+	event := map[string]any{
+		"routing_key":  p.config.RoutingKey,
+		"dedup_key":    key,
+		"event_action": "trigger",
+		"payload": map[string]any{
+			"summary":        notification.Message,
+			"source":         notification.Url,
+			"severity":       pagerDutySeverity(notification.Event.Severity()),
+			"timestamp":      time.Now().UTC().Format(time.RFC3339),
+			"component":      notification.Url,
+			"custom_details": details(notification),
+		},
+	}
+	if err := notifier.SendJSON(ctx, p.client, http.MethodPost, p.config.BaseUrl+"/v2/enqueue", nil, event); err != nil {
+		return fmt.Errorf("failed to send event to pagerduty: %w", err)
+	}
+	return nil
+}
+
+func (p *PagerDuty) resolve(ctx context.Context, key string) error {
+	event := map[string]any{
+		"routing_key":  p.config.RoutingKey,
+		"dedup_key":    key,
+		"event_action": "resolve",
+	}
+	if err := notifier.SendJSON(ctx, p.client, http.MethodPost, p.config.BaseUrl+"/v2/enqueue", nil, event); err != nil {
+		return fmt.Errorf("failed to resolve pagerduty alert: %w", err)
+	}
+	return nil
+}
+
+func pagerDutySeverity(severity model.Severity) string {
+	switch severity {
+	case model.SeverityCritical:
+		return "critical"
+	case model.SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
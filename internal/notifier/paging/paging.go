@@ -0,0 +1,96 @@
+package paging
+
+import (
+	"fmt"
+	neturl "net/url"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"strings"
+)
+
+type action int
+
+const (
+	actionNone action = iota
+	actionTrigger
+	actionResolve
+)
+
+// actionFor maps the lifecycle of incidents to paging events: an incident
+// triggers an alert when it is opened and resolves it when it ends. Other
+// notifications are not sent to paging tools.
+func actionFor(event model.NotificationEvent) action {
+	switch event {
+	case model.NotificationEventDown, model.NotificationEventDegraded:
+		return actionTrigger
+	case model.NotificationEventUp, model.NotificationEventRecovered:
+		return actionResolve
+	default:
+		return actionNone
+	}
+}
+
+// dedupKey identifies the alert of an incident in the paging tool, so that
+// a degraded incident and the down incident replacing it are separate
+// alerts with their own severity.
+func dedupKey(siteId int64, incidentId int64) string {
+	return fmt.Sprintf("shm-site-%d-incident-%d", siteId, incidentId)
+}
+
+// dedupKeys returns the key of the incident of the notification and the key
+// of the incident it replaced, or empty string if it replaced none. The
+// replaced incident is resolved when the new one triggers.
+func dedupKeys(notification model.Notification) (string, string, error) {
+	if notification.Site == nil || notification.IncidentId == 0 {
+		return "", "", fmt.Errorf("notification about %s has no incident", notification.Event)
+	}
+
+	key := dedupKey(notification.Site.Id, notification.IncidentId)
+	if notification.ReplacedIncidentId == 0 {
+		return key, "", nil
+	}
+	return key, dedupKey(notification.Site.Id, notification.ReplacedIncidentId), nil
+}
+
+func details(notification model.Notification) map[string]any {
+	details := map[string]any{
+		"url":   notification.Url,
+		"event": notification.Event,
+	}
+	if incident := notification.Incident; incident != nil {
+		details["incident_id"] = incident.Id
+		details["cause"] = incident.Cause
+		details["started_at"] = incident.StartedAt
+	}
+	if result := notification.Result; result != nil {
+		if result.Code.Valid {
+			details["status_code"] = result.Code.Int64
+		}
+		if result.Latency.Valid {
+			details["latency_ms"] = result.Latency.Int64
+		}
+	}
+	return details
+}
+
+func parseBaseUrl(baseUrl string, defaultUrl string) (string, error) {
+	if baseUrl == "" {
+		return defaultUrl, nil
+	}
+	u, err := neturl.Parse(baseUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("base url must be http or https url")
+	}
+	return strings.TrimSuffix(baseUrl, "/"), nil
+}
+
+// Register registers pagerduty and opsgenie channel types.
+func Register() {
+	client := notifier.NewClient()
+	notifier.Register(model.ChannelTypePagerDuty, func(channel model.Channel) (notifier.Notifier, error) {
+		return newPagerDuty(client, channel)
+	})
+	notifier.Register(model.ChannelTypeOpsgenie, func(channel model.Channel) (notifier.Notifier, error) {
+		return newOpsgenie(client, channel)
+	})
+}
@@ -0,0 +1,73 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"shm/internal/model"
+	"shm/internal/notifier"
+)
+
+type gotifyConfig struct {
+	BaseUrl string `json:"baseUrl"`
+	// Token is the token of a gotify application
+	Token string `json:"token"`
+}
+
+// Gotify sends notifications as messages of a gotify application.
+type Gotify struct {
+	client *http.Client
+	config gotifyConfig
+}
+
+func newGotify(client *http.Client, channel model.Channel) (*Gotify, error) {
+	var config gotifyConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, err
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("application token is required")
+	}
+
+	var err error
+	if config.BaseUrl, err = parseBaseUrl(config.BaseUrl); err != nil {
+		return nil, err
+	}
+	return &Gotify{client: client, config: config}, nil
+}
+
+func (g *Gotify) Notify(ctx context.Context, notification model.Notification) error {
+	notification = notifier.Redact(notification)
+	message := map[string]any{
+		"title":    notification.Event.Title(),
+		"message":  notification.Message,
+		"priority": gotifyPriority(notification.Event.Severity()),
+		"extras": map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]string{"url": notification.Url},
+			},
+		},
+	}
+
+	header := http.Header{"X-Gotify-Key": {g.config.Token}}
+	if err := notifier.SendJSON(ctx, g.client, http.MethodPost, g.config.BaseUrl+"/message", header, message); err != nil {
+		return fmt.Errorf("failed to send gotify message: %w", err)
+	}
+	return nil
+}
+
+// gotifyPriority returns priority from 0 to 10. Gotify clients make a sound
+// from 4 and show a popup from 8.
+func gotifyPriority(severity model.Severity) int {
+	switch severity {
+	case model.SeverityCritical:
+		return 8
+	case model.SeverityWarning:
+		return 5
+	case model.SeverityOk:
+		return 4
+	default:
+		return 1
+	}
+}
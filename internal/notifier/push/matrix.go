@@ -0,0 +1,110 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	neturl "net/url"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type matrixConfig struct {
+	HomeserverUrl string `json:"homeserverUrl"`
+	AccessToken   string `json:"accessToken"`
+	RoomId        string `json:"roomId"`
+}
+
+// Matrix sends notifications to a Matrix room with the client-server API.
+// The user of the access token must have joined the room.
+type Matrix struct {
+	client *http.Client
+	config matrixConfig
+	txn    atomic.Int64
+}
+
+func newMatrix(client *http.Client, channel model.Channel) (*Matrix, error) {
+	var config matrixConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, err
+	}
+	if config.AccessToken == "" {
+		return nil, fmt.Errorf("access token is required")
+	}
+	if !strings.HasPrefix(config.RoomId, "!") || !strings.Contains(config.RoomId, ":") {
+		return nil, fmt.Errorf("room id must look like !room:server")
+	}
+
+	var err error
+	if config.HomeserverUrl, err = parseBaseUrl(config.HomeserverUrl); err != nil {
+		return nil, err
+	}
+	return &Matrix{client: client, config: config}, nil
+}
+
+func (m *Matrix) Notify(ctx context.Context, notification model.Notification) error {
+	// rooms keep the history of messages for all of their members
+	notification = notifier.Redact(notification)
+	title := notification.Event.Title()
+	event := map[string]string{
+		"msgtype":        "m.notice",
+		"body":           title + "\n" + notification.Message + "\n" + notification.Url,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": matrixHTML(title, notification),
+	}
+
+	url := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.config.HomeserverUrl,
+		neturl.PathEscape(m.config.RoomId),
+		m.txnId(ctx),
+	)
+	header := http.Header{"Authorization": {"Bearer " + m.config.AccessToken}}
+	if err := notifier.SendJSON(ctx, m.client, http.MethodPut, url, header, event); err != nil {
+		return fmt.Errorf("failed to send matrix message: %w", err)
+	}
+	return nil
+}
+
+// txnId returns a transaction id which the homeserver uses to deduplicate
+// retried requests. It is derived from the outbox delivery, so retries of a
+// message which reached the room are not posted again. Messages sent outside
+// of the outbox get a unique id.
+func (m *Matrix) txnId(ctx context.Context) string {
+	if deliveryId, ok := notifier.DeliveryId(ctx); ok {
+		return "shm-delivery-" + strconv.FormatInt(deliveryId, 10)
+	}
+	return "shm-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatInt(m.txn.Add(1), 36)
+}
+
+func matrixHTML(title string, notification model.Notification) string {
+	var b strings.Builder
+	b.WriteString("<p><strong>" + html.EscapeString(title) + "</strong></p>")
+	b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(notification.Message), "\n", "<br>") + "</p>")
+	b.WriteString("<ul>")
+	b.WriteString(fmt.Sprintf(
+		`<li>Site: <a href="%s">%s</a></li>`,
+		html.EscapeString(notification.Url),
+		html.EscapeString(notification.Url),
+	))
+	if incident := notification.Incident; incident != nil {
+		duration := incident.Duration(time.Now()).Round(time.Second)
+		b.WriteString("<li>Duration: " + duration.String() + "</li>")
+	}
+	if result := notification.Result; result != nil {
+		if result.Code.Valid {
+			b.WriteString(fmt.Sprintf("<li>Status code: %d</li>", result.Code.Int64))
+		}
+		if result.Latency.Valid {
+			b.WriteString(fmt.Sprintf("<li>Latency: %d ms</li>", result.Latency.Int64))
+		}
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
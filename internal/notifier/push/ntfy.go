@@ -0,0 +1,94 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"shm/internal/model"
+	"shm/internal/notifier"
+)
+
+const ntfyBaseUrl = "https://ntfy.sh"
+
+var ntfyTopicRegexp = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+type ntfyConfig struct {
+	BaseUrl string `json:"baseUrl"`
+	Topic   string `json:"topic"`
+	// Token is an access token of a protected topic
+	Token string `json:"token"`
+}
+
+// Ntfy publishes notifications to a ntfy topic.
+type Ntfy struct {
+	client *http.Client
+	config ntfyConfig
+}
+
+func newNtfy(client *http.Client, channel model.Channel) (*Ntfy, error) {
+	config := ntfyConfig{BaseUrl: ntfyBaseUrl}
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, err
+	}
+	if !ntfyTopicRegexp.MatchString(config.Topic) {
+		return nil, fmt.Errorf("invalid ntfy topic %q", config.Topic)
+	}
+
+	var err error
+	if config.BaseUrl, err = parseBaseUrl(config.BaseUrl); err != nil {
+		return nil, err
+	}
+	return &Ntfy{client: client, config: config}, nil
+}
+
+func (n *Ntfy) Notify(ctx context.Context, notification model.Notification) error {
+	notification = notifier.Redact(notification)
+	severity := notification.Event.Severity()
+	message := map[string]any{
+		"topic":    n.config.Topic,
+		"title":    notification.Event.Title(),
+		"message":  notification.Message,
+		"priority": ntfyPriority(severity),
+		"tags":     []string{ntfyTag(severity), string(notification.Event)},
+		"click":    notification.Url,
+	}
+
+	var header http.Header
+	if n.config.Token != "" {
+		header = http.Header{"Authorization": {"Bearer " + n.config.Token}}
+	}
+	if err := notifier.SendJSON(ctx, n.client, http.MethodPost, n.config.BaseUrl, header, message); err != nil {
+		return fmt.Errorf("failed to publish to ntfy: %w", err)
+	}
+	return nil
+}
+
+// ntfyPriority returns priority from 1 (min) to 5 (max).
+func ntfyPriority(severity model.Severity) int {
+	switch severity {
+	case model.SeverityCritical:
+		return 5
+	case model.SeverityWarning:
+		return 4
+	case model.SeverityOk:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// ntfyTag returns a tag which ntfy displays as an emoji.
+func ntfyTag(severity model.Severity) string {
+	switch severity {
+	case model.SeverityCritical:
+		return "rotating_light"
+	case model.SeverityWarning:
+		return "warning"
+	case model.SeverityOk:
+		return "white_check_mark"
+	default:
+		return "information_source"
+	}
+}
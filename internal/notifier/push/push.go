@@ -0,0 +1,31 @@
+package push
+
+import (
+	"fmt"
+	neturl "net/url"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"strings"
+)
+
+func parseBaseUrl(baseUrl string) (string, error) {
+	u, err := neturl.Parse(baseUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("server url must be http or https url")
+	}
+	return strings.TrimSuffix(baseUrl, "/"), nil
+}
+
+// Register registers ntfy, gotify and matrix channel types.
+func Register() {
+	client := notifier.NewClient()
+	notifier.Register(model.ChannelTypeNtfy, func(channel model.Channel) (notifier.Notifier, error) {
+		return newNtfy(client, channel)
+	})
+	notifier.Register(model.ChannelTypeGotify, func(channel model.Channel) (notifier.Notifier, error) {
+		return newGotify(client, channel)
+	})
+	notifier.Register(model.ChannelTypeMatrix, func(channel model.Channel) (notifier.Notifier, error) {
+		return newMatrix(client, channel)
+	})
+}
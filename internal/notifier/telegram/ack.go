@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+var ackButton = telebot.Btn{Unique: "ack"}
+
+// ackMarkup returns the inline "Acknowledge" button for notifications about
+// open incidents, or nil for other notifications.
+func ackMarkup(notification model.Notification) *telebot.ReplyMarkup {
+	if notification.IncidentId == 0 {
+		return nil
+	}
+	if notification.Event != model.NotificationEventDown &&
+		notification.Event != model.NotificationEventEscalation {
+		return nil
+	}
+
+	markup := &telebot.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data(
+		"Acknowledge",
+		ackButton.Unique,
+		strconv.FormatInt(notification.IncidentId, 10),
+	)))
+	return markup
+}
+
+func (t *TGBot) ackCallback(c telebot.Context) error {
+	chatId := c.Chat().ID
+	data := c.Callback().Data
+
+	slog.Info("acknowledge callback", slog.Int64("chat_id", chatId), slog.String("data", data))
+
+	incidentId, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "Invalid incident!"})
+	}
+
+	// callback data comes from the client, so only chats which were notified
+	// about the incident may acknowledge it
+	ctx := context.Background()
+	incident, err := t.incidents.GetIncidentById(ctx, incidentId)
+	if err != nil {
+		slog.Error("failed to get incident", slog.Int64("incident_id", incidentId), sl.Error(err))
+		return c.Respond(&telebot.CallbackResponse{Text: "Failed to acknowledge!"})
+	}
+	if incident == nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "Invalid incident!"})
+	}
+	allowed, err := t.mayAcknowledge(ctx, chatId, *incident)
+	if err != nil {
+		slog.Error(
+			"failed to check if chat may acknowledge incident",
+			slog.Int64("chat_id", chatId),
+			slog.Int64("incident_id", incidentId),
+			sl.Error(err),
+		)
+		return c.Respond(&telebot.CallbackResponse{Text: "Failed to acknowledge!"})
+	}
+	if !allowed {
+		slog.Warn(
+			"chat may not acknowledge incident",
+			slog.Int64("chat_id", chatId),
+			slog.Int64("incident_id", incidentId),
+		)
+		return c.Respond(&telebot.CallbackResponse{Text: "Invalid incident!"})
+	}
+
+	by := c.Sender().Username
+	if by == "" {
+		by = strings.TrimSpace(c.Sender().FirstName + " " + c.Sender().LastName)
+	}
+
+	acked, err := t.incidents.AcknowledgeIncident(ctx, incidentId, by, time.Now())
+	if err != nil {
+		slog.Error(
+			"failed to acknowledge incident",
+			slog.Int64("incident_id", incidentId),
+			sl.Error(err),
+		)
+		return c.Respond(&telebot.CallbackResponse{Text: "Failed to acknowledge!"})
+	}
+	if !acked {
+		return c.Respond(&telebot.CallbackResponse{Text: "Already acknowledged or resolved"})
+	}
+
+	if err := c.Respond(&telebot.CallbackResponse{Text: "Acknowledged!"}); err != nil {
+		return err
+	}
+	return c.Edit(fmt.Sprintf("%s\n\nAcknowledged by %s.", c.Message().Text, by))
+}
+
+// mayAcknowledge reports whether the chat was notified about the incident:
+// it added the site of the incident or it is paged by a step of the
+// escalation policy of the site.
+func (t *TGBot) mayAcknowledge(ctx context.Context, chatId int64, incident model.Incident) (bool, error) {
+	hasSite, err := t.chats.HasSite(ctx, chatId, incident.SiteId)
+	if err != nil || hasSite {
+		return hasSite, err
+	}
+
+	site, err := t.sites.GetSiteById(ctx, incident.SiteId)
+	if err != nil || site == nil {
+		return false, err
+	}
+	policy, err := t.escalations.GetEffectivePolicy(ctx, *site)
+	if err != nil || policy == nil {
+		return false, err
+	}
+	for _, step := range policy.Steps {
+		if step.ChatId == chatId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,202 @@
+package telegram
+
+import (
+	"context"
+	"path/filepath"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"shm/internal/service"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+func TestAckMarkup(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification model.Notification
+		want         bool
+	}{
+		{
+			name:         "down",
+			notification: model.Notification{Event: model.NotificationEventDown, IncidentId: 5},
+			want:         true,
+		},
+		{
+			name:         "escalation",
+			notification: model.Notification{Event: model.NotificationEventEscalation, IncidentId: 5},
+			want:         true,
+		},
+		{
+			name:         "down without incident",
+			notification: model.Notification{Event: model.NotificationEventDown},
+		},
+		{
+			name:         "up",
+			notification: model.Notification{Event: model.NotificationEventUp, IncidentId: 5},
+		},
+		{
+			name:         "degraded",
+			notification: model.Notification{Event: model.NotificationEventDegraded, IncidentId: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markup := ackMarkup(tt.notification)
+			if (markup != nil) != tt.want {
+				t.Fatalf("ackMarkup() = %v, want markup %v", markup, tt.want)
+			}
+			if markup == nil {
+				return
+			}
+			button := markup.InlineKeyboard[0][0]
+			if button.Unique != ackButton.Unique || button.Data != "5" {
+				t.Errorf("button is %q with data %q, want %q with data 5", button.Unique, button.Data, ackButton.Unique)
+			}
+		})
+	}
+}
+
+// callbackContext is the context of a pressed button. It records the
+// responses of the handler.
+type callbackContext struct {
+	telebot.Context
+	chatId    int64
+	data      string
+	responses []string
+	edited    string
+}
+
+func (c *callbackContext) Chat() *telebot.Chat {
+	return &telebot.Chat{ID: c.chatId}
+}
+
+func (c *callbackContext) Sender() *telebot.User {
+	return &telebot.User{Username: "oncall"}
+}
+
+func (c *callbackContext) Callback() *telebot.Callback {
+	return &telebot.Callback{Data: c.data}
+}
+
+func (c *callbackContext) Message() *telebot.Message {
+	return &telebot.Message{Text: "Bad news."}
+}
+
+func (c *callbackContext) Respond(responses ...*telebot.CallbackResponse) error {
+	for _, response := range responses {
+		c.responses = append(c.responses, response.Text)
+	}
+	return nil
+}
+
+func (c *callbackContext) Edit(what interface{}, opts ...interface{}) error {
+	c.edited = what.(string)
+	return nil
+}
+
+func TestAckCallback(t *testing.T) {
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	common := config.CommonConfig{DbQueryTimeoutSec: 5 * time.Second}
+	bot := &TGBot{
+		chats:       service.NewChatsService(database.ChatsRepo(), common),
+		sites:       service.NewSitesService(database.SitesRepo(), common),
+		incidents:   service.NewIncidentsService(database.IncidentsRepo(), common),
+		escalations: service.NewEscalationPoliciesService(database.EscalationPoliciesRepo(), common),
+	}
+
+	const chatId, otherChatId, oncallChatId = 100, 200, 300
+	ctx := context.Background()
+	// openIncident adds the site from the chat and opens its incident
+	openIncident := func(url string) int64 {
+		t.Helper()
+		site := model.Site{Url: url}
+		if err := site.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		if err := bot.sites.AddSiteFromChat(ctx, chatId, site); err != nil {
+			t.Fatal(err)
+		}
+		added, err := bot.sites.GetSiteByUrl(ctx, site.Url)
+		if err != nil || added == nil {
+			t.Fatalf("GetSiteByUrl() = %v, %v", added, err)
+		}
+		incidentId, err := bot.incidents.OpenIncident(ctx, model.Incident{
+			SiteId:    added.Id,
+			Kind:      model.IncidentKindDown,
+			StartedAt: time.Now(),
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return incidentId
+	}
+
+	incidentId := openIncident("https://example.com")
+	data := strconv.FormatInt(incidentId, 10)
+	otherData := strconv.FormatInt(openIncident("https://other.example.com"), 10)
+
+	incident, err := bot.incidents.GetIncidentById(ctx, incidentId)
+	if err != nil || incident == nil {
+		t.Fatalf("GetIncidentById() = %v, %v", incident, err)
+	}
+	policy := model.EscalationPolicy{
+		SiteId: incident.SiteId,
+		Steps:  model.EscalationSteps{{DelayMin: 10, ChatId: oncallChatId}},
+	}
+	if _, err := bot.escalations.AddPolicy(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		chatId       int64
+		data         string
+		wantResponse string
+		wantEdited   bool
+	}{
+		{name: "invalid data", chatId: chatId, data: "ack", wantResponse: "Invalid incident!"},
+		{name: "unknown incident", chatId: chatId, data: "999", wantResponse: "Invalid incident!"},
+		{name: "chat without site", chatId: otherChatId, data: data, wantResponse: "Invalid incident!"},
+		{name: "chat of site", chatId: chatId, data: otherData, wantResponse: "Acknowledged!", wantEdited: true},
+		{name: "escalation step of other site", chatId: oncallChatId, data: otherData, wantResponse: "Invalid incident!"},
+		{name: "chat of escalation step", chatId: oncallChatId, data: data, wantResponse: "Acknowledged!", wantEdited: true},
+		{name: "acknowledged again", chatId: chatId, data: data, wantResponse: "Already acknowledged or resolved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &callbackContext{chatId: tt.chatId, data: tt.data}
+			if err := bot.ackCallback(c); err != nil {
+				t.Fatalf("ackCallback() error = %v", err)
+			}
+			if len(c.responses) != 1 || c.responses[0] != tt.wantResponse {
+				t.Errorf("responses = %q, want %q", c.responses, tt.wantResponse)
+			}
+			if edited := c.edited != ""; edited != tt.wantEdited {
+				t.Errorf("message was edited: %v, want %v", edited, tt.wantEdited)
+			}
+			if tt.wantEdited && !strings.HasSuffix(c.edited, "Acknowledged by oncall.") {
+				t.Errorf("edited message = %q", c.edited)
+			}
+		})
+	}
+
+	incident, err = bot.incidents.GetIncidentById(ctx, incidentId)
+	if err != nil || incident == nil {
+		t.Fatalf("GetIncidentById() = %v, %v", incident, err)
+	}
+	if incident.AckedBy != "oncall" {
+		t.Errorf("incident was acknowledged by %q, want oncall", incident.AckedBy)
+	}
+}
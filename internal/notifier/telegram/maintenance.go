@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+const maintenanceUsage = `Usage:
+/maintenance [url] - list maintenance windows of [url] site
+/maintenance [url] [duration_min] - start maintenance now
+/maintenance [url] [cron] [duration_min] - add recurring maintenance, e.g. /maintenance example.com 0 3 * * 0 60`
+
+func (t *TGBot) maintenanceCommand(c telebot.Context) error {
+	chatId := c.Chat().ID
+	args := c.Args()
+
+	slog.Info("maintenance command", slog.Int64("chat_id", chatId), slog.Any("args", args))
+
+	if len(args) != 1 && len(args) != 2 && len(args) != 7 {
+		return c.Reply(maintenanceUsage)
+	}
+
+	site, reply := t.chatSite(chatId, args[0])
+	if site == nil {
+		return c.Reply(reply)
+	}
+
+	if len(args) == 1 {
+		return t.listMaintenance(c, *site)
+	}
+
+	durationMin, err := strconv.Atoi(args[len(args)-1])
+	if err != nil {
+		return c.Reply("Invalid duration!")
+	}
+
+	window := model.MaintenanceWindow{
+		SiteId:   site.Id,
+		Announce: true,
+	}
+	if len(args) == 2 {
+		window.StartsAt = time.Now().UTC()
+		window.EndsAt = window.StartsAt.Add(time.Duration(durationMin) * time.Minute)
+	} else {
+		window.Cron = strings.Join(args[1:6], " ")
+		window.DurationMin = durationMin
+	}
+	if err := window.Normalize(); err != nil {
+		return c.Reply(fmt.Sprintf("Invalid maintenance window: %s", err))
+	}
+
+	if _, err := t.maintenance.AddWindow(context.Background(), window); err != nil {
+		slog.Error("failed to add maintenance window", slog.String("command", "maintenance"), sl.Error(err))
+		return nil
+	}
+
+	return c.Send(fmt.Sprintf("Successful! Maintenance of %s %s.", site.Url, window))
+}
+
+func (t *TGBot) listMaintenance(c telebot.Context, site model.Site) error {
+	windows, err := t.maintenance.GetAllWindows(context.Background())
+	if err != nil {
+		slog.Error(
+			"failed to get maintenance windows",
+			slog.String("command", "maintenance"),
+			sl.Error(err),
+		)
+		return nil
+	}
+
+	var b strings.Builder
+	for _, window := range windows {
+		if window.AppliesTo(site) {
+			fmt.Fprintf(&b, "%d) %s\n", window.Id, window)
+		}
+	}
+
+	result := b.String()
+	if len(result) == 0 {
+		return c.Send("The site has no maintenance windows")
+	}
+	return c.Send(result)
+}
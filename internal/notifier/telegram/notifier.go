@@ -0,0 +1,57 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"shm/internal/model"
+	"shm/internal/notifier"
+
+	"gopkg.in/telebot.v4"
+)
+
+type notifierConfig struct {
+	ChatId int64 `json:"chatId"`
+}
+
+// Notifier sends notifications to a single telegram chat.
+type Notifier struct {
+	bot    *telebot.Bot
+	chatId int64
+}
+
+// NewSender creates a bot which is only used to send messages, updates are
+// received by the bot started with New.
+func NewSender(token string) (*telebot.Bot, error) {
+	return telebot.NewBot(telebot.Settings{
+		Token:   token,
+		Offline: true,
+	})
+}
+
+// Register registers the telegram channel type, notifiers send messages
+// with the bot.
+func Register(bot *telebot.Bot) {
+	notifier.Register(model.ChannelTypeTelegram, func(channel model.Channel) (notifier.Notifier, error) {
+		var cfg notifierConfig
+		if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.ChatId == 0 {
+			return nil, fmt.Errorf("chat id is required")
+		}
+		return &Notifier{bot: bot, chatId: cfg.ChatId}, nil
+	})
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification model.Notification) error {
+	var opts []any
+	if markup := ackMarkup(notification); markup != nil {
+		opts = append(opts, markup)
+	}
+
+	if _, err := n.bot.Send(telebot.ChatID(n.chatId), notification.Message, opts...); err != nil {
+		return fmt.Errorf("failed to send message to chat: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"shm/internal/lib/sl"
+	urlpkg "shm/internal/lib/url"
+	"shm/internal/model"
+	"strconv"
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+func (t *TGBot) ruleCommand(c telebot.Context) error {
+	chatId := c.Chat().ID
+	payload := c.Message().Payload
+
+	slog.Info("rule command", slog.Int64("chat_id", chatId), slog.String("payload", payload))
+
+	url, condition, found := strings.Cut(strings.TrimSpace(payload), " ")
+	if !found {
+		return c.Reply(`Usage: /rule [url] [condition], e.g. /rule example.com {"type":"consecutive_failures","count":5}`)
+	}
+
+	site, reply := t.chatSite(chatId, url)
+	if site == nil {
+		return c.Reply(reply)
+	}
+
+	rule := model.AlertRule{SiteId: site.Id}
+	if err := json.Unmarshal([]byte(condition), &rule.Condition); err != nil {
+		return c.Reply("Invalid condition JSON!")
+	}
+	if err := rule.Normalize(); err != nil {
+		return c.Reply(fmt.Sprintf("Invalid rule: %s", err))
+	}
+
+	id, err := t.rules.AddRule(context.Background(), rule)
+	if err != nil {
+		slog.Error("failed to add rule", slog.String("command", "rule"), sl.Error(err))
+		return nil
+	}
+
+	return c.Send(fmt.Sprintf("Successful! Rule %d: %s", id, rule.Condition))
+}
+
+func (t *TGBot) rulesCommand(c telebot.Context) error {
+	chatId := c.Chat().ID
+	url := c.Message().Payload
+
+	slog.Info("rules command", slog.Int64("chat_id", chatId), slog.String("url", url))
+
+	site, reply := t.chatSite(chatId, url)
+	if site == nil {
+		return c.Reply(reply)
+	}
+
+	rules, err := t.rules.GetEffectiveRules(context.Background(), *site)
+	if err != nil {
+		slog.Error("failed to get rules for site", slog.String("command", "rules"), sl.Error(err))
+		return nil
+	}
+	if len(rules) == 0 {
+		return c.Send("The site has no rules, default one is used")
+	}
+
+	var b strings.Builder
+	for _, rule := range rules {
+		if rule.Tag != "" {
+			fmt.Fprintf(&b, "%d) %s (tag %s)\n", rule.Id, rule.Condition, rule.Tag)
+		} else {
+			fmt.Fprintf(&b, "%d) %s\n", rule.Id, rule.Condition)
+		}
+	}
+	return c.Send(b.String())
+}
+
+func (t *TGBot) deleteRuleCommand(c telebot.Context) error {
+	chatId := c.Chat().ID
+	payload := c.Message().Payload
+
+	slog.Info("delete rule command", slog.Int64("chat_id", chatId), slog.String("id", payload))
+
+	id, err := strconv.ParseInt(strings.TrimSpace(payload), 10, 64)
+	if err != nil {
+		return c.Reply("Usage: /delrule [id]")
+	}
+
+	ctx := context.Background()
+	rule, err := t.rules.GetRuleById(ctx, id)
+	if err != nil {
+		slog.Error("failed to get rule", slog.String("command", "delete rule"), sl.Error(err))
+		return nil
+	}
+	if rule == nil || !t.chatHasSite(chatId, rule.SiteId) {
+		return c.Reply("No rule with such id!")
+	}
+
+	if err := t.rules.DeleteRuleById(ctx, id); err != nil {
+		slog.Error("failed to delete rule", slog.String("command", "delete rule"), sl.Error(err))
+		return nil
+	}
+
+	return c.Send("Successful!")
+}
+
+// chatSite returns the site with the url among the sites of the chat. If
+// there is no such site, it returns a reply for the user instead.
+func (t *TGBot) chatSite(chatId int64, url string) (*model.Site, string) {
+	url, err := urlpkg.ConvertToExpectedUrl(url)
+	if err != nil {
+		return nil, "Invalid URL!"
+	}
+
+	sites, err := t.sites.GetAllSitesByChatId(context.Background(), chatId)
+	if err != nil {
+		slog.Error("failed to get all sites by chat id", sl.Error(err))
+		return nil, "Failed to get your sites!"
+	}
+	for _, site := range sites {
+		if site.Url == url {
+			return &site, ""
+		}
+	}
+	return nil, "You are not subscribed to this site!"
+}
+
+func (t *TGBot) chatHasSite(chatId int64, siteId int64) bool {
+	sites, err := t.sites.GetAllSitesByChatId(context.Background(), chatId)
+	if err != nil {
+		slog.Error("failed to get all sites by chat id", sl.Error(err))
+		return false
+	}
+	for _, site := range sites {
+		if site.Id == siteId {
+			return true
+		}
+	}
+	return false
+}
@@ -7,12 +7,12 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"shm/internal/broker"
 	"shm/internal/config"
 	"shm/internal/lib/sl"
 	urlpkg "shm/internal/lib/url"
 	"shm/internal/model"
 	"shm/internal/service"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -23,17 +23,23 @@ import (
 )
 
 type TGBot struct {
-	bot    *telebot.Bot
-	broker broker.MessageBroker
-	chats  *service.ChatsService
-	sites  *service.SitesService
-	config config.TelegramBotConfig
+	bot         *telebot.Bot
+	chats       *service.ChatsService
+	sites       *service.SitesService
+	rules       *service.AlertRulesService
+	maintenance *service.MaintenanceService
+	incidents   *service.IncidentsService
+	escalations *service.EscalationPoliciesService
+	config      config.TelegramBotConfig
 }
 
 func New(
-	broker broker.MessageBroker,
 	chats *service.ChatsService,
 	sites *service.SitesService,
+	rules *service.AlertRulesService,
+	maintenance *service.MaintenanceService,
+	incidents *service.IncidentsService,
+	escalations *service.EscalationPoliciesService,
 	config config.TelegramBotConfig,
 ) (*TGBot, error) {
 	bot, err := telebot.NewBot(telebot.Settings{
@@ -45,11 +51,14 @@ func New(
 	}
 
 	t := &TGBot{
-		bot:    bot,
-		broker: broker,
-		chats:  chats,
-		sites:  sites,
-		config: config,
+		bot:         bot,
+		chats:       chats,
+		sites:       sites,
+		rules:       rules,
+		maintenance: maintenance,
+		incidents:   incidents,
+		escalations: escalations,
+		config:      config,
 	}
 
 	bot.Handle("/start", t.startCommand)
@@ -58,6 +67,12 @@ func New(
 	bot.Handle("/add", t.addSiteCommand)
 	bot.Handle("/delete", t.deleteSiteCommand)
 	bot.Handle("/list", t.listCommand)
+	bot.Handle("/heartbeat", t.heartbeatCommand)
+	bot.Handle("/rule", t.ruleCommand)
+	bot.Handle("/rules", t.rulesCommand)
+	bot.Handle("/delrule", t.deleteRuleCommand)
+	bot.Handle("/maintenance", t.maintenanceCommand)
+	bot.Handle(&ackButton, t.ackCallback)
 
 	return t, nil
 }
@@ -68,16 +83,6 @@ func (t *TGBot) Start() {
 	defer stop()
 	g, ctx := errgroup.WithContext(ctx)
 
-	notifications, err := t.broker.ConsumeNotifications(ctx)
-	if err != nil {
-		slog.Error("failed to register a consumer for notifications", sl.Error(err))
-		return
-	}
-
-	g.Go(func() error {
-		return t.handleNotifications(ctx, notifications)
-	})
-
 	g.Go(func() error {
 		t.bot.Start()
 		return nil
@@ -94,53 +99,19 @@ func (t *TGBot) Start() {
 	}
 }
 
-func (t *TGBot) handleNotifications(
-	ctx context.Context,
-	notifications <-chan model.Notification,
-) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case notification, ok := <-notifications:
-			if !ok {
-				return fmt.Errorf("queue with notitications was closed")
-			}
-			if err := t.Notify(ctx, notification); err != nil {
-				return fmt.Errorf("failed to handle notification: %w", err)
-			}
-		}
-	}
-}
-
-func (t *TGBot) Notify(ctx context.Context, notification model.Notification) error {
-	chats, err := t.chats.GetAllSubscribedOnSiteChats(ctx, notification.Url)
-	if err != nil {
-		return err
-	}
-
-	for _, c := range chats {
-		slog.Info(
-			"sending notification to subscriber",
-			slog.Int64("chat_id", c.Id),
-			slog.String("message", notification.Message),
-		)
-		if _, err = t.bot.Send(telebot.ChatID(c.Id), notification.Message); err != nil {
-			return fmt.Errorf("failed to send message to chat: %w", err)
-		}
-	}
-
-	return nil
-}
-
 func (t *TGBot) startCommand(c telebot.Context) error {
 	slog.Info("start command", slog.Int64("chat_id", c.Chat().ID))
 	return c.Send(`Commands:
 	/subscribe - subscribe to updates
 	/unsubscribe - unsubscribe from updates
-	/add [url] - start monitoring [url] site
+	/add [url] - start monitoring [url] site (tcp://host:port for TCP port, dns://name for DNS name)
 	/delete [url] - stop monitoring [url] site
 	/list - get all monitored sites
+	/heartbeat [period_min] [grace_min] - create heartbeat for a job which pings every [period_min] minutes
+	/rule [url] [condition] - add alert rule with JSON condition for [url] site
+	/rules [url] - get alert rules of [url] site
+	/delrule [id] - delete alert rule
+	/maintenance [url] [cron] [duration_min] - add maintenance window for [url] site, run without arguments for details
 	`)
 }
 
@@ -182,7 +153,13 @@ func (t *TGBot) addSiteCommand(c telebot.Context) error {
 		return c.Reply("Invalid URL!")
 	}
 
-	if err := t.sites.AddSiteFromChat(context.Background(), chatId, url); err != nil {
+	site := model.Site{Url: url}
+	if err := site.Normalize(); err != nil {
+		slog.Error("invalid site", sl.Error(err), slog.String("url", url))
+		return c.Reply("Invalid URL!")
+	}
+
+	if err := t.sites.AddSiteFromChat(context.Background(), chatId, site); err != nil {
 		slog.Error(
 			"failed to add site",
 			slog.String("command", "add site"),
@@ -237,7 +214,7 @@ func (t *TGBot) listCommand(c telebot.Context) error {
 
 	var b strings.Builder
 	for i, site := range sites {
-		fmt.Fprintf(&b, "%d) %s\n", i+1, site.Url)
+		fmt.Fprintf(&b, "%d) %s\n", i+1, t.describeSite(site))
 	}
 
 	result := b.String()
@@ -246,3 +223,73 @@ func (t *TGBot) listCommand(c telebot.Context) error {
 	}
 	return c.Send(result)
 }
+
+func (t *TGBot) heartbeatCommand(c telebot.Context) error {
+	chatId := c.Chat().ID
+	args := c.Args()
+
+	slog.Info("heartbeat command", slog.Int64("chat_id", chatId), slog.Any("args", args))
+
+	if len(args) < 1 || len(args) > 2 {
+		return c.Reply("Usage: /heartbeat [period_min] [grace_min]")
+	}
+
+	periodMin, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.Reply("Invalid period!")
+	}
+	var graceMin int
+	if len(args) == 2 {
+		if graceMin, err = strconv.Atoi(args[1]); err != nil {
+			return c.Reply("Invalid grace time!")
+		}
+	}
+
+	site := model.Site{
+		Type: model.CheckTypeHeartbeat,
+		Heartbeat: model.HeartbeatSpec{
+			PeriodSec: int64(periodMin) * 60,
+			GraceSec:  int64(graceMin) * 60,
+		},
+	}
+	if err := site.Normalize(); err != nil {
+		slog.Error("invalid heartbeat", sl.Error(err))
+		return c.Reply(fmt.Sprintf("Invalid heartbeat: %s", err))
+	}
+
+	if err := t.sites.AddSiteFromChat(context.Background(), chatId, site); err != nil {
+		slog.Error(
+			"failed to add heartbeat",
+			slog.String("command", "heartbeat"),
+			sl.Error(err),
+		)
+		return nil
+	}
+
+	return c.Send(fmt.Sprintf(
+		"Successful! Send POST request to %s at least every %d minutes.",
+		t.pingUrl(site),
+		periodMin,
+	))
+}
+
+// pingUrl returns the url of the server which the heartbeat must be pinged
+// at.
+func (t *TGBot) pingUrl(site model.Site) string {
+	token, _ := site.HeartbeatToken()
+	return strings.TrimSuffix(t.config.HeartbeatBaseUrl, "/") + "/heartbeat/" + token
+}
+
+func (t *TGBot) describeSite(site model.Site) string {
+	if site.Type != model.CheckTypeHeartbeat {
+		return site.Url
+	}
+	// the url of the site identifies it in other commands
+	return fmt.Sprintf(
+		"%s, ping with POST %s (every %s, grace %s)",
+		site.Url,
+		t.pingUrl(site),
+		time.Duration(site.Heartbeat.PeriodSec)*time.Second,
+		time.Duration(site.Heartbeat.GraceSec)*time.Second,
+	)
+}
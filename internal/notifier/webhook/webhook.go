@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"shm/internal/model"
+	"shm/internal/notifier"
+	"strconv"
+	"time"
+)
+
+// PayloadVersion is increased on incompatible changes of Payload.
+const PayloadVersion = 2
+
+const (
+	// SignatureHeader contains "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of the request body keyed with the secret of the webhook.
+	SignatureHeader = "X-Shm-Signature-256"
+	EventHeader     = "X-Shm-Event"
+	// DeliveryHeader contains id of the outbox delivery, which is the same
+	// for all attempts of the delivery.
+	DeliveryHeader = "X-Shm-Delivery"
+)
+
+// Config is the config of a webhook channel.
+type Config struct {
+	Url        string `json:"url"`
+	Secret     string `json:"secret"`
+	TimeoutSec int    `json:"timeoutSec"`
+}
+
+func parseConfig(channel model.Channel) (Config, error) {
+	config := Config{TimeoutSec: 10}
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return Config{}, err
+	}
+
+	u, err := neturl.Parse(config.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Config{}, fmt.Errorf("webhook requires http or https url")
+	}
+	if config.Secret == "" {
+		return Config{}, fmt.Errorf("webhook requires a secret")
+	}
+	if config.TimeoutSec < 1 {
+		return Config{}, fmt.Errorf("webhook requires positive timeout")
+	}
+	return config, nil
+}
+
+// Payload is the JSON body posted to webhooks. It never contains specs of
+// the site, and secrets of the site url are redacted.
+type Payload struct {
+	Version    int                     `json:"version"`
+	Event      model.NotificationEvent `json:"event"`
+	Url        string                  `json:"url"`
+	Message    string                  `json:"message"`
+	Timestamp  time.Time               `json:"timestamp"`
+	Site       *model.SiteSummary      `json:"site,omitempty"`
+	Incident   *model.Incident         `json:"incident,omitempty"`
+	Transition *model.StateTransition  `json:"transition,omitempty"`
+	Result     *Result                 `json:"result,omitempty"`
+}
+
+// Result is the check result of Payload without the site it belongs to.
+type Result struct {
+	Id              int64              `json:"id"`
+	Time            time.Time          `json:"time"`
+	Latency         sql.NullInt64      `json:"latency"`
+	Code            sql.NullInt64      `json:"code"`
+	Timings         model.Timings      `json:"timings"`
+	Successful      bool               `json:"successful"`
+	FailureReason   string             `json:"failureReason,omitempty"`
+	AssertionFailed bool               `json:"assertionFailed,omitempty"`
+	Certificate     *model.Certificate `json:"certificate,omitempty"`
+	Answers         model.Strings      `json:"answers,omitempty"`
+}
+
+func newPayload(notification model.Notification, now time.Time) Payload {
+	notification = notifier.Redact(notification)
+	payload := Payload{
+		Version:    PayloadVersion,
+		Event:      notification.Event,
+		Url:        notification.Url,
+		Message:    notification.Message,
+		Timestamp:  now.UTC(),
+		Incident:   notification.Incident,
+		Transition: notification.Transition,
+	}
+	if site := notification.Site; site != nil {
+		summary := site.Summary()
+		payload.Site = &summary
+	}
+	if result := notification.Result; result != nil {
+		payload.Result = &Result{
+			Id:              result.Id,
+			Time:            result.Time,
+			Latency:         result.Latency,
+			Code:            result.Code,
+			Timings:         result.Timings,
+			Successful:      result.Successful,
+			FailureReason:   result.FailureReason,
+			AssertionFailed: result.AssertionFailed,
+			Certificate:     result.Certificate,
+			Answers:         result.Answers,
+		}
+	}
+	return payload
+}
+
+// Sign returns the value of SignatureHeader for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Register registers the webhook channel type.
+func Register() {
+	client := notifier.NewClient()
+	notifier.Register(model.ChannelTypeWebhook, func(channel model.Channel) (notifier.Notifier, error) {
+		config, err := parseConfig(channel)
+		if err != nil {
+			return nil, err
+		}
+		return &Notifier{client: client, config: config}, nil
+	})
+}
+
+// Notifier posts notifications to a webhook channel. It makes a single
+// attempt, failed deliveries are retried by the outbox.
+type Notifier struct {
+	client *http.Client
+	config Config
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification model.Notification) error {
+	body, err := json.Marshal(newPayload(notification, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(n.config.TimeoutSec)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(notification.Event))
+	req.Header.Set(SignatureHeader, Sign(n.config.Secret, body))
+	// receivers may drop payloads with a delivery they have already seen,
+	// since a retried delivery keeps its id
+	if deliveryId, ok := notifier.DeliveryId(ctx); ok {
+		req.Header.Set(DeliveryHeader, strconv.FormatInt(deliveryId, 10))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+type AlertRulesProvider interface {
+	AddRule(ctx context.Context, rule model.AlertRule) (int64, error)
+	UpdateRule(ctx context.Context, rule model.AlertRule) error
+	DeleteRuleById(ctx context.Context, ruleId int64) error
+
+	GetRuleById(ctx context.Context, ruleId int64) (model.AlertRule, error)
+	GetAllRules(ctx context.Context) ([]model.AlertRule, error)
+	// GetRulesForSite returns rules of the site and of its tags.
+	GetRulesForSite(ctx context.Context, site model.Site) ([]model.AlertRule, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+type CertificatesProvider interface {
+	SaveCertificate(ctx context.Context, cert model.Certificate) error
+	GetCertificateBySiteId(ctx context.Context, siteId int64) (model.Certificate, error)
+	// SetWarnedDays records the expiry warning for days left unless a warning
+	// for as few days was recorded already, so that every warning is sent
+	// once. The warning is added to pending notifications in the same
+	// transaction. It reports whether the warning was recorded.
+	SetWarnedDays(
+		ctx context.Context,
+		siteId int64,
+		days int,
+		notification model.Notification,
+	) (bool, error)
+}
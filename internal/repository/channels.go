@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+type ChannelsProvider interface {
+	AddChannel(ctx context.Context, channel model.Channel) (int64, error)
+	UpdateChannel(ctx context.Context, channel model.Channel) error
+	DeleteChannelById(ctx context.Context, channelId int64) error
+
+	GetChannelById(ctx context.Context, channelId int64) (model.Channel, error)
+	GetAllChannels(ctx context.Context) ([]model.Channel, error)
+	// GetAllSubscribedOnSiteChannels returns channels subscribed to the site
+	// with the url.
+	GetAllSubscribedOnSiteChannels(ctx context.Context, url string) ([]model.Channel, error)
+
+	SubscribeChannel(ctx context.Context, channelId int64, siteId int64) error
+	UnsubscribeChannel(ctx context.Context, channelId int64, siteId int64) error
+}
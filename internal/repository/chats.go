@@ -9,4 +9,6 @@ type ChatsProvider interface {
 	AddChat(ctx context.Context, chat model.Chat) error
 	UpdateChat(ctx context.Context, chat model.Chat) error
 	GetAllSubscribedOnSiteChats(ctx context.Context, url string) ([]model.Chat, error)
+	// HasSite reports whether the site was added from the chat.
+	HasSite(ctx context.Context, chatId int64, siteId int64) (bool, error)
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+type EmailsProvider interface {
+	AddEmailToSite(ctx context.Context, subscription model.EmailToSite) error
+	DeleteEmailFromSite(ctx context.Context, subscription model.EmailToSite) error
+
+	GetEmailsBySiteId(ctx context.Context, siteId int64) ([]model.EmailToSite, error)
+	// GetAllSubscribedOnSiteEmails returns addresses subscribed to the site
+	// with the url.
+	GetAllSubscribedOnSiteEmails(ctx context.Context, url string) ([]string, error)
+}
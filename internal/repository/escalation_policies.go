@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+type EscalationPoliciesProvider interface {
+	AddPolicy(ctx context.Context, policy model.EscalationPolicy) (int64, error)
+	UpdatePolicy(ctx context.Context, policy model.EscalationPolicy) error
+	DeletePolicyById(ctx context.Context, policyId int64) error
+
+	GetPolicyById(ctx context.Context, policyId int64) (model.EscalationPolicy, error)
+	GetAllPolicies(ctx context.Context) ([]model.EscalationPolicy, error)
+	// GetPoliciesForSite returns policies of the site and of its tags.
+	GetPoliciesForSite(ctx context.Context, site model.Site) ([]model.EscalationPolicy, error)
+}
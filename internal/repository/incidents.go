@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+	"time"
+)
+
+type IncidentsProvider interface {
+	// OpenIncident adds the incident and sets the state of its site according
+	// to the kind of the incident. The open incident of the site, such as a
+	// degraded incident replaced by a down one, ends when the new incident
+	// starts. The notification, if not nil, gets id of the incident and is
+	// added to pending notifications in the same transaction.
+	OpenIncident(
+		ctx context.Context,
+		incident model.Incident,
+		notification *model.Notification,
+	) (int64, error)
+	AddFailureToIncident(ctx context.Context, incidentId int64, resultId int64) error
+	// ResolveIncident ends the incident and marks its site as up. The
+	// notification, if not nil, is added to pending notifications in the same
+	// transaction.
+	ResolveIncident(
+		ctx context.Context,
+		incident model.Incident,
+		endedAt time.Time,
+		notification *model.Notification,
+	) error
+
+	// AcknowledgeIncident records who took the open incident. It reports
+	// false if the incident is already acknowledged or resolved.
+	AcknowledgeIncident(
+		ctx context.Context,
+		incidentId int64,
+		by string,
+		at time.Time,
+	) (bool, error)
+	// AdvanceEscalationStep completes the step of the incident if it is the
+	// next one, so that every step runs once. The notification of the step,
+	// if not nil, is added to pending notifications in the same transaction.
+	// It reports whether the step was completed.
+	AdvanceEscalationStep(
+		ctx context.Context,
+		incidentId int64,
+		step int,
+		notification *model.Notification,
+	) (bool, error)
+
+	// SetSiteState sets the state of the site. The notification, if not nil,
+	// is added to pending notifications in the same transaction.
+	SetSiteState(
+		ctx context.Context,
+		siteId int64,
+		state model.SiteState,
+		notification *model.Notification,
+	) error
+
+	GetIncidentById(ctx context.Context, incidentId int64) (model.Incident, error)
+	GetOpenIncidentBySiteId(ctx context.Context, siteId int64) (model.Incident, error)
+	GetAllIncidents(ctx context.Context) ([]model.Incident, error)
+	// GetEscalatingIncidents returns open down incidents which are not
+	// acknowledged, except incidents of paused sites.
+	GetEscalatingIncidents(ctx context.Context) ([]model.Incident, error)
+	GetAllIncidentsBySiteId(ctx context.Context, siteId int64) ([]model.Incident, error)
+	// GetIncidentsBySiteIdInPeriod returns incidents of the site which overlap
+	// the period.
+	GetIncidentsBySiteIdInPeriod(
+		ctx context.Context,
+		siteId int64,
+		from time.Time,
+		to time.Time,
+	) ([]model.Incident, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type LeasesProvider interface {
+	// AcquireLease takes the lease for ttl. Expiry of leases is measured
+	// with the clock of the database, so that clocks of replicas may differ.
+	AcquireLease(
+		ctx context.Context,
+		name string,
+		holder string,
+		ttl time.Duration,
+	) (bool, error)
+	// ReleaseLease expires the lease of holder at once. The state of the
+	// lease is kept for the next holder.
+	ReleaseLease(ctx context.Context, name string, holder string) error
+	// GetLeaseState returns the state kept with the lease, or sql.ErrNoRows
+	// if the lease was never taken.
+	GetLeaseState(ctx context.Context, name string) (string, error)
+	// SetLeaseState keeps state with the lease if holder owns it, and
+	// reports whether it does.
+	SetLeaseState(ctx context.Context, name string, holder string, state string) (bool, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+type MaintenanceProvider interface {
+	AddWindow(ctx context.Context, window model.MaintenanceWindow) (int64, error)
+	UpdateWindow(ctx context.Context, window model.MaintenanceWindow) error
+	DeleteWindowById(ctx context.Context, windowId int64) error
+	// SetWindowActive stores whether the start of the window was announced
+	// and its end is still to be announced.
+	SetWindowActive(ctx context.Context, windowId int64, active bool) error
+
+	GetWindowById(ctx context.Context, windowId int64) (model.MaintenanceWindow, error)
+	GetAllWindows(ctx context.Context) ([]model.MaintenanceWindow, error)
+}
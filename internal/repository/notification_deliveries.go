@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+	"time"
+)
+
+type NotificationDeliveriesProvider interface {
+	// AddDeliveries adds all deliveries of a notification at once.
+	AddDeliveries(ctx context.Context, deliveries []model.NotificationDelivery) error
+	// UpdateDelivery saves the channel, status, attempts and outcome of the
+	// last attempt of the delivery.
+	UpdateDelivery(ctx context.Context, delivery model.NotificationDelivery) error
+	// ClaimDelivery postpones the next attempt of the pending delivery to
+	// until if it is due at now, so that only one worker attempts it. It
+	// reports whether the delivery was claimed.
+	ClaimDelivery(
+		ctx context.Context,
+		deliveryId int64,
+		now time.Time,
+		until time.Time,
+	) (bool, error)
+
+	GetDeliveryById(ctx context.Context, deliveryId int64) (model.NotificationDelivery, error)
+	// GetDueDeliveries returns at most limit pending deliveries which are
+	// due at now and are the oldest pending deliveries of their recipients.
+	GetDueDeliveries(
+		ctx context.Context,
+		now time.Time,
+		limit int,
+	) ([]model.NotificationDelivery, error)
+	// GetDeliveries returns at most limit last deliveries with the status,
+	// or with any status if it is empty.
+	GetDeliveries(
+		ctx context.Context,
+		status model.DeliveryStatus,
+		limit int,
+	) ([]model.NotificationDelivery, error)
+	// GetDeliveriesByChannelId returns at most limit last deliveries to the
+	// channel.
+	GetDeliveriesByChannelId(
+		ctx context.Context,
+		channelId int64,
+		limit int,
+	) ([]model.NotificationDelivery, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"shm/internal/model"
+)
+
+// PendingNotificationsProvider is the outbox of the alert service.
+// Notifications about state changes are added to it in the transaction of the
+// change and published to the broker after the transaction is committed.
+type PendingNotificationsProvider interface {
+	// GetPendingNotifications returns at most limit oldest notifications
+	// with their ids.
+	GetPendingNotifications(ctx context.Context, limit int) ([]model.Notification, error)
+	// DeletePendingNotification removes the notification once it is
+	// published.
+	DeletePendingNotification(ctx context.Context, notificationId int64) error
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+)
+
+type AlertRulesRepo struct {
+	db *sql.DB
+}
+
+func NewAlertRulesRepo(db *sql.DB) *AlertRulesRepo {
+	return &AlertRulesRepo{db}
+}
+
+const alertRuleColumns = "r.id, COALESCE(r.site_id, 0), r.tag, r.condition"
+
+func scanAlertRule(row scanner) (model.AlertRule, error) {
+	var rule model.AlertRule
+	err := row.Scan(&rule.Id, &rule.SiteId, &rule.Tag, &rule.Condition)
+	return rule, err
+}
+
+func scanAlertRules(rows *sql.Rows) ([]model.AlertRule, error) {
+	var rules []model.AlertRule
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// nullSiteId stores rules of tags with NULL site_id.
+func nullSiteId(siteId int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: siteId, Valid: siteId != 0}
+}
+
+func (r *AlertRulesRepo) AddRule(ctx context.Context, rule model.AlertRule) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(
+		ctx,
+		"INSERT INTO alert_rules (site_id, tag, condition) VALUES ($1, $2, $3) RETURNING id",
+		nullSiteId(rule.SiteId), rule.Tag, rule.Condition,
+	).Scan(&id)
+
+	return id, err
+}
+
+func (r *AlertRulesRepo) UpdateRule(ctx context.Context, rule model.AlertRule) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE alert_rules SET site_id = $1, tag = $2, condition = $3 WHERE id = $4",
+		nullSiteId(rule.SiteId), rule.Tag, rule.Condition, rule.Id,
+	)
+	return err
+}
+
+func (r *AlertRulesRepo) DeleteRuleById(ctx context.Context, ruleId int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM alert_rules WHERE id = $1", ruleId)
+	return err
+}
+
+func (r *AlertRulesRepo) GetRuleById(ctx context.Context, ruleId int64) (model.AlertRule, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT "+alertRuleColumns+" FROM alert_rules AS r WHERE r.id = $1",
+		ruleId,
+	)
+	return scanAlertRule(row)
+}
+
+func (r *AlertRulesRepo) GetAllRules(ctx context.Context) ([]model.AlertRule, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+alertRuleColumns+" FROM alert_rules AS r ORDER BY r.id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlertRules(rows)
+}
+
+func (r *AlertRulesRepo) GetRulesForSite(
+	ctx context.Context,
+	site model.Site,
+) ([]model.AlertRule, error) {
+	args := []any{site.Id}
+	query := "SELECT " + alertRuleColumns + " FROM alert_rules AS r WHERE r.site_id = $1"
+	if len(site.Tags) > 0 {
+		query += " OR r.tag IN (" + placeholders(2, len(site.Tags)) + ")"
+		for _, tag := range site.Tags {
+			args = append(args, tag)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY r.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlertRules(rows)
+}
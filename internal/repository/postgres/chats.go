@@ -72,3 +72,13 @@ func (s *ChatsRepo) GetAllSubscribedOnSiteChats(
 
 	return chats, nil
 }
+
+func (s *ChatsRepo) HasSite(ctx context.Context, chatId int64, siteId int64) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM chat_to_site WHERE chat_id = $1 AND site_id = $2)",
+		chatId, siteId,
+	).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,361 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+	"time"
+)
+
+type IncidentsRepo struct {
+	db *sql.DB
+}
+
+func NewIncidentsRepo(db *sql.DB) *IncidentsRepo {
+	return &IncidentsRepo{db}
+}
+
+const incidentColumns = "i.id, i.site_id, i.kind, i.started_at, i.ended_at, i.cause, " +
+	"i.first_failed_result_id, i.last_failed_result_id, i.acked_at, i.acked_by, i.escalation_step"
+
+func incidentFields(incident *model.Incident) []any {
+	return []any{
+		&incident.Id,
+		&incident.SiteId,
+		&incident.Kind,
+		&incident.StartedAt,
+		&incident.EndedAt,
+		&incident.Cause,
+		&incident.FirstFailedResultId,
+		&incident.LastFailedResultId,
+		&incident.AckedAt,
+		&incident.AckedBy,
+		&incident.EscalationStep,
+	}
+}
+
+func scanIncident(row scanner) (model.Incident, error) {
+	var incident model.Incident
+	err := row.Scan(incidentFields(&incident)...)
+	return incident, err
+}
+
+func scanIncidents(rows *sql.Rows) ([]model.Incident, error) {
+	var incidents []model.Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+func (r *IncidentsRepo) OpenIncident(
+	ctx context.Context,
+	incident model.Incident,
+	notification *model.Notification,
+) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE incidents SET ended_at = $1 WHERE site_id = $2 AND ended_at IS NULL",
+		incident.StartedAt, incident.SiteId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO incidents (
+			site_id, kind, started_at, cause, first_failed_result_id, last_failed_result_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		incident.SiteId, incident.Kind, incident.StartedAt, incident.Cause,
+		incident.FirstFailedResultId, incident.LastFailedResultId,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE sites SET state = $1 WHERE id = $2",
+		incident.State(), incident.SiteId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if notification != nil {
+		notification.IncidentId = id
+		if notification.Incident != nil {
+			notification.Incident.Id = id
+		}
+		if err := addPendingNotification(ctx, tx, *notification); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *IncidentsRepo) AddFailureToIncident(
+	ctx context.Context,
+	incidentId int64,
+	resultId int64,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE incidents SET last_failed_result_id = $1 WHERE id = $2",
+		resultId, incidentId,
+	)
+	return err
+}
+
+func (r *IncidentsRepo) ResolveIncident(
+	ctx context.Context,
+	incident model.Incident,
+	endedAt time.Time,
+	notification *model.Notification,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE incidents SET ended_at = $1 WHERE id = $2",
+		endedAt, incident.Id,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE sites SET state = $1 WHERE id = $2",
+		model.SiteStateUp, incident.SiteId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if notification != nil {
+		if err := addPendingNotification(ctx, tx, *notification); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *IncidentsRepo) AcknowledgeIncident(
+	ctx context.Context,
+	incidentId int64,
+	by string,
+	at time.Time,
+) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE incidents SET acked_at = $1, acked_by = $2
+		WHERE id = $3 AND acked_at IS NULL AND ended_at IS NULL`,
+		at, by, incidentId,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *IncidentsRepo) AdvanceEscalationStep(
+	ctx context.Context,
+	incidentId int64,
+	step int,
+	notification *model.Notification,
+) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		"UPDATE incidents SET escalation_step = $1 WHERE id = $2 AND escalation_step = $3",
+		step+1, incidentId, step,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	if notification != nil {
+		if err := addPendingNotification(ctx, tx, *notification); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *IncidentsRepo) SetSiteState(
+	ctx context.Context,
+	siteId int64,
+	state model.SiteState,
+	notification *model.Notification,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE sites SET state = $1 WHERE id = $2",
+		state, siteId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if notification != nil {
+		if err := addPendingNotification(ctx, tx, *notification); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *IncidentsRepo) GetIncidentById(
+	ctx context.Context,
+	incidentId int64,
+) (model.Incident, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT "+incidentColumns+" FROM incidents AS i WHERE i.id = $1",
+		incidentId,
+	)
+	return scanIncident(row)
+}
+
+func (r *IncidentsRepo) GetOpenIncidentBySiteId(
+	ctx context.Context,
+	siteId int64,
+) (model.Incident, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT `+incidentColumns+`
+		FROM incidents AS i
+		WHERE i.site_id = $1 AND i.ended_at IS NULL
+		ORDER BY i.started_at DESC
+		LIMIT 1`,
+		siteId,
+	)
+	return scanIncident(row)
+}
+
+func (r *IncidentsRepo) GetAllIncidents(ctx context.Context) ([]model.Incident, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+incidentColumns+" FROM incidents AS i ORDER BY i.started_at DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+func (r *IncidentsRepo) GetEscalatingIncidents(ctx context.Context) ([]model.Incident, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+incidentColumns+`
+		FROM incidents AS i
+		JOIN sites AS s ON s.id = i.site_id
+		WHERE i.ended_at IS NULL AND i.acked_at IS NULL AND i.kind = $1
+			AND s.state != $2
+		ORDER BY i.started_at`,
+		model.IncidentKindDown,
+		model.SiteStatePaused,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+func (r *IncidentsRepo) GetAllIncidentsBySiteId(
+	ctx context.Context,
+	siteId int64,
+) ([]model.Incident, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+incidentColumns+`
+		FROM incidents AS i
+		WHERE i.site_id = $1
+		ORDER BY i.started_at DESC`,
+		siteId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+func (r *IncidentsRepo) GetIncidentsBySiteIdInPeriod(
+	ctx context.Context,
+	siteId int64,
+	from time.Time,
+	to time.Time,
+) ([]model.Incident, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+incidentColumns+`
+		FROM incidents AS i
+		WHERE i.site_id = $1
+		AND i.started_at < $2
+		AND (i.ended_at IS NULL OR i.ended_at > $3)
+		ORDER BY i.started_at DESC`,
+		siteId, to, from,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
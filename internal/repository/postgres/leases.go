@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type LeasesRepo struct {
+	db *sql.DB
+}
+
+func NewLeasesRepo(db *sql.DB) *LeasesRepo {
+	return &LeasesRepo{db}
+}
+
+// AcquireLease takes the lease if it is free or expired, or extends it if it
+// is already held by holder. It reports whether holder owns the lease.
+func (r *LeasesRepo) AcquireLease(
+	ctx context.Context,
+	name string,
+	holder string,
+	ttl time.Duration,
+) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO leases (name, holder, expires_at)
+		VALUES ($1, $2, (now() AT TIME ZONE 'UTC') + $3 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+			holder = excluded.holder,
+			expires_at = excluded.expires_at
+		WHERE leases.holder = excluded.holder OR leases.expires_at < (now() AT TIME ZONE 'UTC')`,
+		name, holder, ttl.Milliseconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *LeasesRepo) ReleaseLease(ctx context.Context, name string, holder string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE leases SET expires_at = (now() AT TIME ZONE 'UTC') - interval '1 second'
+		WHERE name = $1 AND holder = $2`,
+		name, holder,
+	)
+	return err
+}
+
+func (r *LeasesRepo) GetLeaseState(ctx context.Context, name string) (string, error) {
+	var state string
+	err := r.db.QueryRowContext(ctx, "SELECT state FROM leases WHERE name = $1", name).Scan(&state)
+	return state, err
+}
+
+func (r *LeasesRepo) SetLeaseState(
+	ctx context.Context,
+	name string,
+	holder string,
+	state string,
+) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE leases SET state = $1
+		WHERE name = $2 AND holder = $3 AND expires_at > (now() AT TIME ZONE 'UTC')`,
+		state, name, holder,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// placeholders returns n comma separated placeholders starting from $start.
+func placeholders(start, n int) string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(list, ", ")
+}
+
+// assignments returns "column = $i" pairs for UPDATE starting from $start.
+func assignments(start int, columns []string) string {
+	list := make([]string, len(columns))
+	for i, column := range columns {
+		list[i] = fmt.Sprintf("%s = $%d", column, start+i)
+	}
+	return strings.Join(list, ", ")
+}
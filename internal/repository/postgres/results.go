@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"shm/internal/model"
+	"time"
 )
 
 type ResultsRepo struct {
@@ -14,14 +15,65 @@ func NewResultsRepo(db *sql.DB) *ResultsRepo {
 	return &ResultsRepo{db}
 }
 
-func (r *ResultsRepo) AddResult(ctx context.Context, result model.CheckResult) error {
-	_, err := r.db.ExecContext(
-		ctx,
-		"INSERT INTO check_results (site_id, time, latency, code) VALUES ($1, $2, $3, $4)",
-		result.Site.Id, result.Time, result.Latency, result.Code,
+const resultColumns = siteColumns + ", c.id, c.time, c.latency, c.code, c.successful, c.failure_reason, " +
+	"c.dns_ms, c.connect_ms, c.tls_ms, c.ttfb_ms, c.transfer_ms, c.answers, c.assertion_failed, c.scheduled_at"
+
+func scanResult(row scanner) (model.CheckResult, error) {
+	var result model.CheckResult
+	fields := append(
+		siteFields(&result.Site),
+		&result.Id,
+		&result.Time,
+		&result.Latency,
+		&result.Code,
+		&result.Successful,
+		&result.FailureReason,
+		&result.Timings.DNSLookup,
+		&result.Timings.Connect,
+		&result.Timings.TLSHandshake,
+		&result.Timings.FirstByte,
+		&result.Timings.Transfer,
+		&result.Answers,
+		&result.AssertionFailed,
+		&result.ScheduledAt,
 	)
+	err := row.Scan(fields...)
+	return result, err
+}
+
+func (r *ResultsRepo) AddResult(ctx context.Context, result model.CheckResult) (int64, error) {
+	// a run checked again replaces its result, so the run has a single
+	// result with the same id
+	var id int64
+	err := r.db.QueryRowContext(
+		ctx,
+		`INSERT INTO check_results (
+			site_id, time, latency, code, successful, failure_reason,
+			dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, answers, assertion_failed,
+			scheduled_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (site_id, scheduled_at) DO UPDATE SET
+			time = excluded.time,
+			latency = excluded.latency,
+			code = excluded.code,
+			successful = excluded.successful,
+			failure_reason = excluded.failure_reason,
+			dns_ms = excluded.dns_ms,
+			connect_ms = excluded.connect_ms,
+			tls_ms = excluded.tls_ms,
+			ttfb_ms = excluded.ttfb_ms,
+			transfer_ms = excluded.transfer_ms,
+			answers = excluded.answers,
+			assertion_failed = excluded.assertion_failed
+		RETURNING id`,
+		result.Site.Id, result.Time, result.Latency, result.Code, result.Successful,
+		result.FailureReason, result.Timings.DNSLookup, result.Timings.Connect,
+		result.Timings.TLSHandshake, result.Timings.FirstByte, result.Timings.Transfer,
+		result.Answers, result.AssertionFailed, result.ScheduledAt,
+	).Scan(&id)
 
-	return err
+	return id, err
 }
 
 func (r *ResultsRepo) GetNLastResultsForSite(
@@ -31,7 +83,7 @@ func (r *ResultsRepo) GetNLastResultsForSite(
 ) ([]model.CheckResult, error) {
 	rows, err := r.db.QueryContext(
 		ctx,
-		`SELECT s.id, s.url, c.time, c.latency, c.code
+		`SELECT `+resultColumns+`
 		FROM check_results AS c
 		JOIN sites AS s
 		ON c.site_id = s.id
@@ -45,17 +97,36 @@ func (r *ResultsRepo) GetNLastResultsForSite(
 	}
 	defer rows.Close()
 
+	return scanResults(rows)
+}
+
+func (r *ResultsRepo) GetResultsForSiteSince(
+	ctx context.Context,
+	site model.Site,
+	since time.Time,
+) ([]model.CheckResult, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+resultColumns+`
+		FROM check_results AS c
+		JOIN sites AS s
+		ON c.site_id = s.id
+		WHERE s.id = $1 AND c.time >= $2
+		ORDER BY c.time DESC`,
+		site.Id, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanResults(rows)
+}
+
+func scanResults(rows *sql.Rows) ([]model.CheckResult, error) {
 	var results []model.CheckResult
 	for rows.Next() {
-		var result model.CheckResult
-
-		err = rows.Scan(
-			&result.Site.Id,
-			&result.Site.Url,
-			&result.Time,
-			&result.Latency,
-			&result.Code,
-		)
+		result, err := scanResult(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -63,29 +134,5 @@ func (r *ResultsRepo) GetNLastResultsForSite(
 		results = append(results, result)
 	}
 
-	return results, nil
-}
-
-func (r *ResultsRepo) GetSecondToLastSuccessfulResultForSite(
-	ctx context.Context,
-	site model.Site,
-) (model.CheckResult, error) {
-	var result model.CheckResult
-	err := r.db.QueryRowContext(ctx,
-		`SELECT s.id, s.url, c.time, c.latency, c.code
-		FROM check_results as c
-		JOIN sites as s
-		ON c.site_id = s.id
-		WHERE s.id = $1 AND c.code = 200
-		ORDER BY c.time DESC
-		LIMIT 1 OFFSET 1`,
-		site.Id,
-	).Scan(
-		&result.Site.Id,
-		&result.Site.Url,
-		&result.Time,
-		&result.Latency,
-		&result.Code,
-	)
-	return result, err
+	return results, rows.Err()
 }
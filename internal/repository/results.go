@@ -3,13 +3,15 @@ package repository
 import (
 	"context"
 	"shm/internal/model"
+	"time"
 )
 
 type ResultsProvider interface {
-	AddResult(ctx context.Context, result model.CheckResult) error
+	AddResult(ctx context.Context, result model.CheckResult) (int64, error)
 	GetNLastResultsForSite(ctx context.Context, site model.Site, n int) ([]model.CheckResult, error)
-	GetSecondToLastSuccessfulResultForSite(
+	GetResultsForSiteSince(
 		ctx context.Context,
 		site model.Site,
-	) (model.CheckResult, error)
+		since time.Time,
+	) ([]model.CheckResult, error)
 }
@@ -3,17 +3,42 @@ package repository
 import (
 	"context"
 	"shm/internal/model"
+	"time"
 )
 
 type SitesProvider interface {
-	AddSite(ctx context.Context, url string) error
-	AddSiteFromChat(ctx context.Context, chatId int64, url string) error
+	AddSite(ctx context.Context, site model.Site) error
+	AddSiteFromChat(ctx context.Context, chatId int64, site model.Site) error
+
+	UpdateSite(ctx context.Context, site model.Site) error
+	UpdateLastPing(ctx context.Context, siteId int64, time time.Time) error
+	// UpdateNextRunAt stores the next run of the site and its base without
+	// jitter, from which the run after it is counted.
+	UpdateNextRunAt(ctx context.Context, siteId int64, baseRunAt time.Time, nextRunAt time.Time) error
+	// UpdateDNSAnswers replaces previous answers of the DNS check of the site
+	// with answers unless they changed meanwhile, so that every change is
+	// notified once. The notification, if not nil, is added to pending
+	// notifications in the same transaction. It reports whether the answers
+	// were replaced.
+	UpdateDNSAnswers(
+		ctx context.Context,
+		siteId int64,
+		previous model.Strings,
+		answers model.Strings,
+		notification *model.Notification,
+	) (bool, error)
 
 	DeleteSiteById(ctx context.Context, siteId int64) error
 	DeleteSiteFromChat(ctx context.Context, chatId int64, url string) error
 
 	GetSiteById(ctx context.Context, siteId int64) (model.Site, error)
+	GetSiteByUrl(ctx context.Context, url string) (model.Site, error)
+	// GetDNSAnswers returns the last answers of the DNS check of the site
+	// recorded by UpdateDNSAnswers.
+	GetDNSAnswers(ctx context.Context, siteId int64) (model.Strings, error)
 	GetAllSites(ctx context.Context) ([]model.Site, error)
+	// GetAllMonitoredSites returns sites with subscribers ordered by id.
 	GetAllMonitoredSites(ctx context.Context) ([]model.Site, error)
+	GetDueMonitoredSites(ctx context.Context, now time.Time) ([]model.Site, error)
 	GetAllSitesByChatId(ctx context.Context, chatId int64) ([]model.Site, error)
 }
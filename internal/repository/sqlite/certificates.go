@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+)
+
+type CertificatesRepo struct {
+	db *sql.DB
+}
+
+func NewCertificatesRepo(db *sql.DB) *CertificatesRepo {
+	return &CertificatesRepo{db}
+}
+
+// SaveCertificate replaces stored certificate of the site. Sent expiry
+// warnings are kept only if the certificate was not renewed.
+func (r *CertificatesRepo) SaveCertificate(ctx context.Context, cert model.Certificate) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO certificates (
+			site_id, subject, issuer, sans, not_before, expires_at,
+			hostname_valid, chain_valid, chain_error, checked_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (site_id) DO UPDATE SET
+			subject = excluded.subject,
+			issuer = excluded.issuer,
+			sans = excluded.sans,
+			not_before = excluded.not_before,
+			expires_at = excluded.expires_at,
+			hostname_valid = excluded.hostname_valid,
+			chain_valid = excluded.chain_valid,
+			chain_error = excluded.chain_error,
+			checked_at = excluded.checked_at,
+			warned_days = CASE
+				WHEN certificates.expires_at = excluded.expires_at THEN certificates.warned_days
+				ELSE NULL
+			END`,
+		cert.SiteId, cert.Subject, cert.Issuer, cert.SANs, cert.NotBefore, cert.ExpiresAt,
+		cert.HostnameValid, cert.ChainValid, cert.ChainError, cert.CheckedAt,
+	)
+	return err
+}
+
+func (r *CertificatesRepo) GetCertificateBySiteId(
+	ctx context.Context,
+	siteId int64,
+) (model.Certificate, error) {
+	var cert model.Certificate
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT site_id, subject, issuer, sans, not_before, expires_at,
+			hostname_valid, chain_valid, chain_error, checked_at, warned_days
+		FROM certificates
+		WHERE site_id = ?`,
+		siteId,
+	).Scan(
+		&cert.SiteId,
+		&cert.Subject,
+		&cert.Issuer,
+		&cert.SANs,
+		&cert.NotBefore,
+		&cert.ExpiresAt,
+		&cert.HostnameValid,
+		&cert.ChainValid,
+		&cert.ChainError,
+		&cert.CheckedAt,
+		&cert.WarnedDays,
+	)
+	return cert, err
+}
+
+func (r *CertificatesRepo) SetWarnedDays(
+	ctx context.Context,
+	siteId int64,
+	days int,
+	notification model.Notification,
+) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE certificates SET warned_days = ?
+		WHERE site_id = ? AND (warned_days IS NULL OR warned_days > ?)`,
+		days, siteId, days,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	if err := addPendingNotification(ctx, tx, notification); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
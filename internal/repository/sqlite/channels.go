@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+)
+
+type ChannelsRepo struct {
+	db *sql.DB
+}
+
+func NewChannelsRepo(db *sql.DB) *ChannelsRepo {
+	return &ChannelsRepo{db}
+}
+
+const channelColumns = "ch.id, ch.name, ch.type, ch.config"
+
+func scanChannel(row scanner) (model.Channel, error) {
+	var channel model.Channel
+	err := row.Scan(&channel.Id, &channel.Name, &channel.Type, &channel.Config)
+	return channel, err
+}
+
+func scanChannels(rows *sql.Rows) ([]model.Channel, error) {
+	var channels []model.Channel
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, channel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+func (r *ChannelsRepo) AddChannel(ctx context.Context, channel model.Channel) (int64, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO channels (name, type, config) VALUES (?, ?, ?)",
+		channel.Name, channel.Type, channel.Config,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *ChannelsRepo) UpdateChannel(ctx context.Context, channel model.Channel) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE channels SET name = ?, type = ?, config = ? WHERE id = ?",
+		channel.Name, channel.Type, channel.Config, channel.Id,
+	)
+	return err
+}
+
+func (r *ChannelsRepo) DeleteChannelById(ctx context.Context, channelId int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		"DELETE FROM channel_to_site WHERE channel_id = ?",
+		channelId,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM channels WHERE id = ?", channelId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ChannelsRepo) GetChannelById(ctx context.Context, channelId int64) (model.Channel, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT "+channelColumns+" FROM channels AS ch WHERE ch.id = ?",
+		channelId,
+	)
+	return scanChannel(row)
+}
+
+func (r *ChannelsRepo) GetAllChannels(ctx context.Context) ([]model.Channel, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+channelColumns+" FROM channels AS ch ORDER BY ch.id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChannels(rows)
+}
+
+func (r *ChannelsRepo) GetAllSubscribedOnSiteChannels(
+	ctx context.Context,
+	url string,
+) ([]model.Channel, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+channelColumns+`
+		FROM channels AS ch
+		JOIN channel_to_site AS cs
+		ON ch.id = cs.channel_id
+		JOIN sites AS s
+		ON cs.site_id = s.id
+		WHERE s.url = ?
+		ORDER BY ch.id`,
+		url,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChannels(rows)
+}
+
+func (r *ChannelsRepo) SubscribeChannel(ctx context.Context, channelId int64, siteId int64) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO channel_to_site (channel_id, site_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		channelId, siteId,
+	)
+	return err
+}
+
+func (r *ChannelsRepo) UnsubscribeChannel(ctx context.Context, channelId int64, siteId int64) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM channel_to_site WHERE channel_id = ? AND site_id = ?",
+		channelId, siteId,
+	)
+	return err
+}
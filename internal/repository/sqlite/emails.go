@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+)
+
+type EmailsRepo struct {
+	db *sql.DB
+}
+
+func NewEmailsRepo(db *sql.DB) *EmailsRepo {
+	return &EmailsRepo{db}
+}
+
+func (r *EmailsRepo) AddEmailToSite(ctx context.Context, subscription model.EmailToSite) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO email_to_site (email, site_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		subscription.Email, subscription.SiteId,
+	)
+	return err
+}
+
+func (r *EmailsRepo) DeleteEmailFromSite(ctx context.Context, subscription model.EmailToSite) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM email_to_site WHERE email = ? AND site_id = ?",
+		subscription.Email, subscription.SiteId,
+	)
+	return err
+}
+
+func (r *EmailsRepo) GetEmailsBySiteId(
+	ctx context.Context,
+	siteId int64,
+) ([]model.EmailToSite, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT email, site_id FROM email_to_site WHERE site_id = ? ORDER BY email",
+		siteId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []model.EmailToSite
+	for rows.Next() {
+		var subscription model.EmailToSite
+		if err := rows.Scan(&subscription.Email, &subscription.SiteId); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *EmailsRepo) GetAllSubscribedOnSiteEmails(
+	ctx context.Context,
+	url string,
+) ([]string, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT e.email
+		FROM email_to_site AS e
+		JOIN sites AS s
+		ON e.site_id = s.id
+		WHERE s.url = ?
+		ORDER BY e.email`,
+		url,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+
+		emails = append(emails, email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+)
+
+type EscalationPoliciesRepo struct {
+	db *sql.DB
+}
+
+func NewEscalationPoliciesRepo(db *sql.DB) *EscalationPoliciesRepo {
+	return &EscalationPoliciesRepo{db}
+}
+
+const escalationPolicyColumns = "e.id, COALESCE(e.site_id, 0), e.tag, e.steps"
+
+func scanEscalationPolicy(row scanner) (model.EscalationPolicy, error) {
+	var policy model.EscalationPolicy
+	err := row.Scan(&policy.Id, &policy.SiteId, &policy.Tag, &policy.Steps)
+	return policy, err
+}
+
+func scanEscalationPolicies(rows *sql.Rows) ([]model.EscalationPolicy, error) {
+	var policies []model.EscalationPolicy
+	for rows.Next() {
+		policy, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (r *EscalationPoliciesRepo) AddPolicy(
+	ctx context.Context,
+	policy model.EscalationPolicy,
+) (int64, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO escalation_policies (site_id, tag, steps) VALUES (?, ?, ?)",
+		nullSiteId(policy.SiteId), policy.Tag, policy.Steps,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *EscalationPoliciesRepo) UpdatePolicy(
+	ctx context.Context,
+	policy model.EscalationPolicy,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE escalation_policies SET site_id = ?, tag = ?, steps = ? WHERE id = ?",
+		nullSiteId(policy.SiteId), policy.Tag, policy.Steps, policy.Id,
+	)
+	return err
+}
+
+func (r *EscalationPoliciesRepo) DeletePolicyById(ctx context.Context, policyId int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM escalation_policies WHERE id = ?", policyId)
+	return err
+}
+
+func (r *EscalationPoliciesRepo) GetPolicyById(
+	ctx context.Context,
+	policyId int64,
+) (model.EscalationPolicy, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT "+escalationPolicyColumns+" FROM escalation_policies AS e WHERE e.id = ?",
+		policyId,
+	)
+	return scanEscalationPolicy(row)
+}
+
+func (r *EscalationPoliciesRepo) GetAllPolicies(
+	ctx context.Context,
+) ([]model.EscalationPolicy, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+escalationPolicyColumns+" FROM escalation_policies AS e ORDER BY e.id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEscalationPolicies(rows)
+}
+
+func (r *EscalationPoliciesRepo) GetPoliciesForSite(
+	ctx context.Context,
+	site model.Site,
+) ([]model.EscalationPolicy, error) {
+	args := []any{site.Id}
+	query := "SELECT " + escalationPolicyColumns + " FROM escalation_policies AS e WHERE e.site_id = ?"
+	if len(site.Tags) > 0 {
+		query += " OR e.tag IN (" + placeholders(2, len(site.Tags)) + ")"
+		for _, tag := range site.Tags {
+			args = append(args, tag)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY e.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEscalationPolicies(rows)
+}
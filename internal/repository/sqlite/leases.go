@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type LeasesRepo struct {
+	db *sql.DB
+}
+
+func NewLeasesRepo(db *sql.DB) *LeasesRepo {
+	return &LeasesRepo{db}
+}
+
+// AcquireLease takes the lease if it is free or expired, or extends it if it
+// is already held by holder. It reports whether holder owns the lease.
+func (r *LeasesRepo) AcquireLease(
+	ctx context.Context,
+	name string,
+	holder string,
+	ttl time.Duration,
+) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO leases (name, holder, expires_at)
+		VALUES (?, ?, strftime('%Y-%m-%d %H:%M:%f', 'now', ?))
+		ON CONFLICT (name) DO UPDATE SET
+			holder = excluded.holder,
+			expires_at = excluded.expires_at
+		WHERE leases.holder = excluded.holder
+			OR leases.expires_at < strftime('%Y-%m-%d %H:%M:%f', 'now')`,
+		name, holder, fmt.Sprintf("+%.3f seconds", ttl.Seconds()),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *LeasesRepo) ReleaseLease(ctx context.Context, name string, holder string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE leases SET expires_at = strftime('%Y-%m-%d %H:%M:%f', 'now', '-1 seconds')
+		WHERE name = ? AND holder = ?`,
+		name, holder,
+	)
+	return err
+}
+
+func (r *LeasesRepo) GetLeaseState(ctx context.Context, name string) (string, error) {
+	var state string
+	err := r.db.QueryRowContext(ctx, "SELECT state FROM leases WHERE name = ?", name).Scan(&state)
+	return state, err
+}
+
+func (r *LeasesRepo) SetLeaseState(
+	ctx context.Context,
+	name string,
+	holder string,
+	state string,
+) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE leases SET state = ?
+		WHERE name = ? AND holder = ? AND expires_at > strftime('%Y-%m-%d %H:%M:%f', 'now')`,
+		state, name, holder,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+	"time"
+)
+
+type MaintenanceRepo struct {
+	db *sql.DB
+}
+
+func NewMaintenanceRepo(db *sql.DB) *MaintenanceRepo {
+	return &MaintenanceRepo{db}
+}
+
+const maintenanceColumns = "m.id, COALESCE(m.site_id, 0), m.tag, m.starts_at, m.ends_at, " +
+	"m.cron, m.duration_min, m.announce, m.active"
+
+func scanMaintenanceWindow(row scanner) (model.MaintenanceWindow, error) {
+	var (
+		window           model.MaintenanceWindow
+		startsAt, endsAt sql.NullTime
+	)
+	err := row.Scan(
+		&window.Id,
+		&window.SiteId,
+		&window.Tag,
+		&startsAt,
+		&endsAt,
+		&window.Cron,
+		&window.DurationMin,
+		&window.Announce,
+		&window.Active,
+	)
+	window.StartsAt = startsAt.Time
+	window.EndsAt = endsAt.Time
+	return window, err
+}
+
+func scanMaintenanceWindows(rows *sql.Rows) ([]model.MaintenanceWindow, error) {
+	var windows []model.MaintenanceWindow
+	for rows.Next() {
+		window, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, window)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+// maintenanceValues returns values of site_id, tag, starts_at, ends_at, cron,
+// duration_min and announce. Empty site and times are stored as NULL.
+func maintenanceValues(window model.MaintenanceWindow) []any {
+	return []any{
+		nullSiteId(window.SiteId),
+		window.Tag,
+		nullTime(window.StartsAt),
+		nullTime(window.EndsAt),
+		window.Cron,
+		window.DurationMin,
+		window.Announce,
+	}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func (r *MaintenanceRepo) AddWindow(
+	ctx context.Context,
+	window model.MaintenanceWindow,
+) (int64, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO maintenance_windows (
+			site_id, tag, starts_at, ends_at, cron, duration_min, announce
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		maintenanceValues(window)...,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *MaintenanceRepo) UpdateWindow(ctx context.Context, window model.MaintenanceWindow) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE maintenance_windows
+		SET site_id = ?, tag = ?, starts_at = ?, ends_at = ?,
+		cron = ?, duration_min = ?, announce = ?
+		WHERE id = ?`,
+		append(maintenanceValues(window), window.Id)...,
+	)
+	return err
+}
+
+func (r *MaintenanceRepo) DeleteWindowById(ctx context.Context, windowId int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM maintenance_windows WHERE id = ?", windowId)
+	return err
+}
+
+func (r *MaintenanceRepo) SetWindowActive(ctx context.Context, windowId int64, active bool) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE maintenance_windows SET active = ? WHERE id = ?",
+		active, windowId,
+	)
+	return err
+}
+
+func (r *MaintenanceRepo) GetWindowById(
+	ctx context.Context,
+	windowId int64,
+) (model.MaintenanceWindow, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT "+maintenanceColumns+" FROM maintenance_windows AS m WHERE m.id = ?",
+		windowId,
+	)
+	return scanMaintenanceWindow(row)
+}
+
+func (r *MaintenanceRepo) GetAllWindows(ctx context.Context) ([]model.MaintenanceWindow, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+maintenanceColumns+" FROM maintenance_windows AS m ORDER BY m.id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMaintenanceWindows(rows)
+}
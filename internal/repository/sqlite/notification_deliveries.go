@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+	"time"
+)
+
+type NotificationDeliveriesRepo struct {
+	db *sql.DB
+}
+
+func NewNotificationDeliveriesRepo(db *sql.DB) *NotificationDeliveriesRepo {
+	return &NotificationDeliveriesRepo{db}
+}
+
+const notificationDeliveryColumns = "d.id, d.notification, d.channel_id, d.channel_name, " +
+	"d.channel_type, d.channel_config, d.recipient, d.status, d.attempts, d.next_attempt_at, " +
+	"d.last_error, d.created_at, d.delivered_at"
+
+func scanNotificationDelivery(row scanner) (model.NotificationDelivery, error) {
+	var delivery model.NotificationDelivery
+	err := row.Scan(
+		&delivery.Id,
+		&delivery.Notification,
+		&delivery.Channel.Id,
+		&delivery.Channel.Name,
+		&delivery.Channel.Type,
+		&delivery.Channel.Config,
+		&delivery.Recipient,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	)
+	return delivery, err
+}
+
+func scanNotificationDeliveries(rows *sql.Rows) ([]model.NotificationDelivery, error) {
+	var deliveries []model.NotificationDelivery
+	for rows.Next() {
+		delivery, err := scanNotificationDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// notificationId returns the id of the notification published by the alert
+// service or NULL for notifications without id, which are not deduplicated.
+func notificationId(notification model.Notification) sql.NullInt64 {
+	return sql.NullInt64{Int64: notification.Id, Valid: notification.Id != 0}
+}
+
+func (r *NotificationDeliveriesRepo) AddDeliveries(
+	ctx context.Context,
+	deliveries []model.NotificationDelivery,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, delivery := range deliveries {
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO notification_deliveries (notification, notification_id, channel_id,
+			channel_name, channel_type, channel_config, recipient, status, attempts,
+			next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING`,
+			delivery.Notification, notificationId(delivery.Notification), delivery.Channel.Id,
+			delivery.Channel.Name, delivery.Channel.Type, delivery.Channel.Config,
+			delivery.Recipient, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+			delivery.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *NotificationDeliveriesRepo) UpdateDelivery(
+	ctx context.Context,
+	delivery model.NotificationDelivery,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE notification_deliveries
+		SET channel_name = ?, channel_type = ?, channel_config = ?, status = ?,
+			attempts = ?, next_attempt_at = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?`,
+		delivery.Channel.Name, delivery.Channel.Type, delivery.Channel.Config, delivery.Status,
+		delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.DeliveredAt,
+		delivery.Id,
+	)
+	return err
+}
+
+func (r *NotificationDeliveriesRepo) ClaimDelivery(
+	ctx context.Context,
+	deliveryId int64,
+	now time.Time,
+	until time.Time,
+) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE notification_deliveries
+		SET next_attempt_at = ?
+		WHERE id = ? AND status = ? AND next_attempt_at <= ?`,
+		until, deliveryId, model.DeliveryStatusPending, now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *NotificationDeliveriesRepo) GetDeliveryById(
+	ctx context.Context,
+	deliveryId int64,
+) (model.NotificationDelivery, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT "+notificationDeliveryColumns+" FROM notification_deliveries AS d WHERE d.id = ?",
+		deliveryId,
+	)
+	return scanNotificationDelivery(row)
+}
+
+func (r *NotificationDeliveriesRepo) GetDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]model.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+notificationDeliveryColumns+`
+		FROM notification_deliveries AS d
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM notification_deliveries AS p
+			WHERE p.status = ? AND p.recipient = d.recipient AND p.id < d.id
+		)
+		ORDER BY d.next_attempt_at
+		LIMIT ?`,
+		model.DeliveryStatusPending, now, model.DeliveryStatusPending, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotificationDeliveries(rows)
+}
+
+func (r *NotificationDeliveriesRepo) GetDeliveries(
+	ctx context.Context,
+	status model.DeliveryStatus,
+	limit int,
+) ([]model.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+notificationDeliveryColumns+`
+		FROM notification_deliveries AS d
+		WHERE ? = '' OR d.status = ?
+		ORDER BY d.id DESC
+		LIMIT ?`,
+		status, status, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotificationDeliveries(rows)
+}
+
+func (r *NotificationDeliveriesRepo) GetDeliveriesByChannelId(
+	ctx context.Context,
+	channelId int64,
+	limit int,
+) ([]model.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+notificationDeliveryColumns+`
+		FROM notification_deliveries AS d
+		WHERE d.channel_id = ?
+		ORDER BY d.id DESC
+		LIMIT ?`,
+		channelId, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotificationDeliveries(rows)
+}
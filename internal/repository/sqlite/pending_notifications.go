@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"shm/internal/model"
+	"time"
+)
+
+type PendingNotificationsRepo struct {
+	db *sql.DB
+}
+
+func NewPendingNotificationsRepo(db *sql.DB) *PendingNotificationsRepo {
+	return &PendingNotificationsRepo{db}
+}
+
+// addPendingNotification adds the notification to the outbox of the alert
+// service in the transaction of the state change it is about.
+func addPendingNotification(
+	ctx context.Context,
+	tx *sql.Tx,
+	notification model.Notification,
+) error {
+	_, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO pending_notifications (notification, created_at) VALUES (?, ?)",
+		notification, time.Now(),
+	)
+	return err
+}
+
+func (r *PendingNotificationsRepo) GetPendingNotifications(
+	ctx context.Context,
+	limit int,
+) ([]model.Notification, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT id, notification FROM pending_notifications ORDER BY id LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []model.Notification
+	for rows.Next() {
+		var id int64
+		var notification model.Notification
+		if err := rows.Scan(&id, &notification); err != nil {
+			return nil, err
+		}
+		notification.Id = id
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *PendingNotificationsRepo) DeletePendingNotification(
+	ctx context.Context,
+	notificationId int64,
+) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM pending_notifications WHERE id = ?",
+		notificationId,
+	)
+	return err
+}
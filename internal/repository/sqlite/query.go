@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"strings"
+)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// placeholders returns n comma separated placeholders. start is ignored and
+// kept for symmetry with the postgres repository.
+func placeholders(_, n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// assignments returns "column = ?" pairs for UPDATE.
+func assignments(_ int, columns []string) string {
+	list := make([]string, len(columns))
+	for i, column := range columns {
+		list[i] = column + " = ?"
+	}
+	return strings.Join(list, ", ")
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"shm/internal/model"
+	"strings"
+	"time"
 )
 
 type SitesRepo struct {
@@ -15,27 +18,136 @@ func NewSitesRepo(db *sql.DB) *SitesRepo {
 	return &SitesRepo{db}
 }
 
-func (s *SitesRepo) AddSite(ctx context.Context, url string) error {
-	_, err := s.db.ExecContext(
-		ctx,
-		"INSERT INTO sites (url) VALUES (?) ON CONFLICT DO NOTHING",
-		url,
+const siteColumns = "s.id, s.url, s.type, s.method, s.headers, s.body, s.accepted_codes, " +
+	"s.assertions, s.no_redirects, s.tcp_banner, s.tcp_send, s.tcp_expect, " +
+	"s.dns_record_type, s.dns_resolver, s.dns_expected, s.dns_match, " +
+	"s.heartbeat_period_sec, s.heartbeat_grace_sec, s.last_ping_at, s.interval_sec, s.next_run_at, " +
+	"s.base_run_at, s.state, s.latency_max_ms, s.latency_p95_ms, s.tags"
+
+// siteFields returns destinations for siteColumns.
+func siteFields(site *model.Site) []any {
+	return []any{
+		&site.Id,
+		&site.Url,
+		&site.Type,
+		&site.HTTP.Method,
+		&site.HTTP.Headers,
+		&site.HTTP.Body,
+		&site.HTTP.AcceptedCodes,
+		&site.HTTP.Assertions,
+		&site.HTTP.NoRedirects,
+		&site.TCP.Banner,
+		&site.TCP.Send,
+		&site.TCP.Expect,
+		&site.DNS.RecordType,
+		&site.DNS.Resolver,
+		&site.DNS.Expected,
+		&site.DNS.Match,
+		&site.Heartbeat.PeriodSec,
+		&site.Heartbeat.GraceSec,
+		&site.Heartbeat.LastPingAt,
+		&site.IntervalSec,
+		&site.NextRunAt,
+		&site.BaseRunAt,
+		&site.State,
+		&site.Latency.MaxMs,
+		&site.Latency.P95Ms,
+		&site.Tags,
+	}
+}
+
+// siteWriteColumns are columns of sites set on insert and update.
+var siteWriteColumns = []string{
+	"url", "type", "method", "headers", "body", "accepted_codes",
+	"assertions", "no_redirects", "tcp_banner", "tcp_send", "tcp_expect",
+	"dns_record_type", "dns_resolver", "dns_expected", "dns_match",
+	"heartbeat_period_sec", "heartbeat_grace_sec", "interval_sec",
+	"latency_max_ms", "latency_p95_ms", "tags",
+}
+
+// siteValues returns values for siteWriteColumns.
+func siteValues(site model.Site) []any {
+	return []any{
+		site.Url,
+		site.Type,
+		site.HTTP.Method,
+		site.HTTP.Headers,
+		site.HTTP.Body,
+		site.HTTP.AcceptedCodes,
+		site.HTTP.Assertions,
+		site.HTTP.NoRedirects,
+		site.TCP.Banner,
+		site.TCP.Send,
+		site.TCP.Expect,
+		site.DNS.RecordType,
+		site.DNS.Resolver,
+		site.DNS.Expected,
+		site.DNS.Match,
+		site.Heartbeat.PeriodSec,
+		site.Heartbeat.GraceSec,
+		site.IntervalSec,
+		site.Latency.MaxMs,
+		site.Latency.P95Ms,
+		site.Tags,
+	}
+}
+
+// siteInsertValues returns values for siteWriteColumns and last_ping_at, which
+// is set only on insert and updated by pings afterwards.
+func siteInsertValues(site model.Site) []any {
+	return append(siteValues(site), site.Heartbeat.LastPingAt)
+}
+
+var (
+	insertSiteQuery = fmt.Sprintf(
+		"INSERT INTO sites (%s, last_ping_at) VALUES (%s) ON CONFLICT DO NOTHING",
+		strings.Join(siteWriteColumns, ", "),
+		placeholders(1, len(siteWriteColumns)+1),
 	)
+	updateSiteQuery = fmt.Sprintf(
+		"UPDATE sites SET %s WHERE id = %s",
+		assignments(1, siteWriteColumns),
+		placeholders(len(siteWriteColumns)+1, 1),
+	)
+)
+
+func scanSite(row scanner) (model.Site, error) {
+	var site model.Site
+	err := row.Scan(siteFields(&site)...)
+	return site, err
+}
+
+func scanSites(rows *sql.Rows) ([]model.Site, error) {
+	var sites []model.Site
+	for rows.Next() {
+		site, err := scanSite(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sites = append(sites, site)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+func (s *SitesRepo) AddSite(ctx context.Context, site model.Site) error {
+	_, err := s.db.ExecContext(ctx, insertSiteQuery, siteInsertValues(site)...)
 	return err
 }
 
-func (s *SitesRepo) AddSiteFromChat(ctx context.Context, chatId int64, url string) error {
-	_, err := s.db.ExecContext(
-		ctx,
-		"INSERT INTO sites (url) VALUES (?) ON CONFLICT DO NOTHING",
-		url,
-	)
+func (s *SitesRepo) AddSiteFromChat(ctx context.Context, chatId int64, site model.Site) error {
+	_, err := s.db.ExecContext(ctx, insertSiteQuery, siteInsertValues(site)...)
 	if err != nil {
 		return err
 	}
 
 	var siteId int64
-	err = s.db.QueryRowContext(ctx, "SELECT id FROM sites WHERE url = ?", url).Scan(&siteId)
+	err = s.db.QueryRowContext(ctx, "SELECT id FROM sites WHERE url = ?", site.Url).Scan(&siteId)
 	if err != nil {
 		// TODO: may be error should be returned
 		if errors.Is(err, sql.ErrNoRows) {
@@ -52,6 +164,70 @@ func (s *SitesRepo) AddSiteFromChat(ctx context.Context, chatId int64, url strin
 	return err
 }
 
+func (s *SitesRepo) UpdateSite(ctx context.Context, site model.Site) error {
+	_, err := s.db.ExecContext(ctx, updateSiteQuery, append(siteValues(site), site.Id)...)
+	return err
+}
+
+func (s *SitesRepo) UpdateLastPing(ctx context.Context, siteId int64, time time.Time) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"UPDATE sites SET last_ping_at = ? WHERE id = ?",
+		time, siteId,
+	)
+	return err
+}
+
+func (s *SitesRepo) UpdateNextRunAt(
+	ctx context.Context,
+	siteId int64,
+	baseRunAt time.Time,
+	nextRunAt time.Time,
+) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"UPDATE sites SET next_run_at = ?, base_run_at = ? WHERE id = ?",
+		nextRunAt, baseRunAt, siteId,
+	)
+	return err
+}
+
+func (s *SitesRepo) UpdateDNSAnswers(
+	ctx context.Context,
+	siteId int64,
+	previous model.Strings,
+	answers model.Strings,
+	notification *model.Notification,
+) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		"UPDATE sites SET dns_answers = ? WHERE id = ? AND dns_answers = ?",
+		answers, siteId, previous,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	if notification != nil {
+		if err := addPendingNotification(ctx, tx, *notification); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
 func (s *SitesRepo) DeleteSiteById(ctx context.Context, siteId int64) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM sites WHERE id = ?", siteId)
 	return err
@@ -77,71 +253,89 @@ func (s *SitesRepo) DeleteSiteFromChat(ctx context.Context, chatId int64, url st
 }
 
 func (s *SitesRepo) GetSiteById(ctx context.Context, siteId int64) (model.Site, error) {
-	var site model.Site
-	row := s.db.QueryRowContext(ctx, "SELECT * FROM sites WHERE id = ?", siteId)
-	err := row.Scan(&site.Id, &site.Url)
-	return site, err
+	row := s.db.QueryRowContext(
+		ctx,
+		"SELECT "+siteColumns+" FROM sites AS s WHERE s.id = ?",
+		siteId,
+	)
+	return scanSite(row)
+}
+
+func (s *SitesRepo) GetSiteByUrl(ctx context.Context, url string) (model.Site, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		"SELECT "+siteColumns+" FROM sites AS s WHERE s.url = ?",
+		url,
+	)
+	return scanSite(row)
+}
+
+func (s *SitesRepo) GetDNSAnswers(ctx context.Context, siteId int64) (model.Strings, error) {
+	var answers model.Strings
+	err := s.db.QueryRowContext(
+		ctx,
+		"SELECT dns_answers FROM sites WHERE id = ?",
+		siteId,
+	).Scan(&answers)
+	return answers, err
 }
 
 func (s *SitesRepo) GetAllSites(ctx context.Context) ([]model.Site, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT * FROM sites")
+	rows, err := s.db.QueryContext(ctx, "SELECT "+siteColumns+" FROM sites AS s")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var sites []model.Site
-	for rows.Next() {
-		var site model.Site
-
-		err = rows.Scan(&site.Id, &site.Url)
-		if err != nil {
-			return nil, err
-		}
-
-		sites = append(sites, site)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return sites, nil
+	return scanSites(rows)
 }
 
 func (s *SitesRepo) GetAllMonitoredSites(ctx context.Context) ([]model.Site, error) {
 	rows, err := s.db.QueryContext(
 		ctx,
-		"SELECT DISTINCT s.id, s.url FROM sites AS s JOIN chat_to_site AS c ON s.id = c.site_id",
+		`SELECT `+siteColumns+`
+		FROM sites AS s
+		WHERE (
+			EXISTS (SELECT 1 FROM chat_to_site AS c WHERE c.site_id = s.id)
+			OR EXISTS (SELECT 1 FROM channel_to_site AS c WHERE c.site_id = s.id)
+			OR EXISTS (SELECT 1 FROM email_to_site AS c WHERE c.site_id = s.id)
+		)
+		ORDER BY s.id`,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var sites []model.Site
-	for rows.Next() {
-		var site model.Site
-
-		err = rows.Scan(&site.Id, &site.Url)
-		if err != nil {
-			return nil, err
-		}
-
-		sites = append(sites, site)
-	}
+	return scanSites(rows)
+}
 
-	if err = rows.Err(); err != nil {
+func (s *SitesRepo) GetDueMonitoredSites(ctx context.Context, now time.Time) ([]model.Site, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT `+siteColumns+`
+		FROM sites AS s
+		WHERE (
+			EXISTS (SELECT 1 FROM chat_to_site AS c WHERE c.site_id = s.id)
+			OR EXISTS (SELECT 1 FROM channel_to_site AS c WHERE c.site_id = s.id)
+			OR EXISTS (SELECT 1 FROM email_to_site AS c WHERE c.site_id = s.id)
+		)
+		AND s.state <> 'paused'
+		AND (s.next_run_at IS NULL OR s.next_run_at <= ?)`,
+		now,
+	)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return sites, nil
+	return scanSites(rows)
 }
 
 func (s *SitesRepo) GetAllSitesByChatId(ctx context.Context, chatId int64) ([]model.Site, error) {
 	rows, err := s.db.QueryContext(
 		ctx,
-		`SELECT s.id, s.url
+		`SELECT `+siteColumns+`
 		FROM chat_to_site as c
 		JOIN sites as s
 		ON c.site_id = s.id
@@ -153,21 +347,5 @@ func (s *SitesRepo) GetAllSitesByChatId(ctx context.Context, chatId int64) ([]mo
 	}
 	defer rows.Close()
 
-	var sites []model.Site
-	for rows.Next() {
-		var site model.Site
-
-		err = rows.Scan(&site.Id, &site.Url)
-		if err != nil {
-			return nil, err
-		}
-
-		sites = append(sites, site)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return sites, nil
+	return scanSites(rows)
 }
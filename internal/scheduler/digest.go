@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"shm/internal/broker"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"time"
+)
+
+// defaultDigestPeriod is the period of the first digests, since the time of
+// the previous ones is not known.
+const defaultDigestPeriod = 24 * time.Hour
+
+// digestState is the progress of digests. It is kept with the scheduler
+// lease, so that a restarted scheduler or the next leader neither loses nor
+// repeats digests.
+type digestState struct {
+	// DueAt is when the next digests are due, and the end of their period.
+	DueAt time.Time `json:"dueAt"`
+	// From is the start of the period of the next digests, zero until the
+	// first digests were sent.
+	From time.Time `json:"from"`
+	// SentSiteId is the id of the last site whose digest of the due period
+	// was confirmed. Sites are sent ordered by id, so sending continues
+	// after it.
+	SentSiteId int64 `json:"sentSiteId,omitempty"`
+}
+
+// sendDigestsIfDue publishes the uptime digest of every monitored site once
+// the digest schedule fires. Digests which were missed while no scheduler
+// was running are sent late, once. The progress is stored after every
+// confirmed digest, so digests which were not confirmed are sent again on
+// the next tick, starting from the site which failed.
+func (s *Scheduler) sendDigestsIfDue(ctx context.Context, now time.Time) error {
+	if s.digest == nil {
+		return nil
+	}
+	state, err := s.loadDigestState(ctx)
+	if err != nil {
+		return err
+	}
+	if state.DueAt.IsZero() {
+		state.DueAt = s.digest.Next(now)
+		_, err = s.saveDigestState(ctx, state)
+		return err
+	}
+	if now.Before(state.DueAt) {
+		return nil
+	}
+
+	from := state.From
+	if from.IsZero() {
+		from = state.DueAt.Add(-defaultDigestPeriod)
+	}
+	sites, err := s.sites.GetAllMonitoredSites(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get sites from database: %w", err)
+	}
+
+	sent := 0
+	for _, site := range sites {
+		if site.Id <= state.SentSiteId {
+			continue
+		}
+		report, err := s.incidents.GetUptimeReport(ctx, site.Id, from, state.DueAt)
+		if err != nil {
+			return fmt.Errorf("failed to get uptime report: %w", err)
+		}
+		err = s.broker.PublishNotification(ctx, digestNotification(site, report))
+		var publishErr *broker.PublishError
+		if errors.As(err, &publishErr) && ctx.Err() == nil {
+			slog.Warn("digest was not confirmed by broker", sl.Site(site), sl.Error(err))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send digest to broker: %w", err)
+		}
+
+		state.SentSiteId = site.Id
+		if held, err := s.saveDigestState(ctx, state); err != nil || !held {
+			return err
+		}
+		sent++
+	}
+	slog.Info("digests were sent", slog.Int("sites", sent))
+
+	_, err = s.saveDigestState(ctx, digestState{DueAt: s.digest.Next(now), From: state.DueAt})
+	return err
+}
+
+// loadDigestState returns the digest state kept with the scheduler lease. A
+// state which can not be parsed is started over.
+func (s *Scheduler) loadDigestState(ctx context.Context) (digestState, error) {
+	var state digestState
+	raw, err := s.elector.state(ctx)
+	if err != nil {
+		return state, fmt.Errorf("failed to get digest state from database: %w", err)
+	}
+	if raw == "" {
+		return state, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		slog.Error("invalid digest state, digests are scheduled again", sl.Error(err))
+		return digestState{}, nil
+	}
+	return state, nil
+}
+
+// saveDigestState keeps the digest state with the scheduler lease, and
+// reports whether the replica still holds the lease.
+func (s *Scheduler) saveDigestState(ctx context.Context, state digestState) (bool, error) {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal digest state: %w", err)
+	}
+	held, err := s.elector.setState(ctx, string(raw))
+	if err != nil {
+		return false, fmt.Errorf("failed to save digest state: %w", err)
+	}
+	if !held {
+		slog.Warn("lost scheduler leadership while sending digests")
+	}
+	return held, nil
+}
+
+func digestNotification(site model.Site, report model.UptimeReport) model.Notification {
+	return model.Notification{
+		Url: site.Url,
+		Message: fmt.Sprintf(
+			"Digest for the website %s since %s: uptime %.2f%%, %d incidents, down for %d minutes.",
+			site.Url,
+			report.From.UTC().Format(time.DateTime),
+			report.UptimePercent,
+			report.Incidents,
+			report.DownSec/60,
+		),
+		Event:  model.NotificationEventDigest,
+		Site:   &site,
+		Report: &report,
+	}
+}
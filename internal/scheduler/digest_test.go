@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+func TestSendDigestsIfDue(t *testing.T) {
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	ctx := context.Background()
+	cfg := config.SchedulerConfig{DigestCron: "0 9 * * *"}
+	b := &publisher{}
+	first := newScheduler(t, database, b, cfg)
+	second := newScheduler(t, database, b, cfg)
+
+	var urls []string
+	for _, url := range []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"} {
+		site := addSite(t, first, model.Site{Url: url})
+		_, err := database.DB().ExecContext(ctx, "INSERT INTO chat_to_site (chat_id, site_id) VALUES (1, ?)", site.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, url)
+	}
+	// a site without subscribers gets no digest
+	addSite(t, first, model.Site{Url: "https://unmonitored.example.com"})
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	firstDue := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	secondDue := time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		scheduler *Scheduler
+		now       time.Time
+		rejectUrl string
+		wantUrls  []string
+		// wantFrom is the start of the period of sent digests
+		wantFrom time.Time
+	}{
+		{name: "first tick schedules digests", scheduler: first, now: start},
+		{name: "not due", scheduler: first, now: firstDue.Add(-time.Second)},
+		{
+			name:      "second digest is not confirmed",
+			scheduler: first,
+			now:       firstDue,
+			rejectUrl: urls[1],
+			wantUrls:  urls[:1],
+			wantFrom:  firstDue.Add(-defaultDigestPeriod),
+		},
+		{
+			name:      "retry continues from failed site",
+			scheduler: first,
+			now:       firstDue.Add(time.Minute),
+			wantUrls:  urls[1:],
+			wantFrom:  firstDue.Add(-defaultDigestPeriod),
+		},
+		{name: "sent digests are not repeated", scheduler: first, now: firstDue.Add(2 * time.Minute)},
+		{name: "next leader keeps schedule", scheduler: second, now: secondDue.Add(-time.Second)},
+		{
+			name:      "next leader sends due digests",
+			scheduler: second,
+			now:       secondDue,
+			wantUrls:  urls,
+			wantFrom:  firstDue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the previous leader releases the lease to the next one
+			if !tt.scheduler.elector.isLeader() {
+				first.elector.release()
+				tt.scheduler.elector.tryAcquire(ctx)
+				if !tt.scheduler.elector.isLeader() {
+					t.Fatal("scheduler did not take the lease")
+				}
+			}
+
+			b.notifications = nil
+			b.rejectUrl = tt.rejectUrl
+			if err := tt.scheduler.sendDigestsIfDue(ctx, tt.now); err != nil {
+				t.Fatalf("sendDigestsIfDue() error = %v", err)
+			}
+
+			var gotUrls []string
+			for _, notification := range b.notifications {
+				gotUrls = append(gotUrls, notification.Url)
+				if notification.Event != model.NotificationEventDigest {
+					t.Errorf("event = %s, want digest", notification.Event)
+				}
+				if !notification.Report.From.Equal(tt.wantFrom) {
+					t.Errorf("digest of %s from %s, want %s", notification.Url, notification.Report.From, tt.wantFrom)
+				}
+			}
+			if !reflect.DeepEqual(gotUrls, tt.wantUrls) {
+				t.Errorf("digests of %v, want %v", gotUrls, tt.wantUrls)
+			}
+		})
+	}
+}
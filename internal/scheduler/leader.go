@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"shm/internal/lib/sl"
+	"shm/internal/service"
+	"sync"
+	"time"
+)
+
+const leaseName = "scheduler"
+
+// elector keeps the scheduler lease in the database. Only the replica holding
+// the lease publishes sites; standby replicas try to take the lease over every
+// ttl/3, so a dead leader is replaced in at most 4/3 of ttl.
+type elector struct {
+	leases *service.LeasesService
+	holder string
+	ttl    time.Duration
+
+	mu          sync.Mutex
+	leaderUntil time.Time
+}
+
+func newElector(leases *service.LeasesService, ttl time.Duration) (*elector, error) {
+	holder, err := newHolderId()
+	if err != nil {
+		return nil, err
+	}
+	return &elector{
+		leases: leases,
+		holder: holder,
+		ttl:    ttl,
+	}, nil
+}
+
+func newHolderId() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate holder id: %w", err)
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix)), nil
+}
+
+func (e *elector) run(ctx context.Context) error {
+	t := time.NewTicker(e.ttl / 3)
+	defer t.Stop()
+
+	for {
+		e.tryAcquire(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.release()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (e *elector) tryAcquire(ctx context.Context) {
+	// leadership is counted from the moment before the request, so the
+	// replica never considers itself leader longer than the lease lasts
+	start := time.Now()
+	acquired, err := e.leases.AcquireLease(ctx, leaseName, e.holder, e.ttl)
+	if err != nil {
+		slog.Error("failed to acquire scheduler lease", sl.Error(err))
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	wasLeader := start.Before(e.leaderUntil)
+	if acquired {
+		e.leaderUntil = start.Add(e.ttl)
+	}
+
+	if acquired && !wasLeader {
+		slog.Info("became scheduler leader", slog.String("holder", e.holder))
+	} else if !acquired && wasLeader {
+		slog.Warn("lost scheduler leadership", slog.String("holder", e.holder))
+	}
+}
+
+func (e *elector) release() {
+	e.mu.Lock()
+	e.leaderUntil = time.Time{}
+	e.mu.Unlock()
+
+	ctx := context.Background()
+	if err := e.leases.ReleaseLease(ctx, leaseName, e.holder); err != nil {
+		slog.Error("failed to release scheduler lease", sl.Error(err))
+	}
+}
+
+// state returns the state kept with the scheduler lease.
+func (e *elector) state(ctx context.Context) (string, error) {
+	return e.leases.GetLeaseState(ctx, leaseName)
+}
+
+// setState keeps state with the scheduler lease, and reports whether the
+// replica still holds the lease.
+func (e *elector) setState(ctx context.Context, state string) (bool, error) {
+	return e.leases.SetLeaseState(ctx, leaseName, e.holder, state)
+}
+
+func (e *elector) isLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().Before(e.leaderUntil)
+}
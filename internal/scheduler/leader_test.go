@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"shm/internal/config"
+	"shm/internal/db"
+	"testing"
+	"time"
+)
+
+func TestElector(t *testing.T) {
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	ttl := 300 * time.Millisecond
+	first := newScheduler(t, database, nil, config.SchedulerConfig{LeaseTtlSec: ttl}).elector
+	second := newScheduler(t, database, nil, config.SchedulerConfig{LeaseTtlSec: ttl}).elector
+	ctx := context.Background()
+
+	first.tryAcquire(ctx)
+	second.tryAcquire(ctx)
+	if !first.isLeader() || second.isLeader() {
+		t.Fatalf("leaders after acquire: first %v, second %v, want only first", first.isLeader(), second.isLeader())
+	}
+
+	// the leader renews the lease before it expires
+	for range 3 {
+		time.Sleep(ttl / 3)
+		first.tryAcquire(ctx)
+		second.tryAcquire(ctx)
+	}
+	if !first.isLeader() || second.isLeader() {
+		t.Fatalf("leaders after renewal: first %v, second %v, want only first", first.isLeader(), second.isLeader())
+	}
+
+	// the leader stops renewing the lease and it expires
+	time.Sleep(ttl + 50*time.Millisecond)
+	if first.isLeader() {
+		t.Error("first is leader after its lease expired")
+	}
+	second.tryAcquire(ctx)
+	first.tryAcquire(ctx)
+	if first.isLeader() || !second.isLeader() {
+		t.Fatalf("leaders after expiry: first %v, second %v, want only second", first.isLeader(), second.isLeader())
+	}
+
+	// the released lease is taken over at once
+	second.release()
+	if second.isLeader() {
+		t.Error("second is leader after releasing the lease")
+	}
+	first.tryAcquire(ctx)
+	if !first.isLeader() {
+		t.Error("first is not leader after the lease was released")
+	}
+}
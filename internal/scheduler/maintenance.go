@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"shm/internal/broker"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"time"
+)
+
+// announceMaintenance stores which windows are in progress and, for windows
+// with announcements, notifies subscribers of their sites when a window starts
+// or ends. The state of a window is stored only after the broker confirmed
+// all its notifications, so an announcement which was not confirmed is sent
+// again on the next tick.
+func (s *Scheduler) announceMaintenance(
+	ctx context.Context,
+	windows []model.MaintenanceWindow,
+	now time.Time,
+) error {
+	var sites []model.Site
+	for _, window := range windows {
+		active := window.ActiveAt(now)
+		if active == window.Active {
+			continue
+		}
+
+		if window.Announce && sites == nil {
+			var err error
+			if sites, err = s.sites.GetAllSites(ctx); err != nil {
+				return fmt.Errorf("failed to get sites from database: %w", err)
+			}
+		}
+		err := s.announceWindow(ctx, window, sites, active)
+		var publishErr *broker.PublishError
+		if errors.As(err, &publishErr) && ctx.Err() == nil {
+			slog.Warn(
+				"maintenance announcement was not confirmed by broker",
+				slog.Int64("window_id", window.Id),
+				sl.Error(err),
+			)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.maintenance.SetWindowActive(ctx, window.Id, active); err != nil {
+			return fmt.Errorf("failed to update state of maintenance window: %w", err)
+		}
+		slog.Info(
+			"maintenance window changed state",
+			slog.Int64("window_id", window.Id),
+			slog.Bool("active", active),
+		)
+	}
+	return nil
+}
+
+// announceWindow notifies subscribers of the sites of the window with
+// announcements that it started or ended.
+func (s *Scheduler) announceWindow(
+	ctx context.Context,
+	window model.MaintenanceWindow,
+	sites []model.Site,
+	active bool,
+) error {
+	if !window.Announce {
+		return nil
+	}
+	for _, site := range sites {
+		if !window.AppliesTo(site) {
+			continue
+		}
+		if err := s.broker.PublishNotification(ctx, maintenanceNotification(site, active)); err != nil {
+			return fmt.Errorf("failed to send maintenance notification to broker: %w", err)
+		}
+	}
+	return nil
+}
+
+func maintenanceNotification(site model.Site, started bool) model.Notification {
+	if started {
+		return model.Notification{
+			Url:     site.Url,
+			Message: fmt.Sprintf("Maintenance of the website %s has started, alerts are paused.", site.Url),
+			Event:   model.NotificationEventMaintenanceStarted,
+		}
+	}
+	return model.Notification{
+		Url:     site.Url,
+		Message: fmt.Sprintf("Maintenance of the website %s has ended, monitoring is resumed.", site.Url),
+		Event:   model.NotificationEventMaintenanceEnded,
+	}
+}
+
+// skipSite postpones the check of a site in maintenance by its interval.
+func (s *Scheduler) skipSite(ctx context.Context, site model.Site, now time.Time) error {
+	nextRunAt := now.Add(site.Interval(s.config.IntervalMin))
+	if err := s.sites.UpdateNextRunAt(ctx, site.Id, nextRunAt, nextRunAt); err != nil {
+		return fmt.Errorf("failed to update next run of site: %w", err)
+	}
+	slog.Info("site is in maintenance, check was skipped", sl.Site(site))
+	return nil
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"shm/internal/config"
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+func TestAnnounceMaintenance(t *testing.T) {
+	start := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+	b := &publisher{}
+	s := newScheduler(t, nil, b, config.SchedulerConfig{})
+	ctx := context.Background()
+	site := addSite(t, s, model.Site{Url: "https://example.com", Tags: model.Strings{"db"}})
+	addSite(t, s, model.Site{Url: "https://other.example.com"})
+
+	window := model.MaintenanceWindow{Tag: "db", Cron: "0 3 * * *", DurationMin: 30, Announce: true}
+	if err := window.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.maintenance.AddWindow(ctx, window); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		publishErr error
+		wantEvents []model.NotificationEvent
+		wantActive bool
+	}{
+		{name: "before window", now: start.Add(-time.Minute)},
+		{
+			name:       "unconfirmed start",
+			now:        start,
+			publishErr: errors.New("nack"),
+		},
+		{
+			name:       "start is announced again",
+			now:        start.Add(time.Second),
+			wantEvents: []model.NotificationEvent{model.NotificationEventMaintenanceStarted},
+			wantActive: true,
+		},
+		{name: "during window", now: start.Add(10 * time.Minute), wantActive: true},
+		{
+			name:       "end",
+			now:        start.Add(30 * time.Minute),
+			wantEvents: []model.NotificationEvent{model.NotificationEventMaintenanceEnded},
+		},
+		{name: "after window", now: start.Add(31 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b.err = tt.publishErr
+			b.notifications = nil
+			windows, err := s.maintenance.GetAllWindows(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.announceMaintenance(ctx, windows, tt.now); err != nil {
+				t.Fatalf("announceMaintenance() error = %v", err)
+			}
+
+			var events []model.NotificationEvent
+			for _, notification := range b.notifications {
+				if notification.Url != site.Url {
+					t.Errorf("notification about %s, which is not in maintenance", notification.Url)
+				}
+				events = append(events, notification.Event)
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", events, tt.wantEvents)
+			}
+
+			windows, err = s.maintenance.GetAllWindows(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if windows[0].Active != tt.wantActive {
+				t.Errorf("window is active: %v, want %v", windows[0].Active, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestSkipSite(t *testing.T) {
+	now := time.Date(2026, 3, 1, 3, 10, 0, 0, time.UTC)
+	b := &publisher{}
+	s := newScheduler(t, nil, b, config.SchedulerConfig{})
+	site := addSite(t, s, model.Site{Url: "https://example.com", IntervalSec: 300})
+
+	if err := s.skipSite(context.Background(), site, now); err != nil {
+		t.Fatalf("skipSite() error = %v", err)
+	}
+	if len(b.sites) != 0 {
+		t.Errorf("site in maintenance was published")
+	}
+	if got, want := nextRunAt(t, s, site), now.Add(5*time.Minute); !got.Equal(want) {
+		t.Errorf("next run at %s, want %s", got, want)
+	}
+}
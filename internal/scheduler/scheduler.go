@@ -5,32 +5,70 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"os"
 	"os/signal"
 	"shm/internal/broker"
 	"shm/internal/config"
+	"shm/internal/lib/cron"
 	"shm/internal/lib/sl"
+	"shm/internal/model"
 	"shm/internal/service"
 	"syscall"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type Scheduler struct {
-	broker broker.MessageBroker
-	sites  *service.SitesService
-	config config.SchedulerConfig
+	broker      broker.MessageBroker
+	sites       *service.SitesService
+	maintenance *service.MaintenanceService
+	incidents   *service.IncidentsService
+	elector     *elector
+	config      config.SchedulerConfig
+
+	// digest is nil if digests are disabled
+	digest *cron.Schedule
 }
 
 func New(
 	broker broker.MessageBroker,
 	sites *service.SitesService,
+	maintenance *service.MaintenanceService,
+	incidents *service.IncidentsService,
+	leases *service.LeasesService,
 	config config.SchedulerConfig,
-) *Scheduler {
-	return &Scheduler{
-		broker: broker,
-		sites:  sites,
-		config: config,
+) (*Scheduler, error) {
+	if config.IntervalMin <= 0 || config.TickSec <= 0 || config.LeaseTtlSec <= 0 {
+		return nil, fmt.Errorf("interval, tick and lease ttl of scheduler must be positive")
+	}
+	if config.JitterPercent < 0 || config.JitterPercent > 100 {
+		return nil, fmt.Errorf("jitter percent must be between 0 and 100")
+	}
+
+	var digest *cron.Schedule
+	if config.DigestCron != "" {
+		var err error
+		if digest, err = cron.Parse(config.DigestCron); err != nil {
+			return nil, fmt.Errorf("invalid digest schedule: %w", err)
+		}
 	}
+
+	elector, err := newElector(leases, config.LeaseTtlSec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scheduler{
+		broker:      broker,
+		sites:       sites,
+		maintenance: maintenance,
+		incidents:   incidents,
+		elector:     elector,
+		config:      config,
+		digest:      digest,
+	}, nil
 }
 
 func (s *Scheduler) Start() {
@@ -38,13 +76,25 @@ func (s *Scheduler) Start() {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	if err := s.routine(ctx); err != nil && !errors.Is(err, context.Canceled) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return s.elector.run(ctx)
+	})
+
+	g.Go(func() error {
+		return s.routine(ctx)
+	})
+
+	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
 		slog.Error("error from sheduler", sl.Error(err))
 	}
 }
 
 func (s *Scheduler) routine(ctx context.Context) error {
-	t := time.NewTicker(s.config.IntervalMin)
+	t := time.NewTicker(s.config.TickSec)
+	defer t.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -52,7 +102,23 @@ func (s *Scheduler) routine(ctx context.Context) error {
 		case <-t.C:
 		}
 
-		sites, err := s.sites.GetAllMonitoredSites(ctx)
+		if !s.elector.isLeader() {
+			continue
+		}
+
+		now := time.Now()
+		windows, err := s.maintenance.GetAllWindows(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance windows from database: %w", err)
+		}
+		if err = s.announceMaintenance(ctx, windows, now); err != nil {
+			return err
+		}
+		if err = s.sendDigestsIfDue(ctx, now); err != nil {
+			return err
+		}
+
+		sites, err := s.sites.GetDueMonitoredSites(ctx, now)
 		if err != nil {
 			return fmt.Errorf("failed to get sites from database: %w", err)
 		}
@@ -63,11 +129,77 @@ func (s *Scheduler) routine(ctx context.Context) error {
 				return ctx.Err()
 			default:
 			}
+			// publishing many sites may outlast the lease, and another
+			// replica publishes the rest once it takes the lease over
+			if !s.elector.isLeader() {
+				slog.Warn("lost scheduler leadership while publishing sites")
+				break
+			}
 
-			if err = s.broker.PublishSite(ctx, site); err != nil {
-				return fmt.Errorf("failed to send site to broker: %w", err)
+			if model.InMaintenance(windows, site, now) {
+				err = s.skipSite(ctx, site, now)
+			} else {
+				err = s.scheduleSite(ctx, site, now)
+			}
+			var publishErr *broker.PublishError
+			if errors.As(err, &publishErr) && ctx.Err() == nil {
+				// next runs of the rest of sites are not moved, so they
+				// are published on the next tick
+				slog.Warn("site was not confirmed by broker", sl.Site(site), sl.Error(err))
+				break
+			}
+			if err != nil {
+				return err
 			}
-			slog.Info("successfully sending site to broker", sl.Site(site))
 		}
 	}
 }
+
+// scheduleSite publishes the due site and stores time of its next check.
+// Sites which were never scheduled or missed a whole interval (e.g. while the
+// scheduler was stopped) are spread randomly across the interval instead of
+// being checked all at once.
+func (s *Scheduler) scheduleSite(ctx context.Context, site model.Site, now time.Time) error {
+	interval := site.Interval(s.config.IntervalMin)
+
+	if !site.NextRunAt.Valid || site.NextRunAt.Time.Add(interval).Before(now) {
+		nextRunAt := now.Add(rand.N(interval))
+		if err := s.sites.UpdateNextRunAt(ctx, site.Id, nextRunAt, nextRunAt); err != nil {
+			return fmt.Errorf("failed to update next run of site: %w", err)
+		}
+		slog.Info("site was spread across interval", sl.Site(site), slog.Time("next_run_at", nextRunAt))
+		return nil
+	}
+
+	if err := s.broker.PublishSite(ctx, site); err != nil {
+		return fmt.Errorf("failed to send site to broker: %w", err)
+	}
+	slog.Info("successfully sending site to broker", sl.Site(site))
+
+	// jitter is applied only to the stored run, the run after it is counted
+	// from the base, so that runs do not drift from the interval
+	base := site.NextRunAt.Time
+	if site.BaseRunAt.Valid {
+		base = site.BaseRunAt.Time
+	}
+	base = base.Add(interval)
+	nextRunAt := base.Add(s.jitter(interval))
+	if !nextRunAt.After(now) {
+		base = now.Add(interval)
+		nextRunAt = base
+	}
+	if err := s.sites.UpdateNextRunAt(ctx, site.Id, base, nextRunAt); err != nil {
+		return fmt.Errorf("failed to update next run of site: %w", err)
+	}
+	return nil
+}
+
+// jitter returns random offset within JitterPercent of interval in both
+// directions.
+func (s *Scheduler) jitter(interval time.Duration) time.Duration {
+	max := interval * time.Duration(s.config.JitterPercent) / 100
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(2*max+1) - max
+}
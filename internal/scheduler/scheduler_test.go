@@ -0,0 +1,269 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"shm/internal/broker"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"shm/internal/service"
+	"testing"
+	"time"
+)
+
+// publisher is the broker of tests. It records published messages, or fails
+// to publish them with err. Notifications about rejectUrl are not confirmed
+// either.
+type publisher struct {
+	broker.MessageBroker
+	sites         []model.Site
+	notifications []model.Notification
+	err           error
+	rejectUrl     string
+}
+
+func (p *publisher) PublishSite(ctx context.Context, site model.Site) error {
+	if p.err != nil {
+		return &broker.PublishError{Queue: "sites", Err: p.err}
+	}
+	p.sites = append(p.sites, site)
+	return nil
+}
+
+func (p *publisher) PublishNotification(ctx context.Context, notification model.Notification) error {
+	if p.err != nil {
+		return &broker.PublishError{Queue: "notifications", Err: p.err}
+	}
+	if p.rejectUrl != "" && notification.Url == p.rejectUrl {
+		return &broker.PublishError{Queue: "notifications", Err: broker.ErrNacked}
+	}
+	p.notifications = append(p.notifications, notification)
+	return nil
+}
+
+// newScheduler returns a scheduler with a new SQLite database, which may be
+// shared with other schedulers.
+func newScheduler(t *testing.T, database *db.SQLite, b broker.MessageBroker, cfg config.SchedulerConfig) *Scheduler {
+	t.Helper()
+	if database == nil {
+		var err error
+		database, err = db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { database.Close() })
+	}
+
+	cfg.CommonConfig = config.CommonConfig{DbQueryTimeoutSec: 5 * time.Second}
+	if cfg.IntervalMin == 0 {
+		cfg.IntervalMin = time.Minute
+	}
+	if cfg.TickSec == 0 {
+		cfg.TickSec = time.Second
+	}
+	if cfg.LeaseTtlSec == 0 {
+		cfg.LeaseTtlSec = 15 * time.Second
+	}
+	s, err := New(
+		b,
+		service.NewSitesService(database.SitesRepo(), cfg.CommonConfig),
+		service.NewMaintenanceService(database.MaintenanceRepo(), cfg.CommonConfig),
+		service.NewIncidentsService(database.IncidentsRepo(), cfg.CommonConfig),
+		service.NewLeasesService(database.LeasesRepo(), cfg.CommonConfig),
+		cfg,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// addSite adds the site to the database of the scheduler and returns it with
+// its id.
+func addSite(t *testing.T, s *Scheduler, site model.Site) model.Site {
+	t.Helper()
+	ctx := context.Background()
+	if err := site.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.sites.AddSite(ctx, site); err != nil {
+		t.Fatal(err)
+	}
+	added, err := s.sites.GetSiteByUrl(ctx, site.Url)
+	if err != nil || added == nil {
+		t.Fatalf("GetSiteByUrl() = %v, %v", added, err)
+	}
+	if site.NextRunAt.Valid {
+		if !site.BaseRunAt.Valid {
+			site.BaseRunAt = site.NextRunAt
+		}
+		err := s.sites.UpdateNextRunAt(ctx, added.Id, site.BaseRunAt.Time, site.NextRunAt.Time)
+		if err != nil {
+			t.Fatal(err)
+		}
+		added.NextRunAt = site.NextRunAt
+		added.BaseRunAt = site.BaseRunAt
+	}
+	return *added
+}
+
+func nextRunAt(t *testing.T, s *Scheduler, site model.Site) time.Time {
+	t.Helper()
+	return getSite(t, s, site).NextRunAt.Time
+}
+
+func getSite(t *testing.T, s *Scheduler, site model.Site) model.Site {
+	t.Helper()
+	got, err := s.sites.GetSiteById(context.Background(), site.Id)
+	if err != nil || got == nil {
+		t.Fatalf("GetSiteById() = %v, %v", got, err)
+	}
+	return *got
+}
+
+func TestNewValidatesJitter(t *testing.T) {
+	for _, percent := range []int{-1, 101} {
+		_, err := New(nil, nil, nil, nil, nil, config.SchedulerConfig{
+			IntervalMin:   time.Minute,
+			TickSec:       time.Second,
+			LeaseTtlSec:   time.Second,
+			JitterPercent: percent,
+		})
+		if err == nil {
+			t.Errorf("New() with jitter %d%% succeeded", percent)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		percent  int
+		interval time.Duration
+		max      time.Duration
+	}{
+		{percent: 0, interval: time.Minute, max: 0},
+		{percent: 10, interval: time.Minute, max: 6 * time.Second},
+		{percent: 50, interval: 10 * time.Second, max: 5 * time.Second},
+		{percent: 100, interval: time.Second, max: time.Second},
+		// offset rounds down to zero
+		{percent: 10, interval: 5 * time.Nanosecond, max: 0},
+	}
+
+	for _, tt := range tests {
+		s := &Scheduler{config: config.SchedulerConfig{JitterPercent: tt.percent}}
+		for range 1000 {
+			if got := s.jitter(tt.interval); got < -tt.max || got > tt.max {
+				t.Fatalf("jitter(%s) with %d%% = %s, want within ±%s", tt.interval, tt.percent, got, tt.max)
+			}
+		}
+	}
+}
+
+func TestScheduleSite(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	interval := 5 * time.Minute
+
+	tests := []struct {
+		name string
+		// nextRunAt is the stored next run of the site, zero if it was never
+		// scheduled, and baseRunAt is the run without jitter, if it differs
+		nextRunAt   time.Time
+		baseRunAt   time.Time
+		publishErr  error
+		wantPublish bool
+		wantErr     bool
+		// the next run is expected within [wantFrom, wantTo]
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{
+			name:     "never scheduled site is spread across interval",
+			wantFrom: now,
+			wantTo:   now.Add(interval),
+		},
+		{
+			name:        "due site is published with jitter",
+			nextRunAt:   now.Add(-time.Second),
+			wantPublish: true,
+			wantFrom:    now.Add(-time.Second + interval - interval/10),
+			wantTo:      now.Add(-time.Second + interval + interval/10),
+		},
+		{
+			name:        "next run is counted from base without jitter",
+			nextRunAt:   now.Add(-time.Second),
+			baseRunAt:   now.Add(-time.Second - interval/10),
+			wantPublish: true,
+			wantFrom:    now.Add(-time.Second + interval - 2*interval/10),
+			wantTo:      now.Add(-time.Second + interval),
+		},
+		{
+			name:      "site which missed whole interval is spread again",
+			nextRunAt: now.Add(-interval - time.Second),
+			wantFrom:  now,
+			wantTo:    now.Add(interval),
+		},
+		{
+			name:       "site which was not confirmed keeps its next run",
+			nextRunAt:  now.Add(-time.Second),
+			publishErr: errors.New("nack"),
+			wantErr:    true,
+			wantFrom:   now.Add(-time.Second),
+			wantTo:     now.Add(-time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &publisher{err: tt.publishErr}
+			s := newScheduler(t, nil, b, config.SchedulerConfig{JitterPercent: 10})
+			site := model.Site{Url: "https://example.com", IntervalSec: int64(interval.Seconds())}
+			if !tt.nextRunAt.IsZero() {
+				site.NextRunAt = sql.NullTime{Time: tt.nextRunAt, Valid: true}
+			}
+			if !tt.baseRunAt.IsZero() {
+				site.BaseRunAt = sql.NullTime{Time: tt.baseRunAt, Valid: true}
+			}
+			site = addSite(t, s, site)
+
+			err := s.scheduleSite(context.Background(), site, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scheduleSite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if published := len(b.sites) == 1; published != tt.wantPublish {
+				t.Errorf("site was published: %v, want %v", published, tt.wantPublish)
+			}
+			if got := nextRunAt(t, s, site); got.Before(tt.wantFrom) || got.After(tt.wantTo) {
+				t.Errorf("next run at %s, want within [%s, %s]", got, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestScheduleSiteDoesNotDrift(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	interval := time.Minute
+	s := newScheduler(t, nil, &publisher{}, config.SchedulerConfig{JitterPercent: 50})
+	site := addSite(t, s, model.Site{
+		Url:         "https://example.com",
+		IntervalSec: int64(interval.Seconds()),
+		NextRunAt:   sql.NullTime{Time: start, Valid: true},
+	})
+
+	for i := 1; i <= 50; i++ {
+		if err := s.scheduleSite(context.Background(), site, site.NextRunAt.Time); err != nil {
+			t.Fatal(err)
+		}
+		site = getSite(t, s, site)
+
+		wantBase := start.Add(time.Duration(i) * interval)
+		if !site.BaseRunAt.Time.Equal(wantBase) {
+			t.Fatalf("base of run %d at %s, want %s", i, site.BaseRunAt.Time, wantBase)
+		}
+		if offset := site.NextRunAt.Time.Sub(wantBase); offset < -interval/2 || offset > interval/2 {
+			t.Fatalf("run %d is %s away from its base, want within ±%s", i, offset, interval/2)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/request"
+	"shm/internal/server/response"
+	"strconv"
+)
+
+func (s *Server) getChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := s.channels.GetAllChannels(context.Background())
+	if err != nil {
+		slog.Error("failed to get all channels", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, channels)
+}
+
+func (s *Server) getChannel(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	channel, err := s.channels.GetChannelById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get channel by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if channel == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no channel with such id"))
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, channel)
+}
+
+func (s *Server) addChannel(w http.ResponseWriter, r *http.Request) {
+	var channel model.Channel
+	if err := request.ReadJSON(r, &channel); err != nil {
+		slog.Error("invalid channel", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid channel"))
+		return
+	}
+
+	if err := channel.Normalize(); err != nil {
+		slog.Error("invalid channel", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid channel: %w", err))
+		return
+	}
+
+	id, err := s.channels.AddChannel(context.Background(), channel)
+	if err != nil {
+		slog.Error("failed to add channel", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	channel.Id = id
+
+	response.WriteJSON(w, http.StatusCreated, channel)
+}
+
+func (s *Server) updateChannel(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	var channel model.Channel
+	if err := request.ReadJSON(r, &channel); err != nil {
+		slog.Error("invalid channel", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid channel"))
+		return
+	}
+	channel.Id = int64(id)
+
+	if err := channel.Normalize(); err != nil {
+		slog.Error("invalid channel", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid channel: %w", err))
+		return
+	}
+
+	err = s.channels.UpdateChannel(context.Background(), channel)
+	if err != nil {
+		slog.Error("failed to update channel", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) deleteChannel(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = s.channels.DeleteChannelById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to delete channel by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) subscribeChannel(w http.ResponseWriter, r *http.Request) {
+	id, siteId, err := channelSiteIds(r)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	site, err := s.sites.GetSiteById(context.Background(), siteId)
+	if err != nil {
+		slog.Error("failed to get site by id", slog.Int64("id", siteId), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if site == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no site with such id"))
+		return
+	}
+
+	channel, err := s.channels.GetChannelById(context.Background(), id)
+	if err != nil {
+		slog.Error("failed to get channel by id", slog.Int64("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if channel == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no channel with such id"))
+		return
+	}
+
+	err = s.channels.SubscribeChannel(context.Background(), id, siteId)
+	if err != nil {
+		slog.Error(
+			"failed to subscribe channel",
+			slog.Int64("id", id),
+			slog.Int64("site_id", siteId),
+			sl.Error(err),
+		)
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) unsubscribeChannel(w http.ResponseWriter, r *http.Request) {
+	id, siteId, err := channelSiteIds(r)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = s.channels.UnsubscribeChannel(context.Background(), id, siteId)
+	if err != nil {
+		slog.Error(
+			"failed to unsubscribe channel",
+			slog.Int64("id", id),
+			slog.Int64("site_id", siteId),
+			sl.Error(err),
+		)
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func channelSiteIds(r *http.Request) (int64, int64, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	siteId, err := strconv.Atoi(r.PathValue("siteId"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return int64(id), int64(siteId), nil
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/server/response"
+	"strconv"
+)
+
+// getChannelDeliveries returns the last outbox deliveries to the channel.
+func (s *Server) getChannelDeliveries(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	limit := 50
+	if strLimit := r.URL.Query().Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil || limit < 1 {
+			response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid limit"))
+			return
+		}
+	}
+
+	deliveries, err := s.outbox.GetDeliveriesByChannelId(context.Background(), int64(id), limit)
+	if err != nil {
+		slog.Error("failed to get deliveries of channel", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, deliveries)
+}
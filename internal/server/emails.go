@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/request"
+	"shm/internal/server/response"
+	"strconv"
+)
+
+func (s *Server) getSiteEmails(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	emails, err := s.emails.GetEmailsBySiteId(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get emails of site", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, emails)
+}
+
+func (s *Server) addSiteEmail(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	var subscription model.EmailToSite
+	if err := request.ReadJSON(r, &subscription); err != nil {
+		slog.Error("invalid email", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid email"))
+		return
+	}
+	subscription.SiteId = int64(id)
+
+	if err := subscription.Normalize(); err != nil {
+		slog.Error("invalid email", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	site, err := s.sites.GetSiteById(context.Background(), subscription.SiteId)
+	if err != nil {
+		slog.Error("failed to get site by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if site == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no site with such id"))
+		return
+	}
+
+	if err := s.emails.AddEmailToSite(context.Background(), subscription); err != nil {
+		slog.Error("failed to add email to site", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusCreated, subscription)
+}
+
+func (s *Server) deleteSiteEmail(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	subscription := model.EmailToSite{Email: r.PathValue("email"), SiteId: int64(id)}
+	if err := subscription.Normalize(); err != nil {
+		slog.Error("invalid email", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.emails.DeleteEmailFromSite(context.Background(), subscription); err != nil {
+		slog.Error("failed to delete email from site", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
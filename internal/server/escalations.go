@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/request"
+	"shm/internal/server/response"
+	"strconv"
+)
+
+func (s *Server) getEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := s.escalations.GetAllPolicies(context.Background())
+	if err != nil {
+		slog.Error("failed to get all escalation policies", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, policies)
+}
+
+func (s *Server) getEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	policy, err := s.escalations.GetPolicyById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get escalation policy by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if policy == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no escalation policy with such id"))
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, policy)
+}
+
+func (s *Server) addEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy model.EscalationPolicy
+	if err := request.ReadJSON(r, &policy); err != nil {
+		slog.Error("invalid escalation policy", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid escalation policy"))
+		return
+	}
+
+	if err := policy.Normalize(); err != nil {
+		slog.Error("invalid escalation policy", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid escalation policy: %w", err))
+		return
+	}
+
+	id, err := s.escalations.AddPolicy(context.Background(), policy)
+	if err != nil {
+		slog.Error("failed to add escalation policy", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	policy.Id = id
+
+	response.WriteJSON(w, http.StatusCreated, policy)
+}
+
+func (s *Server) updateEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	var policy model.EscalationPolicy
+	if err := request.ReadJSON(r, &policy); err != nil {
+		slog.Error("invalid escalation policy", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid escalation policy"))
+		return
+	}
+	policy.Id = int64(id)
+
+	if err := policy.Normalize(); err != nil {
+		slog.Error("invalid escalation policy", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid escalation policy: %w", err))
+		return
+	}
+
+	err = s.escalations.UpdatePolicy(context.Background(), policy)
+	if err != nil {
+		slog.Error("failed to update escalation policy", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) deleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = s.escalations.DeletePolicyById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to delete escalation policy by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
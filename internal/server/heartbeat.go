@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/response"
+	"time"
+)
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
+	url := model.HeartbeatUrl(r.PathValue("token"))
+	ctx := context.Background()
+
+	site, err := s.sites.GetSiteByUrl(ctx, url)
+	if err != nil {
+		slog.Error("failed to get heartbeat by url", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if site == nil || site.Type != model.CheckTypeHeartbeat {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no heartbeat with such token"))
+		return
+	}
+
+	now := time.Now()
+	if err = s.sites.UpdateLastPing(ctx, site.Id, now); err != nil {
+		slog.Error("failed to update last ping of heartbeat", sl.Site(*site), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	site.Heartbeat.LastPingAt.Time = now
+	site.Heartbeat.LastPingAt.Valid = true
+
+	result := model.CheckResult{
+		Site:       *site,
+		Time:       now,
+		Successful: true,
+	}
+	if result.Id, err = s.results.AddResult(ctx, result); err != nil {
+		slog.Error("failed to add result of heartbeat", sl.Site(*site), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = s.broker.PublishResult(ctx, result); err != nil {
+		slog.Error("failed to send result of heartbeat to broker", sl.Site(*site), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	slog.Info("heartbeat ping received", sl.Site(*site))
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+func TestPing(t *testing.T) {
+	b := &publisher{}
+	s := newServer(t, b)
+	heartbeat := addSite(t, s, model.Site{Type: model.CheckTypeHeartbeat, Heartbeat: model.HeartbeatSpec{PeriodSec: 60}})
+	token, err := heartbeat.HeartbeatToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		wantCode    int
+		wantResults int
+	}{
+		{name: "unknown token", token: "unknown", wantCode: http.StatusNotFound},
+		{name: "heartbeat", token: token, wantCode: http.StatusNoContent, wantResults: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b.results = nil
+			before := time.Now()
+			if code := serve(t, s, http.MethodPost, "/heartbeat/"+tt.token, ""); code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", code, tt.wantCode)
+			}
+			if len(b.results) != tt.wantResults {
+				t.Fatalf("published %d results, want %d", len(b.results), tt.wantResults)
+			}
+			if tt.wantResults == 0 {
+				return
+			}
+
+			result := b.results[0]
+			if !result.Successful || result.Id == 0 || result.Site.Id != heartbeat.Id {
+				t.Errorf("published result = %+v, want successful stored result of heartbeat", result)
+			}
+			got := getSite(t, s, heartbeat.Url)
+			if !got.Heartbeat.LastPingAt.Valid || got.Heartbeat.LastPingAt.Time.Before(before) {
+				t.Errorf("last ping at %v, want after %s", got.Heartbeat.LastPingAt, before)
+			}
+		})
+	}
+}
+
+func TestUpdateHeartbeatKeepsToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantToken bool
+		wantType  model.CheckType
+	}{
+		{
+			name:      "without url",
+			body:      `{"heartbeat":{"periodSec":120,"graceSec":30}}`,
+			wantToken: true,
+			wantType:  model.CheckTypeHeartbeat,
+		},
+		{
+			name:      "without url with type",
+			body:      `{"type":"heartbeat","heartbeat":{"periodSec":120}}`,
+			wantToken: true,
+			wantType:  model.CheckTypeHeartbeat,
+		},
+		{
+			name:     "other check",
+			body:     `{"url":"https://example.com"}`,
+			wantType: model.CheckTypeHTTP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, &publisher{})
+			heartbeat := addSite(t, s, model.Site{Type: model.CheckTypeHeartbeat, Heartbeat: model.HeartbeatSpec{PeriodSec: 60}})
+
+			path := fmt.Sprintf("/sites/%d", heartbeat.Id)
+			if code := serve(t, s, http.MethodPut, path, tt.body); code != http.StatusNoContent {
+				t.Fatalf("status = %d", code)
+			}
+
+			got, err := s.sites.GetSiteById(context.Background(), heartbeat.Id)
+			if err != nil || got == nil {
+				t.Fatalf("GetSiteById() = %v, %v", got, err)
+			}
+			if kept := got.Url == heartbeat.Url; kept != tt.wantToken {
+				t.Errorf("url = %s, token of %s kept: %v, want %v", got.Url, heartbeat.Url, kept, tt.wantToken)
+			}
+			if got.Type != tt.wantType {
+				t.Errorf("type = %s, want %s", got.Type, tt.wantType)
+			}
+		})
+	}
+}
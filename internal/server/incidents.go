@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/request"
+	"shm/internal/server/response"
+	"strconv"
+	"time"
+)
+
+func (s *Server) getIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents, err := s.incidents.GetAllIncidents(context.Background())
+	if err != nil {
+		slog.Error("failed to get all incidents", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, incidents)
+}
+
+func (s *Server) getSiteIncidents(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	incidents, err := s.incidents.GetAllIncidentsBySiteId(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get incidents by site id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, incidents)
+}
+
+// getUptime returns the uptime report of a site for the last days, which are
+// given by the query parameter and default to 30.
+func (s *Server) getUptime(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	days := 30
+	if strDays := r.URL.Query().Get("days"); strDays != "" {
+		days, err = strconv.Atoi(strDays)
+		if err != nil || days < 1 {
+			response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid days"))
+			return
+		}
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+	report, err := s.incidents.GetUptimeReport(context.Background(), int64(id), from, to)
+	if err != nil {
+		slog.Error("failed to get uptime report", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, report)
+}
+
+type ackRequest struct {
+	By string `json:"by"`
+}
+
+// ackIncident acknowledges the open incident, which stops its escalation.
+func (s *Server) ackIncident(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	// body is optional
+	var req ackRequest
+	if err := request.ReadJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("invalid acknowledgement", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid acknowledgement"))
+		return
+	}
+	if req.By == "" {
+		req.By = "api"
+	}
+
+	ctx := context.Background()
+	incident, err := s.incidents.GetIncidentById(ctx, int64(id))
+	if err != nil {
+		slog.Error("failed to get incident by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if incident == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no incident with such id"))
+		return
+	}
+
+	acked, err := s.incidents.AcknowledgeIncident(ctx, incident.Id, req.By, time.Now())
+	if err != nil {
+		slog.Error("failed to acknowledge incident", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if !acked {
+		response.WriteError(
+			w,
+			http.StatusConflict,
+			fmt.Errorf("incident is already acknowledged or resolved"),
+		)
+		return
+	}
+
+	slog.Info("incident was acknowledged", slog.Int("id", id), slog.String("by", req.By))
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) pauseSite(w http.ResponseWriter, r *http.Request) {
+	s.setSiteState(w, r, model.SiteStatePaused)
+}
+
+// resumeSite makes the state of a site unknown, so that the next check
+// decides whether it is up or down. A site with an open incident gets the
+// state of the incident back, so the next successful check resolves it.
+func (s *Server) resumeSite(w http.ResponseWriter, r *http.Request) {
+	s.setSiteState(w, r, model.SiteStateUnknown)
+}
+
+func (s *Server) setSiteState(w http.ResponseWriter, r *http.Request, state model.SiteState) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	ctx := context.Background()
+	site, err := s.sites.GetSiteById(ctx, int64(id))
+	if err != nil {
+		slog.Error("failed to get site by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if site == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no site with such id"))
+		return
+	}
+
+	if state == model.SiteStateUnknown {
+		incident, err := s.incidents.GetOpenIncidentBySiteId(ctx, site.Id)
+		if err != nil {
+			slog.Error("failed to get open incident", sl.Site(*site), sl.Error(err))
+			response.WriteError(w, http.StatusInternalServerError, err)
+			return
+		} else if incident != nil {
+			state = incident.State()
+		}
+	}
+
+	if err = s.incidents.SetSiteState(ctx, site.Id, state, nil); err != nil {
+		slog.Error(
+			"failed to set state of site",
+			sl.Site(*site),
+			slog.String("state", string(state)),
+			sl.Error(err),
+		)
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
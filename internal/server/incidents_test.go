@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"shm/internal/model"
+	"testing"
+	"time"
+)
+
+func TestPauseAndResume(t *testing.T) {
+	tests := []struct {
+		name      string
+		incident  model.IncidentKind
+		wantState model.SiteState
+	}{
+		{name: "without incident", wantState: model.SiteStateUnknown},
+		{name: "down incident", incident: model.IncidentKindDown, wantState: model.SiteStateDown},
+		{name: "degraded incident", incident: model.IncidentKindDegraded, wantState: model.SiteStateDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, nil)
+			ctx := context.Background()
+			site := addSite(t, s, model.Site{Url: "https://example.com"})
+			if tt.incident != "" {
+				incident := model.Incident{SiteId: site.Id, Kind: tt.incident, StartedAt: time.Now()}
+				if _, err := s.incidents.OpenIncident(ctx, incident, nil); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.incidents.SetSiteState(ctx, site.Id, incident.State(), nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			path := fmt.Sprintf("/sites/%d", site.Id)
+			if code := serve(t, s, http.MethodPost, path+"/pause", ""); code != http.StatusNoContent {
+				t.Fatalf("pause status = %d", code)
+			}
+			if state := getSite(t, s, site.Url).State; state != model.SiteStatePaused {
+				t.Errorf("state after pause = %s, want paused", state)
+			}
+
+			// the state of the open incident is restored, so the next
+			// successful check goes from down or degraded to up and resolves it
+			if code := serve(t, s, http.MethodPost, path+"/resume", ""); code != http.StatusNoContent {
+				t.Fatalf("resume status = %d", code)
+			}
+			if state := getSite(t, s, site.Url).State; state != tt.wantState {
+				t.Errorf("state after resume = %s, want %s", state, tt.wantState)
+			}
+		})
+	}
+}
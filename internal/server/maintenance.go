@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/request"
+	"shm/internal/server/response"
+	"strconv"
+)
+
+func (s *Server) getMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	windows, err := s.maintenance.GetAllWindows(context.Background())
+	if err != nil {
+		slog.Error("failed to get all maintenance windows", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, windows)
+}
+
+func (s *Server) getMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	window, err := s.maintenance.GetWindowById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get maintenance window by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if window == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no maintenance window with such id"))
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, window)
+}
+
+func (s *Server) addMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var window model.MaintenanceWindow
+	if err := request.ReadJSON(r, &window); err != nil {
+		slog.Error("invalid maintenance window", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid maintenance window"))
+		return
+	}
+
+	if err := window.Normalize(); err != nil {
+		slog.Error("invalid maintenance window", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid maintenance window: %w", err))
+		return
+	}
+
+	id, err := s.maintenance.AddWindow(context.Background(), window)
+	if err != nil {
+		slog.Error("failed to add maintenance window", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	window.Id = id
+
+	response.WriteJSON(w, http.StatusCreated, window)
+}
+
+func (s *Server) updateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	var window model.MaintenanceWindow
+	if err := request.ReadJSON(r, &window); err != nil {
+		slog.Error("invalid maintenance window", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid maintenance window"))
+		return
+	}
+	window.Id = int64(id)
+
+	if err := window.Normalize(); err != nil {
+		slog.Error("invalid maintenance window", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid maintenance window: %w", err))
+		return
+	}
+
+	err = s.maintenance.UpdateWindow(context.Background(), window)
+	if err != nil {
+		slog.Error("failed to update maintenance window", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) deleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = s.maintenance.DeleteWindowById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to delete maintenance window by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/response"
+	"strconv"
+	"time"
+)
+
+// getOutbox returns the last notification deliveries, optionally filtered by
+// status, e.g. ?status=failed lists dead-lettered deliveries.
+func (s *Server) getOutbox(w http.ResponseWriter, r *http.Request) {
+	status := model.DeliveryStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", model.DeliveryStatusPending, model.DeliveryStatusDelivered, model.DeliveryStatusFailed:
+	default:
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid status"))
+		return
+	}
+
+	limit := 50
+	if strLimit := r.URL.Query().Get("limit"); strLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil || limit < 1 {
+			response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid limit"))
+			return
+		}
+	}
+
+	deliveries, err := s.outbox.GetDeliveries(context.Background(), status, limit)
+	if err != nil {
+		slog.Error("failed to get notification deliveries", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, deliveries)
+}
+
+func (s *Server) getOutboxDelivery(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	delivery, err := s.outbox.GetDeliveryById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get notification delivery by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if delivery == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no delivery with such id"))
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, delivery)
+}
+
+// replayOutboxDelivery makes the failed delivery pending again with fresh
+// attempts, so that the notifier sends it shortly. The delivery is sent
+// with the current config of its channel.
+func (s *Server) replayOutboxDelivery(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	ctx := context.Background()
+	delivery, err := s.outbox.GetDeliveryById(ctx, int64(id))
+	if err != nil {
+		slog.Error("failed to get notification delivery by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if delivery == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no delivery with such id"))
+		return
+	} else if delivery.Status != model.DeliveryStatusFailed {
+		response.WriteError(w, http.StatusConflict, fmt.Errorf("only failed deliveries can be replayed"))
+		return
+	}
+
+	if delivery.Channel.Id != 0 {
+		channel, err := s.channels.GetChannelById(ctx, delivery.Channel.Id)
+		if err != nil {
+			slog.Error("failed to get channel by id", slog.Int64("id", delivery.Channel.Id), sl.Error(err))
+			response.WriteError(w, http.StatusInternalServerError, err)
+			return
+		} else if channel == nil {
+			response.WriteError(w, http.StatusNotFound, fmt.Errorf("channel of delivery was deleted"))
+			return
+		}
+		delivery.Channel = *channel
+	}
+
+	delivery.Status = model.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.outbox.UpdateDelivery(ctx, *delivery); err != nil {
+		slog.Error("failed to replay notification delivery", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	slog.Info("notification delivery was replayed", slog.Int("id", id))
+	response.WriteJSON(w, http.StatusOK, delivery)
+}
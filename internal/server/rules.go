@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shm/internal/lib/sl"
+	"shm/internal/model"
+	"shm/internal/server/request"
+	"shm/internal/server/response"
+	"strconv"
+)
+
+func (s *Server) getRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.rules.GetAllRules(context.Background())
+	if err != nil {
+		slog.Error("failed to get all alert rules", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, rules)
+}
+
+func (s *Server) getRule(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	rule, err := s.rules.GetRuleById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get alert rule by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if rule == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no rule with such id"))
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, rule)
+}
+
+func (s *Server) addRule(w http.ResponseWriter, r *http.Request) {
+	var rule model.AlertRule
+	if err := request.ReadJSON(r, &rule); err != nil {
+		slog.Error("invalid alert rule", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid rule"))
+		return
+	}
+
+	if err := rule.Normalize(); err != nil {
+		slog.Error("invalid alert rule", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid rule: %w", err))
+		return
+	}
+
+	id, err := s.rules.AddRule(context.Background(), rule)
+	if err != nil {
+		slog.Error("failed to add alert rule", sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	rule.Id = id
+
+	response.WriteJSON(w, http.StatusCreated, rule)
+}
+
+func (s *Server) updateRule(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	var rule model.AlertRule
+	if err := request.ReadJSON(r, &rule); err != nil {
+		slog.Error("invalid alert rule", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid rule"))
+		return
+	}
+	rule.Id = int64(id)
+
+	if err := rule.Normalize(); err != nil {
+		slog.Error("invalid alert rule", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid rule: %w", err))
+		return
+	}
+
+	err = s.rules.UpdateRule(context.Background(), rule)
+	if err != nil {
+		slog.Error("failed to update alert rule", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) deleteRule(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = s.rules.DeleteRuleById(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to delete alert rule by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"shm/internal/broker"
 	"shm/internal/config"
 	"shm/internal/lib/sl"
 	"shm/internal/model"
@@ -16,12 +17,35 @@ import (
 )
 
 type Server struct {
-	server *http.Server
-	sites  *service.SitesService
-	config config.ServerConfig
+	server       *http.Server
+	broker       broker.MessageBroker
+	sites        *service.SitesService
+	results      *service.ResultsService
+	certificates *service.CertificatesService
+	incidents    *service.IncidentsService
+	rules        *service.AlertRulesService
+	maintenance  *service.MaintenanceService
+	escalations  *service.EscalationPoliciesService
+	channels     *service.ChannelsService
+	emails       *service.EmailsService
+	outbox       *service.NotificationDeliveriesService
+	config       config.ServerConfig
 }
 
-func New(sites *service.SitesService, config config.ServerConfig) *Server {
+func New(
+	broker broker.MessageBroker,
+	sites *service.SitesService,
+	results *service.ResultsService,
+	certificates *service.CertificatesService,
+	incidents *service.IncidentsService,
+	rules *service.AlertRulesService,
+	maintenance *service.MaintenanceService,
+	escalations *service.EscalationPoliciesService,
+	channels *service.ChannelsService,
+	emails *service.EmailsService,
+	outbox *service.NotificationDeliveriesService,
+	config config.ServerConfig,
+) *Server {
 	router := http.NewServeMux()
 
 	s := &Server{
@@ -29,14 +53,62 @@ func New(sites *service.SitesService, config config.ServerConfig) *Server {
 			Addr:    config.Address,
 			Handler: middleware.Logging(router),
 		},
-		sites:  sites,
-		config: config,
+		broker:       broker,
+		sites:        sites,
+		results:      results,
+		certificates: certificates,
+		incidents:    incidents,
+		rules:        rules,
+		maintenance:  maintenance,
+		escalations:  escalations,
+		channels:     channels,
+		emails:       emails,
+		outbox:       outbox,
+		config:       config,
 	}
 
 	router.HandleFunc("GET /sites", s.getSites)
 	router.HandleFunc("GET /sites/{id}", s.getSite)
 	router.HandleFunc("POST /sites", s.addSite)
+	router.HandleFunc("PUT /sites/{id}", s.updateSite)
 	router.HandleFunc("DELETE /sites/{id}", s.deleteSite)
+	router.HandleFunc("GET /sites/{id}/certificate", s.getCertificate)
+	router.HandleFunc("GET /sites/{id}/incidents", s.getSiteIncidents)
+	router.HandleFunc("GET /sites/{id}/uptime", s.getUptime)
+	router.HandleFunc("GET /sites/{id}/emails", s.getSiteEmails)
+	router.HandleFunc("POST /sites/{id}/emails", s.addSiteEmail)
+	router.HandleFunc("DELETE /sites/{id}/emails/{email}", s.deleteSiteEmail)
+	router.HandleFunc("POST /sites/{id}/pause", s.pauseSite)
+	router.HandleFunc("POST /sites/{id}/resume", s.resumeSite)
+	router.HandleFunc("GET /incidents", s.getIncidents)
+	router.HandleFunc("POST /incidents/{id}/ack", s.ackIncident)
+	router.HandleFunc("GET /rules", s.getRules)
+	router.HandleFunc("GET /rules/{id}", s.getRule)
+	router.HandleFunc("POST /rules", s.addRule)
+	router.HandleFunc("PUT /rules/{id}", s.updateRule)
+	router.HandleFunc("DELETE /rules/{id}", s.deleteRule)
+	router.HandleFunc("GET /maintenance", s.getMaintenanceWindows)
+	router.HandleFunc("GET /maintenance/{id}", s.getMaintenanceWindow)
+	router.HandleFunc("POST /maintenance", s.addMaintenanceWindow)
+	router.HandleFunc("PUT /maintenance/{id}", s.updateMaintenanceWindow)
+	router.HandleFunc("DELETE /maintenance/{id}", s.deleteMaintenanceWindow)
+	router.HandleFunc("GET /escalations", s.getEscalationPolicies)
+	router.HandleFunc("GET /escalations/{id}", s.getEscalationPolicy)
+	router.HandleFunc("POST /escalations", s.addEscalationPolicy)
+	router.HandleFunc("PUT /escalations/{id}", s.updateEscalationPolicy)
+	router.HandleFunc("DELETE /escalations/{id}", s.deleteEscalationPolicy)
+	router.HandleFunc("GET /channels", s.getChannels)
+	router.HandleFunc("GET /channels/{id}", s.getChannel)
+	router.HandleFunc("POST /channels", s.addChannel)
+	router.HandleFunc("PUT /channels/{id}", s.updateChannel)
+	router.HandleFunc("DELETE /channels/{id}", s.deleteChannel)
+	router.HandleFunc("POST /channels/{id}/sites/{siteId}", s.subscribeChannel)
+	router.HandleFunc("DELETE /channels/{id}/sites/{siteId}", s.unsubscribeChannel)
+	router.HandleFunc("GET /channels/{id}/deliveries", s.getChannelDeliveries)
+	router.HandleFunc("GET /outbox", s.getOutbox)
+	router.HandleFunc("GET /outbox/{id}", s.getOutboxDelivery)
+	router.HandleFunc("POST /outbox/{id}/replay", s.replayOutboxDelivery)
+	router.HandleFunc("POST /heartbeat/{token}", s.ping)
 
 	return s
 }
@@ -86,13 +158,76 @@ func (s *Server) addSite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := s.sites.AddSite(context.Background(), site.Url)
+	if err := site.Normalize(); err != nil {
+		slog.Error("invalid site", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid site: %w", err))
+		return
+	}
+
+	err := s.sites.AddSite(context.Background(), site)
 	if err != nil {
 		slog.Error("failed to add site", sl.Error(err))
 		response.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	// url of a heartbeat contains generated token, which the client needs to
+	// send pings
+	if site.Type == model.CheckTypeHeartbeat {
+		response.WriteJSON(w, http.StatusCreated, site)
+		return
+	}
+	response.WriteJSON(w, http.StatusNoContent, "")
+}
+
+func (s *Server) updateSite(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	var site model.Site
+	if err := request.ReadJSON(r, &site); err != nil {
+		slog.Error("invalid site", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid site"))
+		return
+	}
+	site.Id = int64(id)
+
+	existing, err := s.sites.GetSiteById(context.Background(), site.Id)
+	if err != nil {
+		slog.Error("failed to get site by id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if existing == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no site with such id"))
+		return
+	}
+
+	// a heartbeat updated without url keeps its token, otherwise Normalize
+	// generates a new one and pings of the client are not accepted anymore
+	if existing.Type == model.CheckTypeHeartbeat && site.Url == "" &&
+		(site.Type == "" || site.Type == model.CheckTypeHeartbeat) {
+		site.Url = existing.Url
+		site.Type = model.CheckTypeHeartbeat
+	}
+
+	if err := site.Normalize(); err != nil {
+		slog.Error("invalid site", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid site: %w", err))
+		return
+	}
+
+	err = s.sites.UpdateSite(context.Background(), site)
+	if err != nil {
+		slog.Error("failed to update site", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	response.WriteJSON(w, http.StatusNoContent, "")
 }
 
@@ -118,3 +253,25 @@ func (s *Server) deleteSite(w http.ResponseWriter, r *http.Request) {
 
 	response.WriteJSON(w, http.StatusNoContent, "")
 }
+
+func (s *Server) getCertificate(w http.ResponseWriter, r *http.Request) {
+	strId := r.PathValue("id")
+	id, err := strconv.Atoi(strId)
+	if err != nil {
+		slog.Error("invalid id", sl.Error(err))
+		response.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid id"))
+		return
+	}
+
+	cert, err := s.certificates.GetCertificateBySiteId(context.Background(), int64(id))
+	if err != nil {
+		slog.Error("failed to get certificate by site id", slog.Int("id", id), sl.Error(err))
+		response.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if cert == nil {
+		response.WriteError(w, http.StatusNotFound, fmt.Errorf("no certificate for such site"))
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, cert)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"shm/internal/broker"
+	"shm/internal/config"
+	"shm/internal/db"
+	"shm/internal/model"
+	"shm/internal/service"
+	"strings"
+	"testing"
+	"time"
+)
+
+// publisher is the broker of tests. It records published results.
+type publisher struct {
+	broker.MessageBroker
+	results []model.CheckResult
+}
+
+func (p *publisher) PublishResult(ctx context.Context, result model.CheckResult) error {
+	p.results = append(p.results, result)
+	return nil
+}
+
+// newServer returns a server with a new SQLite database which publishes to
+// the broker.
+func newServer(t *testing.T, b broker.MessageBroker) *Server {
+	t.Helper()
+	database, err := db.NewSQLite(filepath.Join(t.TempDir(), "shm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	common := config.CommonConfig{DbQueryTimeoutSec: 5 * time.Second}
+	return New(
+		b,
+		service.NewSitesService(database.SitesRepo(), common),
+		service.NewResultsService(database.ResultsRepo(), common),
+		service.NewCertificatesService(database.CertificatesRepo(), common),
+		service.NewIncidentsService(database.IncidentsRepo(), common),
+		service.NewAlertRulesService(database.AlertRulesRepo(), common),
+		service.NewMaintenanceService(database.MaintenanceRepo(), common),
+		service.NewEscalationPoliciesService(database.EscalationPoliciesRepo(), common),
+		service.NewChannelsService(database.ChannelsRepo(), common),
+		service.NewEmailsService(database.EmailsRepo(), common),
+		service.NewNotificationDeliveriesService(database.NotificationDeliveriesRepo(), common),
+		config.ServerConfig{CommonConfig: common},
+	)
+}
+
+// serve sends the request to the router of the server and returns the
+// status of the response.
+func serve(t *testing.T, s *Server, method, path, body string) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w.Code
+}
+
+// addSite adds the site to the database of the server and returns it with
+// its id.
+func addSite(t *testing.T, s *Server, site model.Site) model.Site {
+	t.Helper()
+	ctx := context.Background()
+	if err := site.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.sites.AddSite(ctx, site); err != nil {
+		t.Fatal(err)
+	}
+	return getSite(t, s, site.Url)
+}
+
+func getSite(t *testing.T, s *Server, url string) model.Site {
+	t.Helper()
+	site, err := s.sites.GetSiteByUrl(context.Background(), url)
+	if err != nil || site == nil {
+		t.Fatalf("GetSiteByUrl() = %v, %v", site, err)
+	}
+	return *site
+}
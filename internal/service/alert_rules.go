@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+)
+
+type AlertRulesService struct {
+	rules  repository.AlertRulesProvider
+	config config.CommonConfig
+}
+
+func NewAlertRulesService(
+	rules repository.AlertRulesProvider,
+	config config.CommonConfig,
+) *AlertRulesService {
+	return &AlertRulesService{
+		rules:  rules,
+		config: config,
+	}
+}
+
+func (a *AlertRulesService) AddRule(ctx context.Context, rule model.AlertRule) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return a.rules.AddRule(ctx, rule)
+}
+
+func (a *AlertRulesService) UpdateRule(ctx context.Context, rule model.AlertRule) error {
+	ctx, cancel := context.WithTimeout(ctx, a.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return a.rules.UpdateRule(ctx, rule)
+}
+
+func (a *AlertRulesService) DeleteRuleById(ctx context.Context, ruleId int64) error {
+	ctx, cancel := context.WithTimeout(ctx, a.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return a.rules.DeleteRuleById(ctx, ruleId)
+}
+
+func (a *AlertRulesService) GetRuleById(ctx context.Context, ruleId int64) (*model.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	rule, err := a.rules.GetRuleById(ctx, ruleId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (a *AlertRulesService) GetAllRules(ctx context.Context) ([]model.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return a.rules.GetAllRules(ctx)
+}
+
+// GetEffectiveRules returns the rules which decide when the site is down:
+// rules of the site if there are any, otherwise rules of its tags.
+func (a *AlertRulesService) GetEffectiveRules(
+	ctx context.Context,
+	site model.Site,
+) ([]model.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	rules, err := a.rules.GetRulesForSite(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	var siteRules, tagRules []model.AlertRule
+	for _, rule := range rules {
+		if rule.SiteId == site.Id {
+			siteRules = append(siteRules, rule)
+		} else {
+			tagRules = append(tagRules, rule)
+		}
+	}
+	if len(siteRules) > 0 {
+		return siteRules, nil
+	}
+	return tagRules, nil
+}
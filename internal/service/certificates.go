@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+)
+
+type CertificatesService struct {
+	certificates repository.CertificatesProvider
+	config       config.CommonConfig
+}
+
+func NewCertificatesService(
+	certificates repository.CertificatesProvider,
+	config config.CommonConfig,
+) *CertificatesService {
+	return &CertificatesService{
+		certificates: certificates,
+		config:       config,
+	}
+}
+
+func (c *CertificatesService) SaveCertificate(ctx context.Context, cert model.Certificate) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.certificates.SaveCertificate(ctx, cert)
+}
+
+func (c *CertificatesService) GetCertificateBySiteId(
+	ctx context.Context,
+	siteId int64,
+) (*model.Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	cert, err := c.certificates.GetCertificateBySiteId(ctx, siteId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (c *CertificatesService) SetWarnedDays(
+	ctx context.Context,
+	siteId int64,
+	days int,
+	notification model.Notification,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.certificates.SetWarnedDays(ctx, siteId, days, notification)
+}
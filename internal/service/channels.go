@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+)
+
+type ChannelsService struct {
+	channels repository.ChannelsProvider
+	config   config.CommonConfig
+}
+
+func NewChannelsService(
+	channels repository.ChannelsProvider,
+	config config.CommonConfig,
+) *ChannelsService {
+	return &ChannelsService{
+		channels: channels,
+		config:   config,
+	}
+}
+
+func (c *ChannelsService) AddChannel(ctx context.Context, channel model.Channel) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.AddChannel(ctx, channel)
+}
+
+func (c *ChannelsService) UpdateChannel(ctx context.Context, channel model.Channel) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.UpdateChannel(ctx, channel)
+}
+
+func (c *ChannelsService) DeleteChannelById(ctx context.Context, channelId int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.DeleteChannelById(ctx, channelId)
+}
+
+func (c *ChannelsService) GetChannelById(
+	ctx context.Context,
+	channelId int64,
+) (*model.Channel, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	channel, err := c.channels.GetChannelById(ctx, channelId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (c *ChannelsService) GetAllChannels(ctx context.Context) ([]model.Channel, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.GetAllChannels(ctx)
+}
+
+func (c *ChannelsService) GetAllSubscribedOnSiteChannels(
+	ctx context.Context,
+	url string,
+) ([]model.Channel, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.GetAllSubscribedOnSiteChannels(ctx, url)
+}
+
+func (c *ChannelsService) SubscribeChannel(
+	ctx context.Context,
+	channelId int64,
+	siteId int64,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.SubscribeChannel(ctx, channelId, siteId)
+}
+
+func (c *ChannelsService) UnsubscribeChannel(
+	ctx context.Context,
+	channelId int64,
+	siteId int64,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.channels.UnsubscribeChannel(ctx, channelId, siteId)
+}
@@ -42,3 +42,10 @@ func (c *ChatsService) GetAllSubscribedOnSiteChats(
 
 	return c.chats.GetAllSubscribedOnSiteChats(ctx, url)
 }
+
+func (c *ChatsService) HasSite(ctx context.Context, chatId int64, siteId int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return c.chats.HasSite(ctx, chatId, siteId)
+}
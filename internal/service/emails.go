@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+)
+
+type EmailsService struct {
+	emails repository.EmailsProvider
+	config config.CommonConfig
+}
+
+func NewEmailsService(emails repository.EmailsProvider, config config.CommonConfig) *EmailsService {
+	return &EmailsService{
+		emails: emails,
+		config: config,
+	}
+}
+
+func (e *EmailsService) AddEmailToSite(ctx context.Context, subscription model.EmailToSite) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.emails.AddEmailToSite(ctx, subscription)
+}
+
+func (e *EmailsService) DeleteEmailFromSite(
+	ctx context.Context,
+	subscription model.EmailToSite,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.emails.DeleteEmailFromSite(ctx, subscription)
+}
+
+func (e *EmailsService) GetEmailsBySiteId(
+	ctx context.Context,
+	siteId int64,
+) ([]model.EmailToSite, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.emails.GetEmailsBySiteId(ctx, siteId)
+}
+
+func (e *EmailsService) GetAllSubscribedOnSiteEmails(
+	ctx context.Context,
+	url string,
+) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.emails.GetAllSubscribedOnSiteEmails(ctx, url)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+)
+
+type EscalationPoliciesService struct {
+	policies repository.EscalationPoliciesProvider
+	config   config.CommonConfig
+}
+
+func NewEscalationPoliciesService(
+	policies repository.EscalationPoliciesProvider,
+	config config.CommonConfig,
+) *EscalationPoliciesService {
+	return &EscalationPoliciesService{
+		policies: policies,
+		config:   config,
+	}
+}
+
+func (e *EscalationPoliciesService) AddPolicy(
+	ctx context.Context,
+	policy model.EscalationPolicy,
+) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.policies.AddPolicy(ctx, policy)
+}
+
+func (e *EscalationPoliciesService) UpdatePolicy(
+	ctx context.Context,
+	policy model.EscalationPolicy,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.policies.UpdatePolicy(ctx, policy)
+}
+
+func (e *EscalationPoliciesService) DeletePolicyById(ctx context.Context, policyId int64) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.policies.DeletePolicyById(ctx, policyId)
+}
+
+func (e *EscalationPoliciesService) GetPolicyById(
+	ctx context.Context,
+	policyId int64,
+) (*model.EscalationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	policy, err := e.policies.GetPolicyById(ctx, policyId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (e *EscalationPoliciesService) GetAllPolicies(
+	ctx context.Context,
+) ([]model.EscalationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return e.policies.GetAllPolicies(ctx)
+}
+
+// GetEffectivePolicy returns the policy of the site if there is one,
+// otherwise the first policy of its tags, or nil if there are none.
+func (e *EscalationPoliciesService) GetEffectivePolicy(
+	ctx context.Context,
+	site model.Site,
+) (*model.EscalationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	policies, err := e.policies.GetPoliciesForSite(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	var effective *model.EscalationPolicy
+	for i, policy := range policies {
+		if policy.SiteId == site.Id {
+			return &policies[i], nil
+		}
+		if effective == nil {
+			effective = &policies[i]
+		}
+	}
+	return effective, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+	"time"
+)
+
+type IncidentsService struct {
+	incidents repository.IncidentsProvider
+	config    config.CommonConfig
+}
+
+func NewIncidentsService(
+	incidents repository.IncidentsProvider,
+	config config.CommonConfig,
+) *IncidentsService {
+	return &IncidentsService{
+		incidents: incidents,
+		config:    config,
+	}
+}
+
+func (i *IncidentsService) OpenIncident(
+	ctx context.Context,
+	incident model.Incident,
+	notification *model.Notification,
+) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.OpenIncident(ctx, incident, notification)
+}
+
+func (i *IncidentsService) AddFailureToIncident(
+	ctx context.Context,
+	incidentId int64,
+	resultId int64,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.AddFailureToIncident(ctx, incidentId, resultId)
+}
+
+func (i *IncidentsService) ResolveIncident(
+	ctx context.Context,
+	incident model.Incident,
+	endedAt time.Time,
+	notification *model.Notification,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.ResolveIncident(ctx, incident, endedAt, notification)
+}
+
+func (i *IncidentsService) AcknowledgeIncident(
+	ctx context.Context,
+	incidentId int64,
+	by string,
+	at time.Time,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.AcknowledgeIncident(ctx, incidentId, by, at)
+}
+
+func (i *IncidentsService) AdvanceEscalationStep(
+	ctx context.Context,
+	incidentId int64,
+	step int,
+	notification *model.Notification,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.AdvanceEscalationStep(ctx, incidentId, step, notification)
+}
+
+func (i *IncidentsService) SetSiteState(
+	ctx context.Context,
+	siteId int64,
+	state model.SiteState,
+	notification *model.Notification,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.SetSiteState(ctx, siteId, state, notification)
+}
+
+func (i *IncidentsService) GetIncidentById(
+	ctx context.Context,
+	incidentId int64,
+) (*model.Incident, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	incident, err := i.incidents.GetIncidentById(ctx, incidentId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &incident, nil
+}
+
+func (i *IncidentsService) GetOpenIncidentBySiteId(
+	ctx context.Context,
+	siteId int64,
+) (*model.Incident, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	incident, err := i.incidents.GetOpenIncidentBySiteId(ctx, siteId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &incident, nil
+}
+
+func (i *IncidentsService) GetAllIncidents(ctx context.Context) ([]model.Incident, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.GetAllIncidents(ctx)
+}
+
+func (i *IncidentsService) GetEscalatingIncidents(ctx context.Context) ([]model.Incident, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.GetEscalatingIncidents(ctx)
+}
+
+func (i *IncidentsService) GetAllIncidentsBySiteId(
+	ctx context.Context,
+	siteId int64,
+) ([]model.Incident, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return i.incidents.GetAllIncidentsBySiteId(ctx, siteId)
+}
+
+func (i *IncidentsService) GetUptimeReport(
+	ctx context.Context,
+	siteId int64,
+	from time.Time,
+	to time.Time,
+) (model.UptimeReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	incidents, err := i.incidents.GetIncidentsBySiteIdInPeriod(ctx, siteId, from, to)
+	if err != nil {
+		return model.UptimeReport{}, err
+	}
+	return model.NewUptimeReport(siteId, incidents, from, to), nil
+}
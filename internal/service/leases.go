@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/repository"
+	"time"
+)
+
+type LeasesService struct {
+	leases repository.LeasesProvider
+	config config.CommonConfig
+}
+
+func NewLeasesService(leases repository.LeasesProvider, config config.CommonConfig) *LeasesService {
+	return &LeasesService{
+		leases: leases,
+		config: config,
+	}
+}
+
+func (l *LeasesService) AcquireLease(
+	ctx context.Context,
+	name string,
+	holder string,
+	ttl time.Duration,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return l.leases.AcquireLease(ctx, name, holder, ttl)
+}
+
+func (l *LeasesService) ReleaseLease(ctx context.Context, name string, holder string) error {
+	ctx, cancel := context.WithTimeout(ctx, l.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return l.leases.ReleaseLease(ctx, name, holder)
+}
+
+// GetLeaseState returns an empty state if the lease was never taken.
+func (l *LeasesService) GetLeaseState(ctx context.Context, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	state, err := l.leases.GetLeaseState(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return state, err
+}
+
+func (l *LeasesService) SetLeaseState(
+	ctx context.Context,
+	name string,
+	holder string,
+	state string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return l.leases.SetLeaseState(ctx, name, holder, state)
+}
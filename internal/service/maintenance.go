@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+	"time"
+)
+
+type MaintenanceService struct {
+	maintenance repository.MaintenanceProvider
+	config      config.CommonConfig
+}
+
+func NewMaintenanceService(
+	maintenance repository.MaintenanceProvider,
+	config config.CommonConfig,
+) *MaintenanceService {
+	return &MaintenanceService{
+		maintenance: maintenance,
+		config:      config,
+	}
+}
+
+func (m *MaintenanceService) AddWindow(
+	ctx context.Context,
+	window model.MaintenanceWindow,
+) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return m.maintenance.AddWindow(ctx, window)
+}
+
+func (m *MaintenanceService) UpdateWindow(ctx context.Context, window model.MaintenanceWindow) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return m.maintenance.UpdateWindow(ctx, window)
+}
+
+func (m *MaintenanceService) DeleteWindowById(ctx context.Context, windowId int64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return m.maintenance.DeleteWindowById(ctx, windowId)
+}
+
+func (m *MaintenanceService) SetWindowActive(ctx context.Context, windowId int64, active bool) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return m.maintenance.SetWindowActive(ctx, windowId, active)
+}
+
+func (m *MaintenanceService) GetWindowById(
+	ctx context.Context,
+	windowId int64,
+) (*model.MaintenanceWindow, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	window, err := m.maintenance.GetWindowById(ctx, windowId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (m *MaintenanceService) GetAllWindows(ctx context.Context) ([]model.MaintenanceWindow, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return m.maintenance.GetAllWindows(ctx)
+}
+
+// InMaintenance reports whether any maintenance window of the site is in
+// progress at the moment.
+func (m *MaintenanceService) InMaintenance(
+	ctx context.Context,
+	site model.Site,
+	now time.Time,
+) (bool, error) {
+	windows, err := m.GetAllWindows(ctx)
+	if err != nil {
+		return false, err
+	}
+	return model.InMaintenance(windows, site, now), nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+	"time"
+)
+
+type NotificationDeliveriesService struct {
+	deliveries repository.NotificationDeliveriesProvider
+	config     config.CommonConfig
+}
+
+func NewNotificationDeliveriesService(
+	deliveries repository.NotificationDeliveriesProvider,
+	config config.CommonConfig,
+) *NotificationDeliveriesService {
+	return &NotificationDeliveriesService{
+		deliveries: deliveries,
+		config:     config,
+	}
+}
+
+func (n *NotificationDeliveriesService) AddDeliveries(
+	ctx context.Context,
+	deliveries []model.NotificationDelivery,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return n.deliveries.AddDeliveries(ctx, deliveries)
+}
+
+func (n *NotificationDeliveriesService) UpdateDelivery(
+	ctx context.Context,
+	delivery model.NotificationDelivery,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return n.deliveries.UpdateDelivery(ctx, delivery)
+}
+
+func (n *NotificationDeliveriesService) ClaimDelivery(
+	ctx context.Context,
+	deliveryId int64,
+	now time.Time,
+	until time.Time,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return n.deliveries.ClaimDelivery(ctx, deliveryId, now, until)
+}
+
+func (n *NotificationDeliveriesService) GetDeliveryById(
+	ctx context.Context,
+	deliveryId int64,
+) (*model.NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	delivery, err := n.deliveries.GetDeliveryById(ctx, deliveryId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (n *NotificationDeliveriesService) GetDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]model.NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return n.deliveries.GetDueDeliveries(ctx, now, limit)
+}
+
+func (n *NotificationDeliveriesService) GetDeliveries(
+	ctx context.Context,
+	status model.DeliveryStatus,
+	limit int,
+) ([]model.NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return n.deliveries.GetDeliveries(ctx, status, limit)
+}
+
+func (n *NotificationDeliveriesService) GetDeliveriesByChannelId(
+	ctx context.Context,
+	channelId int64,
+	limit int,
+) ([]model.NotificationDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return n.deliveries.GetDeliveriesByChannelId(ctx, channelId, limit)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"shm/internal/config"
+	"shm/internal/model"
+	"shm/internal/repository"
+)
+
+type PendingNotificationsService struct {
+	notifications repository.PendingNotificationsProvider
+	config        config.CommonConfig
+}
+
+func NewPendingNotificationsService(
+	notifications repository.PendingNotificationsProvider,
+	config config.CommonConfig,
+) *PendingNotificationsService {
+	return &PendingNotificationsService{
+		notifications: notifications,
+		config:        config,
+	}
+}
+
+func (p *PendingNotificationsService) GetPendingNotifications(
+	ctx context.Context,
+	limit int,
+) ([]model.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return p.notifications.GetPendingNotifications(ctx, limit)
+}
+
+func (p *PendingNotificationsService) DeletePendingNotification(
+	ctx context.Context,
+	notificationId int64,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return p.notifications.DeletePendingNotification(ctx, notificationId)
+}
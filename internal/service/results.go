@@ -2,11 +2,10 @@ package service
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"shm/internal/config"
 	"shm/internal/model"
 	"shm/internal/repository"
+	"time"
 )
 
 type ResultsService struct {
@@ -24,7 +23,7 @@ func NewResultsService(
 	}
 }
 
-func (r *ResultsService) AddResult(ctx context.Context, result model.CheckResult) error {
+func (r *ResultsService) AddResult(ctx context.Context, result model.CheckResult) (int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.config.DbQueryTimeoutSec)
 	defer cancel()
 
@@ -42,19 +41,13 @@ func (r *ResultsService) GetNLastResultsForSite(
 	return r.results.GetNLastResultsForSite(ctx, site, number)
 }
 
-func (r *ResultsService) GetSecondToLastSuccessfulResultForSite(
+func (r *ResultsService) GetResultsForSiteSince(
 	ctx context.Context,
 	site model.Site,
-) (*model.CheckResult, error) {
+	since time.Time,
+) ([]model.CheckResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.config.DbQueryTimeoutSec)
 	defer cancel()
 
-	successfulResult, err := r.results.GetSecondToLastSuccessfulResultForSite(ctx, site)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &successfulResult, nil
+	return r.results.GetResultsForSiteSince(ctx, site, since)
 }
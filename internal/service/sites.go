@@ -7,6 +7,7 @@ import (
 	"shm/internal/config"
 	"shm/internal/model"
 	"shm/internal/repository"
+	"time"
 )
 
 type SitesService struct {
@@ -21,18 +22,57 @@ func NewSitesService(sites repository.SitesProvider, config config.CommonConfig)
 	}
 }
 
-func (s *SitesService) AddSite(ctx context.Context, url string) error {
+func (s *SitesService) AddSite(ctx context.Context, site model.Site) error {
 	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
 	defer cancel()
 
-	return s.sites.AddSite(ctx, url)
+	return s.sites.AddSite(ctx, site)
 }
 
-func (s *SitesService) AddSiteFromChat(ctx context.Context, chatId int64, url string) error {
+func (s *SitesService) AddSiteFromChat(ctx context.Context, chatId int64, site model.Site) error {
 	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
 	defer cancel()
 
-	return s.sites.AddSiteFromChat(ctx, chatId, url)
+	return s.sites.AddSiteFromChat(ctx, chatId, site)
+}
+
+func (s *SitesService) UpdateSite(ctx context.Context, site model.Site) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return s.sites.UpdateSite(ctx, site)
+}
+
+func (s *SitesService) UpdateLastPing(ctx context.Context, siteId int64, time time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return s.sites.UpdateLastPing(ctx, siteId, time)
+}
+
+func (s *SitesService) UpdateNextRunAt(
+	ctx context.Context,
+	siteId int64,
+	baseRunAt time.Time,
+	nextRunAt time.Time,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return s.sites.UpdateNextRunAt(ctx, siteId, baseRunAt, nextRunAt)
+}
+
+func (s *SitesService) UpdateDNSAnswers(
+	ctx context.Context,
+	siteId int64,
+	previous model.Strings,
+	answers model.Strings,
+	notification *model.Notification,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return s.sites.UpdateDNSAnswers(ctx, siteId, previous, answers, notification)
 }
 
 func (s *SitesService) DeleteSiteById(ctx context.Context, siteId int64) error {
@@ -63,6 +103,32 @@ func (s *SitesService) GetSiteById(ctx context.Context, siteId int64) (*model.Si
 	return &site, nil
 }
 
+func (s *SitesService) GetSiteByUrl(ctx context.Context, url string) (*model.Site, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	site, err := s.sites.GetSiteByUrl(ctx, url)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &site, nil
+}
+
+// GetDNSAnswers returns nil if the site does not exist.
+func (s *SitesService) GetDNSAnswers(ctx context.Context, siteId int64) (model.Strings, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	answers, err := s.sites.GetDNSAnswers(ctx, siteId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return answers, err
+}
+
 func (s *SitesService) GetAllSites(ctx context.Context) ([]model.Site, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
 	defer cancel()
@@ -77,6 +143,13 @@ func (s *SitesService) GetAllMonitoredSites(ctx context.Context) ([]model.Site,
 	return s.sites.GetAllMonitoredSites(ctx)
 }
 
+func (s *SitesService) GetDueMonitoredSites(ctx context.Context, now time.Time) ([]model.Site, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
+	defer cancel()
+
+	return s.sites.GetDueMonitoredSites(ctx, now)
+}
+
 func (s *SitesService) GetAllSitesByChatId(ctx context.Context, chatId int64) ([]model.Site, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.config.DbQueryTimeoutSec)
 	defer cancel()
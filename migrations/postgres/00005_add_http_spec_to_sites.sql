@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites
+    ADD COLUMN IF NOT EXISTS method TEXT NOT NULL DEFAULT 'GET',
+    ADD COLUMN IF NOT EXISTS headers TEXT NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS body TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS accepted_codes TEXT NOT NULL DEFAULT '200';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sites
+    DROP COLUMN IF EXISTS method,
+    DROP COLUMN IF EXISTS headers,
+    DROP COLUMN IF EXISTS body,
+    DROP COLUMN IF EXISTS accepted_codes;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS successful BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE check_results SET successful = COALESCE(code = 200, FALSE);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE check_results DROP COLUMN IF EXISTS successful;
+-- +goose StatementEnd
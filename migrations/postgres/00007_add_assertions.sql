@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites ADD COLUMN IF NOT EXISTS assertions TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS failure_reason TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sites DROP COLUMN IF EXISTS assertions;
+ALTER TABLE check_results DROP COLUMN IF EXISTS failure_reason;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS certificates (
+    site_id INTEGER PRIMARY KEY,
+    subject TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    sans TEXT NOT NULL,
+    not_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    hostname_valid BOOLEAN NOT NULL,
+    chain_valid BOOLEAN NOT NULL,
+    chain_error TEXT NOT NULL,
+    checked_at TIMESTAMP NOT NULL,
+    warned_days INTEGER
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS certificates;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE check_results
+    ADD COLUMN IF NOT EXISTS dns_ms INTEGER,
+    ADD COLUMN IF NOT EXISTS connect_ms INTEGER,
+    ADD COLUMN IF NOT EXISTS tls_ms INTEGER,
+    ADD COLUMN IF NOT EXISTS ttfb_ms INTEGER,
+    ADD COLUMN IF NOT EXISTS transfer_ms INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE check_results
+    DROP COLUMN IF EXISTS dns_ms,
+    DROP COLUMN IF EXISTS connect_ms,
+    DROP COLUMN IF EXISTS tls_ms,
+    DROP COLUMN IF EXISTS ttfb_ms,
+    DROP COLUMN IF EXISTS transfer_ms;
+-- +goose StatementEnd
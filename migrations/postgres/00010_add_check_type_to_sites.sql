@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites
+    ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'http',
+    ADD COLUMN IF NOT EXISTS tcp_banner TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tcp_send TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tcp_expect TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sites
+    DROP COLUMN IF EXISTS type,
+    DROP COLUMN IF EXISTS tcp_banner,
+    DROP COLUMN IF EXISTS tcp_send,
+    DROP COLUMN IF EXISTS tcp_expect;
+-- +goose StatementEnd
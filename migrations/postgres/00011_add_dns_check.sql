@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites
+    ADD COLUMN IF NOT EXISTS dns_record_type TEXT NOT NULL DEFAULT 'A',
+    ADD COLUMN IF NOT EXISTS dns_resolver TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS dns_expected TEXT NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS dns_match TEXT NOT NULL DEFAULT 'equals';
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS answers TEXT NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sites
+    DROP COLUMN IF EXISTS dns_record_type,
+    DROP COLUMN IF EXISTS dns_resolver,
+    DROP COLUMN IF EXISTS dns_expected,
+    DROP COLUMN IF EXISTS dns_match;
+ALTER TABLE check_results DROP COLUMN IF EXISTS answers;
+-- +goose StatementEnd
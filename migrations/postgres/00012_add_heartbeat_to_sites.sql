@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites
+    ADD COLUMN IF NOT EXISTS heartbeat_period_sec INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS heartbeat_grace_sec INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_ping_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sites
+    DROP COLUMN IF EXISTS heartbeat_period_sec,
+    DROP COLUMN IF EXISTS heartbeat_grace_sec,
+    DROP COLUMN IF EXISTS last_ping_at;
+-- +goose StatementEnd
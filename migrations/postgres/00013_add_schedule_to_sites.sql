@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites
+    ADD COLUMN IF NOT EXISTS interval_sec INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS sites_next_run_at_idx ON sites (next_run_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS sites_next_run_at_idx;
+ALTER TABLE sites
+    DROP COLUMN IF EXISTS interval_sec,
+    DROP COLUMN IF EXISTS next_run_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY;
+ALTER TABLE sites ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'unknown';
+CREATE TABLE IF NOT EXISTS incidents (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    site_id INTEGER NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    cause TEXT NOT NULL,
+    first_failed_result_id BIGINT NOT NULL,
+    last_failed_result_id BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS incidents_site_id_idx ON incidents (site_id, started_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incidents;
+ALTER TABLE sites DROP COLUMN IF EXISTS state;
+ALTER TABLE check_results DROP COLUMN IF EXISTS id;
+-- +goose StatementEnd
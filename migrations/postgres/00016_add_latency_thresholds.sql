@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites
+    ADD COLUMN IF NOT EXISTS latency_max_ms INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS latency_p95_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE incidents ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'down';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE incidents DROP COLUMN IF EXISTS kind;
+ALTER TABLE sites
+    DROP COLUMN IF EXISTS latency_max_ms,
+    DROP COLUMN IF EXISTS latency_p95_ms;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sites ADD COLUMN IF NOT EXISTS tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS assertion_failed BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    site_id INTEGER,
+    tag TEXT NOT NULL DEFAULT '',
+    condition TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS alert_rules_site_id_idx ON alert_rules (site_id);
+CREATE INDEX IF NOT EXISTS alert_rules_tag_idx ON alert_rules (tag);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS alert_rules;
+ALTER TABLE check_results DROP COLUMN IF EXISTS assertion_failed;
+ALTER TABLE sites DROP COLUMN IF EXISTS tags;
+-- +goose StatementEnd
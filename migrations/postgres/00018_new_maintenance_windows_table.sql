@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    site_id INTEGER,
+    tag TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    cron TEXT NOT NULL DEFAULT '',
+    duration_min INTEGER NOT NULL DEFAULT 0,
+    announce BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT FALSE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS maintenance_windows;
+-- +goose StatementEnd
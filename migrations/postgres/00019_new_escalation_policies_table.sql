@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    site_id INTEGER,
+    tag TEXT NOT NULL DEFAULT '',
+    steps TEXT NOT NULL DEFAULT '[]'
+);
+ALTER TABLE incidents
+    ADD COLUMN IF NOT EXISTS acked_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS acked_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS escalation_step INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE incidents
+    DROP COLUMN IF EXISTS acked_at,
+    DROP COLUMN IF EXISTS acked_by,
+    DROP COLUMN IF EXISTS escalation_step;
+DROP TABLE IF EXISTS escalation_policies;
+-- +goose StatementEnd
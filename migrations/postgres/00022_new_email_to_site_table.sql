@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_to_site (
+    email TEXT NOT NULL,
+    site_id INTEGER NOT NULL,
+    PRIMARY KEY (email, site_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_to_site;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE check_results ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+CREATE UNIQUE INDEX IF NOT EXISTS check_results_run_idx ON check_results (site_id, scheduled_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS check_results_run_idx;
+ALTER TABLE check_results DROP COLUMN IF EXISTS scheduled_at;
+-- +goose StatementEnd
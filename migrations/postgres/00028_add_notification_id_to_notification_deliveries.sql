@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notification_deliveries ADD COLUMN IF NOT EXISTS notification_id BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS notification_deliveries_notification_idx
+    ON notification_deliveries (notification_id, recipient);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notification_deliveries_notification_idx;
+ALTER TABLE notification_deliveries DROP COLUMN IF EXISTS notification_id;
+-- +goose StatementEnd
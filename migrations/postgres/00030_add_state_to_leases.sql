@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE leases DROP COLUMN IF EXISTS state;
+-- +goose StatementEnd